@@ -10,14 +10,21 @@ import (
 
 // App config struct
 type Config struct {
-	Server   ServerConfig
-	Postgres PostgresConfig
-	Redis    RedisConfig
-	Cookie   Cookie
-	Session  Session
-	Metrics  Metrics
-	Logger   Logger
-	Jaeger   Jaeger
+	Server            ServerConfig
+	Postgres          PostgresConfig
+	Redis             RedisConfig
+	Cookie            Cookie
+	Session           Session
+	Metrics           Metrics
+	Logger            Logger
+	Jaeger            Jaeger
+	Jwt               Jwt
+	MFA               MFA
+	WebAuthn          WebAuthn
+	Mailer            Mailer
+	EmailVerification EmailVerification
+	PasswordReset     PasswordReset
+	AccountDeletion   AccountDeletion
 }
 
 // Server config struct
@@ -26,7 +33,6 @@ type ServerConfig struct {
 	Port              string
 	PprofPort         string
 	Mode              string
-	JwtSecretKey      string
 	CookieName        string
 	ReadTimeout       time.Duration
 	WriteTimeout      time.Duration
@@ -38,6 +44,7 @@ type ServerConfig struct {
 	Timeout           time.Duration
 	MaxConnectionAge  time.Duration
 	Time              time.Duration
+	TrustForwardedFor bool
 }
 
 // Logger config
@@ -81,11 +88,20 @@ type Cookie struct {
 	HTTPOnly bool
 }
 
-// Session config
+// Session config, with sliding expiration Expire is an idle timeout extended by authenticated calls,
+// MaxAge is the lifetime in seconds since login after which user must login again, 0 disables it.
+// MaxConcurrent caps simultaneous sessions of user, MaxConcurrentByRole overrides it for users having the role,
+// LimitPolicy decides whether login over the cap is rejected or evicts the oldest session
 type Session struct {
-	Prefix string
-	Name   string
-	Expire int
+	Prefix              string
+	Name                string
+	Expire              int
+	RefreshExpire       int
+	SlidingExpiration   bool
+	MaxAge              int
+	MaxConcurrent       int
+	MaxConcurrentByRole map[string]int
+	LimitPolicy         string
 }
 
 // Metrics config
@@ -101,6 +117,61 @@ type Jaeger struct {
 	LogSpans    bool
 }
 
+// Jwt access token config
+type Jwt struct {
+	AccessTokenEnabled bool
+	AccessTokenExpire  int
+	Issuer             string
+	SigningAlgorithm   string
+	KeysDir            string
+	KeysReloadInterval int
+	JwksAddr           string
+}
+
+// Multi-factor authentication config
+type MFA struct {
+	Issuer          string
+	EncryptionKey   string
+	ChallengeExpire int
+	MaxAttempts     int
+}
+
+// WebAuthn passkeys relying party config
+type WebAuthn struct {
+	RPID            string
+	RPDisplayName   string
+	RPOrigin        string
+	ChallengeExpire int
+}
+
+// Outgoing email config, BaseURL is used for links sent to users
+type Mailer struct {
+	Driver   string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	BaseURL  string
+}
+
+// Email verification config, Required blocks login of unverified accounts
+type EmailVerification struct {
+	Required    bool
+	TokenExpire int
+}
+
+// Password reset config
+type PasswordReset struct {
+	TokenExpire int
+}
+
+// Deleted accounts can be restored during GracePeriod seconds, purge job runs every PurgeInterval seconds
+type AccountDeletion struct {
+	GracePeriod   int
+	PurgeInterval int
+}
+
 // Load config file from given path
 func LoadConfig(filename string) (*viper.Viper, error) {
 	v := viper.New()
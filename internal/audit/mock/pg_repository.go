@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pg_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	reflect "reflect"
+)
+
+// MockAuditPGRepository is a mock of AuditPGRepository interface
+type MockAuditPGRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditPGRepositoryMockRecorder
+}
+
+// MockAuditPGRepositoryMockRecorder is the mock recorder for MockAuditPGRepository
+type MockAuditPGRepositoryMockRecorder struct {
+	mock *MockAuditPGRepository
+}
+
+// NewMockAuditPGRepository creates a new mock instance
+func NewMockAuditPGRepository(ctrl *gomock.Controller) *MockAuditPGRepository {
+	mock := &MockAuditPGRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditPGRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditPGRepository) EXPECT() *MockAuditPGRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockAuditPGRepository) Create(ctx context.Context, event *models.AuditEvent) (*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, event)
+	ret0, _ := ret[0].(*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockAuditPGRepositoryMockRecorder) Create(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditPGRepository)(nil).Create), ctx, event)
+}
+
+// FindByUserID mocks base method
+func (m *MockAuditPGRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID
+func (mr *MockAuditPGRepositoryMockRecorder) FindByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockAuditPGRepository)(nil).FindByUserID), ctx, userID)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	reflect "reflect"
+)
+
+// MockAuditUseCase is a mock of AuditUseCase interface
+type MockAuditUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditUseCaseMockRecorder
+}
+
+// MockAuditUseCaseMockRecorder is the mock recorder for MockAuditUseCase
+type MockAuditUseCaseMockRecorder struct {
+	mock *MockAuditUseCase
+}
+
+// NewMockAuditUseCase creates a new mock instance
+func NewMockAuditUseCase(ctrl *gomock.Controller) *MockAuditUseCase {
+	mock := &MockAuditUseCase{ctrl: ctrl}
+	mock.recorder = &MockAuditUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditUseCase) EXPECT() *MockAuditUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method
+func (m *MockAuditUseCase) Record(ctx context.Context, userID uuid.UUID, eventType string, metadata models.AuditMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, userID, eventType, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record
+func (mr *MockAuditUseCaseMockRecorder) Record(ctx, userID, eventType, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditUseCase)(nil).Record), ctx, userID, eventType, metadata)
+}
+
+// FindByUserID mocks base method
+func (m *MockAuditUseCase) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID
+func (mr *MockAuditUseCaseMockRecorder) FindByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockAuditUseCase)(nil).FindByUserID), ctx, userID)
+}
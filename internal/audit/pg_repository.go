@@ -0,0 +1,16 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository.go -package mock
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Audit events pg repository
+type AuditPGRepository interface {
+	Create(ctx context.Context, event *models.AuditEvent) (*models.AuditEvent, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.AuditEvent, error)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Audit repository
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+// Audit repository constructor
+func NewAuditPGRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create audit event
+func (r *AuditRepository) Create(ctx context.Context, event *models.AuditEvent) (*models.AuditEvent, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "AuditRepository.Create")
+	defer span.Finish()
+
+	createdEvent := &models.AuditEvent{}
+	if err := r.db.QueryRowxContext(ctx, createAuditEventQuery, event.UserID, event.Type, event.Metadata).StructScan(createdEvent); err != nil {
+		return nil, errors.Wrap(err, "Create.QueryRowxContext")
+	}
+
+	return createdEvent, nil
+}
+
+// Find audit events of user, newest first
+func (r *AuditRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.AuditEvent, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "AuditRepository.FindByUserID")
+	defer span.Finish()
+
+	events := make([]*models.AuditEvent, 0)
+	if err := r.db.SelectContext(ctx, &events, findAuditEventsByUserIDQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "FindByUserID.SelectContext")
+	}
+
+	return events, nil
+}
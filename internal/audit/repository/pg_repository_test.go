@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+func TestAuditRepository(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	auditPGRepository := NewAuditPGRepository(sqlxDB)
+	userID := uuid.New()
+	columns := []string{"event_id", "user_id", "event_type", "metadata", "created_at"}
+
+	t.Run("Create", func(t *testing.T) {
+		event := &models.AuditEvent{UserID: userID, Type: models.AuditEventLogin, Metadata: models.AuditMetadata{"method": "password"}}
+		rows := sqlmock.NewRows(columns).AddRow(uuid.New(), userID, event.Type, []byte(`{"method":"password"}`), time.Now())
+
+		mock.ExpectQuery(createAuditEventQuery).WithArgs(userID, event.Type, []byte(`{"method":"password"}`)).WillReturnRows(rows)
+
+		createdEvent, err := auditPGRepository.Create(context.Background(), event)
+		require.NoError(t, err)
+		require.Equal(t, "password", createdEvent.Metadata["method"])
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("FindByUserID", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), userID, models.AuditEventLogout, []byte(`{}`), time.Now()).
+			AddRow(uuid.New(), userID, models.AuditEventLogin, []byte(`{"method":"passkey"}`), time.Now().Add(-time.Hour))
+
+		mock.ExpectQuery(findAuditEventsByUserIDQuery).WithArgs(userID).WillReturnRows(rows)
+
+		events, err := auditPGRepository.FindByUserID(context.Background(), userID)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, models.AuditEventLogout, events[0].Type)
+		require.Equal(t, "passkey", events[1].Metadata["method"])
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,9 @@
+package repository
+
+const (
+	createAuditEventQuery = `INSERT INTO audit_events (user_id, event_type, metadata) VALUES ($1, $2, $3) 
+		RETURNING event_id, user_id, event_type, metadata, created_at`
+
+	findAuditEventsByUserIDQuery = `SELECT event_id, user_id, event_type, metadata, created_at FROM audit_events 
+		WHERE user_id = $1 ORDER BY created_at DESC`
+)
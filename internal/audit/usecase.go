@@ -0,0 +1,16 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase.go -package mock
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Audit UseCase interface
+type AuditUseCase interface {
+	Record(ctx context.Context, userID uuid.UUID, eventType string, metadata models.AuditMetadata) error
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.AuditEvent, error)
+}
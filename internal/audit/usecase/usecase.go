@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/internal/audit"
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Audit UseCase
+type auditUseCase struct {
+	auditPgRepo audit.AuditPGRepository
+}
+
+// New Audit UseCase
+func NewAuditUseCase(auditPgRepo audit.AuditPGRepository) *auditUseCase {
+	return &auditUseCase{auditPgRepo: auditPgRepo}
+}
+
+// Record audit event of user
+func (u *auditUseCase) Record(ctx context.Context, userID uuid.UUID, eventType string, metadata models.AuditMetadata) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditUseCase.Record")
+	defer span.Finish()
+
+	if metadata == nil {
+		metadata = models.AuditMetadata{}
+	}
+	event := &models.AuditEvent{UserID: userID, Type: eventType, Metadata: metadata}
+	if _, err := u.auditPgRepo.Create(ctx, event); err != nil {
+		return errors.Wrap(err, "auditPgRepo.Create")
+	}
+
+	return nil
+}
+
+// Find audit events of user, newest first
+func (u *auditUseCase) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.AuditEvent, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditUseCase.FindByUserID")
+	defer span.Finish()
+
+	events, err := u.auditPgRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "auditPgRepo.FindByUserID")
+	}
+
+	return events, nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/internal/audit/mock"
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+func TestAuditUseCase_Record(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditPGRepository := mock.NewMockAuditPGRepository(ctrl)
+	auditUC := NewAuditUseCase(auditPGRepository)
+	userID := uuid.New()
+
+	t.Run("Record", func(t *testing.T) {
+		auditPGRepository.EXPECT().Create(gomock.Any(), &models.AuditEvent{
+			UserID:   userID,
+			Type:     models.AuditEventLogout,
+			Metadata: models.AuditMetadata{},
+		}).Return(&models.AuditEvent{}, nil)
+
+		err := auditUC.Record(context.Background(), userID, models.AuditEventLogout, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("FindByUserID", func(t *testing.T) {
+		events := []*models.AuditEvent{{UserID: userID, Type: models.AuditEventLogin}}
+		auditPGRepository.EXPECT().FindByUserID(gomock.Any(), userID).Return(events, nil)
+
+		foundEvents, err := auditUC.FindByUserID(context.Background(), userID)
+		require.NoError(t, err)
+		require.Equal(t, events, foundEvents)
+	})
+}
@@ -0,0 +1,187 @@
+package interceptors
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+)
+
+const (
+	sessionIDHeader     = "session_id"
+	authorizationHeader = "authorization"
+	bearerPrefix        = "bearer "
+)
+
+// Authentication requirement of rpc method
+type AuthMode int
+
+const (
+	// Caller must be authenticated
+	AuthRequired AuthMode = iota
+	// Caller is resolved if credentials are present
+	AuthOptional
+	// Caller must not be authenticated
+	AuthForbidden
+)
+
+type principalCtxKey struct{}
+
+// Get authenticated caller from ctx
+func PrincipalFromCtx(ctx context.Context) (*models.Principal, bool) {
+	principal, ok := ctx.Value(principalCtxKey{}).(*models.Principal)
+	return principal, ok
+}
+
+// Put authenticated caller to ctx
+func ContextWithPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, principal)
+}
+
+// Auth unary interceptor, resolves session or access token from metadata and puts principal to ctx
+func (im *InterceptorManager) AuthUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	authCtx, err := im.authenticate(ctx, info.Server, info.FullMethod)
+	if err != nil {
+		im.logger.Errorf("AuthUnary: method: %s, err: %v", info.FullMethod, err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "authenticate: %v", err)
+	}
+
+	return handler(authCtx, req)
+}
+
+// Auth stream interceptor, resolves session or access token from metadata and puts principal to stream ctx
+func (im *InterceptorManager) AuthStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	authCtx, err := im.authenticate(ss.Context(), srv, info.FullMethod)
+	if err != nil {
+		im.logger.Errorf("AuthStream: method: %s, err: %v", info.FullMethod, err)
+		return status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "authenticate: %v", err)
+	}
+
+	wrapped := grpc_middleware.WrapServerStream(ss)
+	wrapped.WrappedContext = authCtx
+	return handler(srv, wrapped)
+}
+
+func (im *InterceptorManager) authenticate(ctx context.Context, srv interface{}, fullMethod string) (context.Context, error) {
+	mode := methodPolicy(srv, fullMethod).Auth
+
+	principal, err := im.resolvePrincipal(ctx)
+	if err != nil {
+		if mode == AuthRequired || !isInvalidCredentials(err) {
+			return nil, err
+		}
+		// invalid or expired credentials make caller anonymous for methods not requiring authentication
+		return ctx, nil
+	}
+
+	switch {
+	case principal == nil && mode == AuthRequired:
+		return nil, grpc_errors.ErrUnauthenticated
+	case principal != nil && mode == AuthForbidden:
+		return nil, grpc_errors.ErrAlreadyAuthenticated
+	case principal == nil:
+		return ctx, nil
+	}
+
+	return ContextWithPrincipal(ctx, principal), nil
+}
+
+// Resolve caller from session_id or authorization bearer metadata, returns nil principal if there are no credentials
+func (im *InterceptorManager) resolvePrincipal(ctx context.Context) (*models.Principal, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	if sessionID := firstMetadataValue(md, sessionIDHeader); sessionID != "" {
+		return im.resolveSession(ctx, sessionID)
+	}
+
+	if authorization := firstMetadataValue(md, authorizationHeader); authorization != "" {
+		if !strings.HasPrefix(strings.ToLower(authorization), bearerPrefix) {
+			return nil, errors.Wrap(grpc_errors.ErrInvalidToken, "authorization header")
+		}
+		return im.resolveAccessToken(ctx, strings.TrimSpace(authorization[len(bearerPrefix):]))
+	}
+
+	return nil, nil
+}
+
+func (im *InterceptorManager) resolveSession(ctx context.Context, sessionID string) (*models.Principal, error) {
+	session, err := im.sessUC.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.Wrap(grpc_errors.ErrInvalidSessionId, "sessUC.GetSessionByID")
+		}
+		return nil, errors.Wrap(err, "sessUC.GetSessionByID")
+	}
+
+	user, err := im.userUC.FindById(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(grpc_errors.ErrInvalidSessionId, "userUC.FindById")
+		}
+		return nil, errors.Wrap(err, "userUC.FindById")
+	}
+
+	im.touchSession(ctx, session)
+
+	return &models.Principal{
+		UserID:      user.UserID,
+		Roles:       user.Roles,
+		Permissions: user.Permissions,
+		SessionID:   session.SessionID,
+	}, nil
+}
+
+func (im *InterceptorManager) resolveAccessToken(ctx context.Context, accessToken string) (*models.Principal, error) {
+	claims, err := im.jwtManager.VerifyAccessToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := im.sessUC.GetSessionByID(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.Wrap(grpc_errors.ErrInvalidToken, "sessUC.GetSessionByID")
+		}
+		return nil, errors.Wrap(err, "sessUC.GetSessionByID")
+	}
+
+	im.touchSession(ctx, session)
+
+	return &models.Principal{
+		UserID:      session.UserID,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		SessionID:   session.SessionID,
+	}, nil
+}
+
+// Update last seen time of session, failure does not fail the call
+func (im *InterceptorManager) touchSession(ctx context.Context, session *models.Session) {
+	if err := im.sessUC.Touch(ctx, session); err != nil {
+		im.logger.Errorf("sessUC.Touch: %v", err)
+	}
+}
+
+func isInvalidCredentials(err error) bool {
+	return errors.Is(err, grpc_errors.ErrInvalidSessionId) || errors.Is(err, grpc_errors.ErrInvalidToken)
+}
+
+func firstMetadataValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,134 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	mockSessUC "github.com/AleksK1NG/auth-microservice/internal/session/mock"
+	"github.com/AleksK1NG/auth-microservice/internal/user/mock"
+	"github.com/AleksK1NG/auth-microservice/pkg/jwt"
+	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+)
+
+type policyService map[string]MethodPolicy
+
+func (s policyService) MethodPolicy(fullMethod string) MethodPolicy {
+	return s[fullMethod]
+}
+
+func principalHandler(ctx context.Context, req interface{}) (interface{}, error) {
+	principal, _ := PrincipalFromCtx(ctx)
+	return principal, nil
+}
+
+func TestInterceptorManager_AuthUnary(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	cfg := &config.Config{Jwt: config.Jwt{
+		AccessTokenExpire: 60,
+		Issuer:            "auth",
+		SigningAlgorithm:  jwt.AlgorithmES256,
+		KeysDir:           t.TempDir(),
+	}}
+	apiLogger := logger.NewAPILogger(cfg)
+	apiLogger.InitLogger()
+	jwtManager, err := jwt.NewJwtManager(apiLogger, cfg)
+	require.NoError(t, err)
+	im := NewInterceptorManager(apiLogger, cfg, nil, sessUC, userUC, jwtManager)
+
+	srv := policyService{
+		"/required":  {Auth: AuthRequired},
+		"/optional":  {Auth: AuthOptional},
+		"/forbidden": {Auth: AuthForbidden},
+	}
+	user := &models.User{UserID: uuid.New(), Roles: []string{models.RoleUser}, Permissions: []string{models.PermissionUsersRead}}
+	session := &models.Session{SessionID: uuid.New().String(), UserID: user.UserID}
+
+	t.Run("Session", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", session.SessionID))
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), session.SessionID).Return(session, nil)
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+		sessUC.EXPECT().Touch(gomock.Any(), session).Return(nil)
+
+		resp, err := im.AuthUnary(ctx, nil, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/required"}, principalHandler)
+		require.NoError(t, err)
+		principal := resp.(*models.Principal)
+		require.Equal(t, user.UserID, principal.UserID)
+		require.Equal(t, user.Roles, principal.Roles)
+		require.Equal(t, user.Permissions, principal.Permissions)
+		require.Equal(t, session.SessionID, principal.SessionID)
+	})
+
+	t.Run("Access token", func(t *testing.T) {
+		accessToken, err := jwtManager.GenerateAccessToken(&jwt.Claims{
+			UserID:      user.UserID.String(),
+			Roles:       user.Roles,
+			Permissions: user.Permissions,
+			SessionID:   session.SessionID,
+		})
+		require.NoError(t, err)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+accessToken))
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), session.SessionID).Return(session, nil)
+		// failing to update last seen time does not fail the call
+		sessUC.EXPECT().Touch(gomock.Any(), session).Return(redis.ErrClosed)
+
+		resp, err := im.AuthUnary(ctx, nil, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/required"}, principalHandler)
+		require.NoError(t, err)
+		principal := resp.(*models.Principal)
+		require.Equal(t, user.UserID, principal.UserID)
+		require.Equal(t, session.SessionID, principal.SessionID)
+		require.Equal(t, user.Permissions, principal.Permissions)
+	})
+
+	t.Run("Required without credentials", func(t *testing.T) {
+		_, err := im.AuthUnary(context.Background(), nil, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/required"}, principalHandler)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Required with missing session key", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("other", "value"))
+		_, err := im.AuthUnary(ctx, nil, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/required"}, principalHandler)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Optional with expired session", func(t *testing.T) {
+		sessionID := uuid.New().String()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionID))
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), sessionID).Return(nil, redis.Nil)
+
+		resp, err := im.AuthUnary(ctx, nil, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/optional"}, principalHandler)
+		require.NoError(t, err)
+		require.Nil(t, resp)
+	})
+
+	t.Run("Forbidden with session", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", session.SessionID))
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), session.SessionID).Return(session, nil)
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+		sessUC.EXPECT().Touch(gomock.Any(), session).Return(nil)
+
+		_, err := im.AuthUnary(ctx, nil, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/forbidden"}, principalHandler)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("Forbidden without credentials", func(t *testing.T) {
+		resp, err := im.AuthUnary(context.Background(), nil, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/forbidden"}, principalHandler)
+		require.NoError(t, err)
+		require.Nil(t, resp)
+	})
+}
@@ -0,0 +1,42 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+const (
+	userAgentHeader    = "user-agent"
+	forwardedForHeader = "x-forwarded-for"
+)
+
+// Get address and user agent of client from ctx, x-forwarded-for is used only behind a trusted proxy
+// because any client can set it
+func ClientFromCtx(ctx context.Context, trustForwardedFor bool) *models.ClientInfo {
+	client := &models.ClientInfo{}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	client.UserAgent = firstMetadataValue(md, userAgentHeader)
+
+	if trustForwardedFor {
+		if forwardedFor := firstMetadataValue(md, forwardedForHeader); forwardedFor != "" {
+			client.IPAddress = strings.TrimSpace(strings.Split(forwardedFor, ",")[0])
+			return client
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IPAddress = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.IPAddress); err == nil {
+			client.IPAddress = host
+		}
+	}
+
+	return client
+}
@@ -0,0 +1,39 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestClientFromCtx(t *testing.T) {
+	t.Parallel()
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 51234}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(
+		"user-agent", "grpc-go/1.34.0",
+		"x-forwarded-for", "203.0.113.7, 10.0.0.1",
+	))
+
+	t.Run("Peer address", func(t *testing.T) {
+		client := ClientFromCtx(ctx, false)
+		require.Equal(t, "10.0.0.2", client.IPAddress)
+		require.Equal(t, "grpc-go/1.34.0", client.UserAgent)
+	})
+
+	t.Run("Trusted forwarded for", func(t *testing.T) {
+		client := ClientFromCtx(ctx, true)
+		require.Equal(t, "203.0.113.7", client.IPAddress)
+		require.Equal(t, "grpc-go/1.34.0", client.UserAgent)
+	})
+
+	t.Run("Without metadata", func(t *testing.T) {
+		client := ClientFromCtx(context.Background(), true)
+		require.Empty(t, client.IPAddress)
+		require.Empty(t, client.UserAgent)
+	})
+}
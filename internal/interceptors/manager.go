@@ -9,21 +9,34 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/internal/session"
+	"github.com/AleksK1NG/auth-microservice/internal/user"
 	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+	"github.com/AleksK1NG/auth-microservice/pkg/jwt"
 	"github.com/AleksK1NG/auth-microservice/pkg/logger"
 	"github.com/AleksK1NG/auth-microservice/pkg/metric"
 )
 
 // InterceptorManager
 type InterceptorManager struct {
-	logger logger.Logger
-	cfg    *config.Config
-	metr   metric.Metrics
+	logger     logger.Logger
+	cfg        *config.Config
+	metr       metric.Metrics
+	sessUC     session.SessionUseCase
+	userUC     user.UserUseCase
+	jwtManager jwt.Manager
 }
 
 // InterceptorManager constructor
-func NewInterceptorManager(logger logger.Logger, cfg *config.Config, metr metric.Metrics) *InterceptorManager {
-	return &InterceptorManager{logger: logger, cfg: cfg, metr: metr}
+func NewInterceptorManager(
+	logger logger.Logger,
+	cfg *config.Config,
+	metr metric.Metrics,
+	sessUC session.SessionUseCase,
+	userUC user.UserUseCase,
+	jwtManager jwt.Manager,
+) *InterceptorManager {
+	return &InterceptorManager{logger: logger, cfg: cfg, metr: metr, sessUC: sessUC, userUC: userUC, jwtManager: jwtManager}
 }
 
 // Logger Interceptor
@@ -0,0 +1,105 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+)
+
+// Authorization rule of rpc method, stream methods are checked with nil request
+type AccessRule func(principal *models.Principal, req interface{}) bool
+
+// Authentication and authorization policy of rpc method
+type MethodPolicy struct {
+	Auth   AuthMode
+	Access AccessRule
+}
+
+// Implemented by services to declare policy per method,
+// undeclared methods require authentication and allow any authenticated caller
+type PolicyProvider interface {
+	MethodPolicy(fullMethod string) MethodPolicy
+}
+
+// Allow callers granted one of the permissions
+func RequirePermission(permissions ...string) AccessRule {
+	return func(principal *models.Principal, req interface{}) bool {
+		return principal != nil && hasPermission(principal, permissions)
+	}
+}
+
+// Allow caller owning the requested resource or granted one of the permissions,
+// ownerID extracts owner user id from request
+func SelfOrPermission(ownerID func(req interface{}) string, permissions ...string) AccessRule {
+	return func(principal *models.Principal, req interface{}) bool {
+		if principal == nil {
+			return false
+		}
+		if hasPermission(principal, permissions) {
+			return true
+		}
+		if req == nil {
+			return false
+		}
+		id, err := uuid.Parse(ownerID(req))
+		return err == nil && id == principal.UserID
+	}
+}
+
+// RBAC unary interceptor, checks access rule of method for principal put to ctx by AuthUnary
+func (im *InterceptorManager) RBACUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := im.authorize(ctx, info.Server, info.FullMethod, req); err != nil {
+		im.logger.Errorf("RBACUnary: method: %s, err: %v", info.FullMethod, err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "authorize: %v", err)
+	}
+
+	return handler(ctx, req)
+}
+
+// RBAC stream interceptor, checks access rule of method for principal put to stream ctx by AuthStream
+func (im *InterceptorManager) RBACStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := im.authorize(ss.Context(), srv, info.FullMethod, nil); err != nil {
+		im.logger.Errorf("RBACStream: method: %s, err: %v", info.FullMethod, err)
+		return status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "authorize: %v", err)
+	}
+
+	return handler(srv, ss)
+}
+
+func (im *InterceptorManager) authorize(ctx context.Context, srv interface{}, fullMethod string, req interface{}) error {
+	policy := methodPolicy(srv, fullMethod)
+	if policy.Access == nil {
+		return nil
+	}
+
+	principal, ok := PrincipalFromCtx(ctx)
+	if !ok {
+		return grpc_errors.ErrUnauthenticated
+	}
+	if !policy.Access(principal, req) {
+		return grpc_errors.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+func methodPolicy(srv interface{}, fullMethod string) MethodPolicy {
+	if provider, ok := srv.(PolicyProvider); ok {
+		return provider.MethodPolicy(fullMethod)
+	}
+	return MethodPolicy{Auth: AuthRequired}
+}
+
+func hasPermission(principal *models.Principal, permissions []string) bool {
+	for _, permission := range permissions {
+		if principal.HasPermission(permission) {
+			return true
+		}
+	}
+	return false
+}
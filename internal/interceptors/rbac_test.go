@@ -0,0 +1,71 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+)
+
+type ownedRequest struct {
+	ownerID string
+}
+
+func TestInterceptorManager_RBACUnary(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{}
+	apiLogger := logger.NewAPILogger(cfg)
+	apiLogger.InitLogger()
+	im := NewInterceptorManager(apiLogger, cfg, nil, nil, nil, nil)
+
+	ownerID := func(req interface{}) string { return req.(*ownedRequest).ownerID }
+	srv := policyService{
+		"/permitted": {Auth: AuthRequired, Access: RequirePermission(models.PermissionUsersRead)},
+		"/owned":     {Auth: AuthRequired, Access: SelfOrPermission(ownerID, models.PermissionUsersRead)},
+		"/any":       {Auth: AuthRequired},
+	}
+	admin := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleAdmin}, Permissions: []string{models.PermissionUsersRead}}
+	user := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleUser}}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	cases := []struct {
+		name      string
+		method    string
+		principal *models.Principal
+		req       interface{}
+		code      codes.Code
+	}{
+		{name: "Permitted", method: "/permitted", principal: admin, code: codes.OK},
+		{name: "Not permitted", method: "/permitted", principal: user, code: codes.PermissionDenied},
+		{name: "Without principal", method: "/permitted", code: codes.Unauthenticated},
+		{name: "Owned by self", method: "/owned", principal: user, req: &ownedRequest{ownerID: user.UserID.String()}, code: codes.OK},
+		{name: "Owned by other with permission", method: "/owned", principal: admin, req: &ownedRequest{ownerID: user.UserID.String()}, code: codes.OK},
+		{name: "Owned by other without permission", method: "/owned", principal: user, req: &ownedRequest{ownerID: admin.UserID.String()}, code: codes.PermissionDenied},
+		{name: "No access rule", method: "/any", principal: user, code: codes.OK},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.principal != nil {
+				ctx = ContextWithPrincipal(ctx, c.principal)
+			}
+
+			resp, err := im.RBACUnary(ctx, c.req, &grpc.UnaryServerInfo{Server: srv, FullMethod: c.method}, handler)
+			require.Equal(t, c.code, status.Code(err))
+			if c.code == codes.OK {
+				require.Equal(t, "ok", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pg_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	reflect "reflect"
+)
+
+// MockMfaPGRepository is a mock of MfaPGRepository interface
+type MockMfaPGRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMfaPGRepositoryMockRecorder
+}
+
+// MockMfaPGRepositoryMockRecorder is the mock recorder for MockMfaPGRepository
+type MockMfaPGRepositoryMockRecorder struct {
+	mock *MockMfaPGRepository
+}
+
+// NewMockMfaPGRepository creates a new mock instance
+func NewMockMfaPGRepository(ctrl *gomock.Controller) *MockMfaPGRepository {
+	mock := &MockMfaPGRepository{ctrl: ctrl}
+	mock.recorder = &MockMfaPGRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMfaPGRepository) EXPECT() *MockMfaPGRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByUserID mocks base method
+func (m *MockMfaPGRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].(*models.UserMFA)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID
+func (mr *MockMfaPGRepositoryMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockMfaPGRepository)(nil).GetByUserID), ctx, userID)
+}
+
+// Upsert mocks base method
+func (m *MockMfaPGRepository) Upsert(ctx context.Context, userMFA *models.UserMFA) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, userMFA)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert
+func (mr *MockMfaPGRepositoryMockRecorder) Upsert(ctx, userMFA interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockMfaPGRepository)(nil).Upsert), ctx, userMFA)
+}
+
+// UpdateTOTPLastStep mocks base method
+func (m *MockMfaPGRepository) UpdateTOTPLastStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTOTPLastStep", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTOTPLastStep indicates an expected call of UpdateTOTPLastStep
+func (mr *MockMfaPGRepositoryMockRecorder) UpdateTOTPLastStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTOTPLastStep", reflect.TypeOf((*MockMfaPGRepository)(nil).UpdateTOTPLastStep), ctx, userID, step)
+}
+
+// DeleteByUserID mocks base method
+func (m *MockMfaPGRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID
+func (mr *MockMfaPGRepositoryMockRecorder) DeleteByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockMfaPGRepository)(nil).DeleteByUserID), ctx, userID)
+}
+
+// ReplaceRecoveryCodes mocks base method
+func (m *MockMfaPGRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*models.RecoveryCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, codes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes
+func (mr *MockMfaPGRepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userID, codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockMfaPGRepository)(nil).ReplaceRecoveryCodes), ctx, userID, codes)
+}
+
+// FindUnusedRecoveryCodes mocks base method
+func (m *MockMfaPGRepository) FindUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]*models.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUnusedRecoveryCodes", ctx, userID)
+	ret0, _ := ret[0].([]*models.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUnusedRecoveryCodes indicates an expected call of FindUnusedRecoveryCodes
+func (mr *MockMfaPGRepositoryMockRecorder) FindUnusedRecoveryCodes(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUnusedRecoveryCodes", reflect.TypeOf((*MockMfaPGRepository)(nil).FindUnusedRecoveryCodes), ctx, userID)
+}
+
+// UseRecoveryCode mocks base method
+func (m *MockMfaPGRepository) UseRecoveryCode(ctx context.Context, codeID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, codeID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode
+func (mr *MockMfaPGRepositoryMockRecorder) UseRecoveryCode(ctx, codeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockMfaPGRepository)(nil).UseRecoveryCode), ctx, codeID)
+}
@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: redis_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	reflect "reflect"
+)
+
+// MockMfaRedisRepository is a mock of MfaRedisRepository interface
+type MockMfaRedisRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMfaRedisRepositoryMockRecorder
+}
+
+// MockMfaRedisRepositoryMockRecorder is the mock recorder for MockMfaRedisRepository
+type MockMfaRedisRepositoryMockRecorder struct {
+	mock *MockMfaRedisRepository
+}
+
+// NewMockMfaRedisRepository creates a new mock instance
+func NewMockMfaRedisRepository(ctrl *gomock.Controller) *MockMfaRedisRepository {
+	mock := &MockMfaRedisRepository{ctrl: ctrl}
+	mock.recorder = &MockMfaRedisRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMfaRedisRepository) EXPECT() *MockMfaRedisRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateChallenge mocks base method
+func (m *MockMfaRedisRepository) CreateChallenge(ctx context.Context, challenge *models.MFAChallenge, expire int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChallenge", ctx, challenge, expire)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateChallenge indicates an expected call of CreateChallenge
+func (mr *MockMfaRedisRepositoryMockRecorder) CreateChallenge(ctx, challenge, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChallenge", reflect.TypeOf((*MockMfaRedisRepository)(nil).CreateChallenge), ctx, challenge, expire)
+}
+
+// AttemptChallenge mocks base method
+func (m *MockMfaRedisRepository) AttemptChallenge(ctx context.Context, challengeID string) (*models.MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttemptChallenge", ctx, challengeID)
+	ret0, _ := ret[0].(*models.MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttemptChallenge indicates an expected call of AttemptChallenge
+func (mr *MockMfaRedisRepositoryMockRecorder) AttemptChallenge(ctx, challengeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttemptChallenge", reflect.TypeOf((*MockMfaRedisRepository)(nil).AttemptChallenge), ctx, challengeID)
+}
+
+// DeleteChallenge mocks base method
+func (m *MockMfaRedisRepository) DeleteChallenge(ctx context.Context, challengeID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteChallenge", ctx, challengeID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteChallenge indicates an expected call of DeleteChallenge
+func (mr *MockMfaRedisRepositoryMockRecorder) DeleteChallenge(ctx, challengeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChallenge", reflect.TypeOf((*MockMfaRedisRepository)(nil).DeleteChallenge), ctx, challengeID)
+}
+
+// AttemptTOTP mocks base method
+func (m *MockMfaRedisRepository) AttemptTOTP(ctx context.Context, userID uuid.UUID, expire int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttemptTOTP", ctx, userID, expire)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttemptTOTP indicates an expected call of AttemptTOTP
+func (mr *MockMfaRedisRepositoryMockRecorder) AttemptTOTP(ctx, userID, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttemptTOTP", reflect.TypeOf((*MockMfaRedisRepository)(nil).AttemptTOTP), ctx, userID, expire)
+}
+
+// ResetTOTPAttempts mocks base method
+func (m *MockMfaRedisRepository) ResetTOTPAttempts(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetTOTPAttempts", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetTOTPAttempts indicates an expected call of ResetTOTPAttempts
+func (mr *MockMfaRedisRepositoryMockRecorder) ResetTOTPAttempts(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetTOTPAttempts", reflect.TypeOf((*MockMfaRedisRepository)(nil).ResetTOTPAttempts), ctx, userID)
+}
+
+// AttemptRecoveryCode mocks base method
+func (m *MockMfaRedisRepository) AttemptRecoveryCode(ctx context.Context, userID uuid.UUID, expire int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttemptRecoveryCode", ctx, userID, expire)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttemptRecoveryCode indicates an expected call of AttemptRecoveryCode
+func (mr *MockMfaRedisRepositoryMockRecorder) AttemptRecoveryCode(ctx, userID, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttemptRecoveryCode", reflect.TypeOf((*MockMfaRedisRepository)(nil).AttemptRecoveryCode), ctx, userID, expire)
+}
+
+// ResetRecoveryCodeAttempts mocks base method
+func (m *MockMfaRedisRepository) ResetRecoveryCodeAttempts(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetRecoveryCodeAttempts", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetRecoveryCodeAttempts indicates an expected call of ResetRecoveryCodeAttempts
+func (mr *MockMfaRedisRepositoryMockRecorder) ResetRecoveryCodeAttempts(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetRecoveryCodeAttempts", reflect.TypeOf((*MockMfaRedisRepository)(nil).ResetRecoveryCodeAttempts), ctx, userID)
+}
@@ -0,0 +1,169 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	reflect "reflect"
+)
+
+// MockMfaUseCase is a mock of MfaUseCase interface
+type MockMfaUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockMfaUseCaseMockRecorder
+}
+
+// MockMfaUseCaseMockRecorder is the mock recorder for MockMfaUseCase
+type MockMfaUseCaseMockRecorder struct {
+	mock *MockMfaUseCase
+}
+
+// NewMockMfaUseCase creates a new mock instance
+func NewMockMfaUseCase(ctrl *gomock.Controller) *MockMfaUseCase {
+	mock := &MockMfaUseCase{ctrl: ctrl}
+	mock.recorder = &MockMfaUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMfaUseCase) EXPECT() *MockMfaUseCaseMockRecorder {
+	return m.recorder
+}
+
+// EnableTOTP mocks base method
+func (m *MockMfaUseCase) EnableTOTP(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, user)
+	ret0, _ := ret[0].(*models.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP
+func (mr *MockMfaUseCaseMockRecorder) EnableTOTP(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockMfaUseCase)(nil).EnableTOTP), ctx, user)
+}
+
+// ConfirmTOTP mocks base method
+func (m *MockMfaUseCase) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP
+func (mr *MockMfaUseCaseMockRecorder) ConfirmTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockMfaUseCase)(nil).ConfirmTOTP), ctx, userID, code)
+}
+
+// DisableTOTP mocks base method
+func (m *MockMfaUseCase) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP
+func (mr *MockMfaUseCaseMockRecorder) DisableTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockMfaUseCase)(nil).DisableTOTP), ctx, userID, code)
+}
+
+// IsEnabled mocks base method
+func (m *MockMfaUseCase) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEnabled", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEnabled indicates an expected call of IsEnabled
+func (mr *MockMfaUseCaseMockRecorder) IsEnabled(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEnabled", reflect.TypeOf((*MockMfaUseCase)(nil).IsEnabled), ctx, userID)
+}
+
+// GetStatus mocks base method
+func (m *MockMfaUseCase) GetStatus(ctx context.Context, userID uuid.UUID) (*models.MFAStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatus", ctx, userID)
+	ret0, _ := ret[0].(*models.MFAStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatus indicates an expected call of GetStatus
+func (mr *MockMfaUseCaseMockRecorder) GetStatus(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockMfaUseCase)(nil).GetStatus), ctx, userID)
+}
+
+// CreateChallenge mocks base method
+func (m *MockMfaUseCase) CreateChallenge(ctx context.Context, userID uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChallenge", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateChallenge indicates an expected call of CreateChallenge
+func (mr *MockMfaUseCaseMockRecorder) CreateChallenge(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChallenge", reflect.TypeOf((*MockMfaUseCase)(nil).CreateChallenge), ctx, userID)
+}
+
+// VerifyChallenge mocks base method
+func (m *MockMfaUseCase) VerifyChallenge(ctx context.Context, challengeID, code string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyChallenge", ctx, challengeID, code)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyChallenge indicates an expected call of VerifyChallenge
+func (mr *MockMfaUseCaseMockRecorder) VerifyChallenge(ctx, challengeID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChallenge", reflect.TypeOf((*MockMfaUseCase)(nil).VerifyChallenge), ctx, challengeID, code)
+}
+
+// GenerateRecoveryCodes mocks base method
+func (m *MockMfaUseCase) GenerateRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateRecoveryCodes", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateRecoveryCodes indicates an expected call of GenerateRecoveryCodes
+func (mr *MockMfaUseCaseMockRecorder) GenerateRecoveryCodes(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRecoveryCodes", reflect.TypeOf((*MockMfaUseCase)(nil).GenerateRecoveryCodes), ctx, userID)
+}
+
+// UseRecoveryCode mocks base method
+func (m *MockMfaUseCase) UseRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, code)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode
+func (mr *MockMfaUseCaseMockRecorder) UseRecoveryCode(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockMfaUseCase)(nil).UseRecoveryCode), ctx, userID, code)
+}
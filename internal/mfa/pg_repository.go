@@ -0,0 +1,21 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository.go -package mock
+package mfa
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// MFA pg repository
+type MfaPGRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error)
+	Upsert(ctx context.Context, userMFA *models.UserMFA) error
+	UpdateTOTPLastStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*models.RecoveryCode) error
+	FindUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]*models.RecoveryCode, error)
+	UseRecoveryCode(ctx context.Context, codeID uuid.UUID) (bool, error)
+}
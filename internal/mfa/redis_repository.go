@@ -0,0 +1,21 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository.go -package mock
+package mfa
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// MFA challenges and code attempts Redis repository interface
+type MfaRedisRepository interface {
+	CreateChallenge(ctx context.Context, challenge *models.MFAChallenge, expire int) (string, error)
+	AttemptChallenge(ctx context.Context, challengeID string) (*models.MFAChallenge, error)
+	DeleteChallenge(ctx context.Context, challengeID string) (bool, error)
+	AttemptTOTP(ctx context.Context, userID uuid.UUID, expire int) (int, error)
+	ResetTOTPAttempts(ctx context.Context, userID uuid.UUID) error
+	AttemptRecoveryCode(ctx context.Context, userID uuid.UUID, expire int) (int, error)
+	ResetRecoveryCodeAttempts(ctx context.Context, userID uuid.UUID) error
+}
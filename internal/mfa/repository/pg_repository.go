@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// MFA repository
+type MfaRepository struct {
+	db *sqlx.DB
+}
+
+// MFA repository constructor
+func NewMfaPGRepository(db *sqlx.DB) *MfaRepository {
+	return &MfaRepository{db: db}
+}
+
+// Get user MFA settings
+func (r *MfaRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "MfaRepository.GetByUserID")
+	defer span.Finish()
+
+	userMFA := &models.UserMFA{}
+	if err := r.db.GetContext(ctx, userMFA, getByUserIDQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "GetByUserID.GetContext")
+	}
+
+	return userMFA, nil
+}
+
+// Create or replace user MFA settings
+func (r *MfaRepository) Upsert(ctx context.Context, userMFA *models.UserMFA) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "MfaRepository.Upsert")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(ctx, upsertQuery, userMFA.UserID, userMFA.TOTPSecret, userMFA.TOTPEnabled); err != nil {
+		return errors.Wrap(err, "Upsert.ExecContext")
+	}
+
+	return nil
+}
+
+// Store time step of accepted TOTP code, returns false if the same or a later step was already accepted
+func (r *MfaRepository) UpdateTOTPLastStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "MfaRepository.UpdateTOTPLastStep")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, updateTOTPLastStepQuery, userID, step)
+	if err != nil {
+		return false, errors.Wrap(err, "UpdateTOTPLastStep.ExecContext")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "UpdateTOTPLastStep.RowsAffected")
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// Delete user MFA settings
+func (r *MfaRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "MfaRepository.DeleteByUserID")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(ctx, deleteByUserIDQuery, userID); err != nil {
+		return errors.Wrap(err, "DeleteByUserID.ExecContext")
+	}
+
+	return nil
+}
+
+// Replace all recovery codes of user
+func (r *MfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*models.RecoveryCode) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "MfaRepository.ReplaceRecoveryCodes")
+	defer span.Finish()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "ReplaceRecoveryCodes.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, deleteRecoveryCodesQuery, userID); err != nil {
+		return errors.Wrap(err, "ReplaceRecoveryCodes.ExecContext")
+	}
+
+	for _, code := range codes {
+		if _, err := tx.ExecContext(ctx, createRecoveryCodeQuery, userID, code.CodeHash); err != nil {
+			return errors.Wrap(err, "ReplaceRecoveryCodes.ExecContext")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "ReplaceRecoveryCodes.Commit")
+	}
+
+	return nil
+}
+
+// Find recovery codes of user which were not used yet
+func (r *MfaRepository) FindUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]*models.RecoveryCode, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "MfaRepository.FindUnusedRecoveryCodes")
+	defer span.Finish()
+
+	var codes []*models.RecoveryCode
+	if err := r.db.SelectContext(ctx, &codes, findUnusedRecoveryCodesQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "FindUnusedRecoveryCodes.SelectContext")
+	}
+
+	return codes, nil
+}
+
+// Mark recovery code as used, returns false if it was already used
+func (r *MfaRepository) UseRecoveryCode(ctx context.Context, codeID uuid.UUID) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "MfaRepository.UseRecoveryCode")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, useRecoveryCodeQuery, codeID)
+	if err != nil {
+		return false, errors.Wrap(err, "UseRecoveryCode.ExecContext")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "UseRecoveryCode.RowsAffected")
+	}
+
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+func TestMfaRepository_RecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	mfaPGRepository := NewMfaPGRepository(sqlxDB)
+	userID := uuid.New()
+
+	t.Run("ReplaceRecoveryCodes", func(t *testing.T) {
+		codes := []*models.RecoveryCode{{CodeHash: "hash1"}, {CodeHash: "hash2"}}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteRecoveryCodesQuery).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(createRecoveryCodeQuery).WithArgs(userID, "hash1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(createRecoveryCodeQuery).WithArgs(userID, "hash2").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := mfaPGRepository.ReplaceRecoveryCodes(context.Background(), userID, codes)
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("FindUnusedRecoveryCodes", func(t *testing.T) {
+		codeID := uuid.New()
+		rows := sqlmock.NewRows([]string{"code_id", "user_id", "code_hash", "used_at", "created_at"}).
+			AddRow(codeID, userID, "hash1", nil, time.Now())
+
+		mock.ExpectQuery(findUnusedRecoveryCodesQuery).WithArgs(userID).WillReturnRows(rows)
+
+		codes, err := mfaPGRepository.FindUnusedRecoveryCodes(context.Background(), userID)
+		require.NoError(t, err)
+		require.Len(t, codes, 1)
+		require.Equal(t, codeID, codes[0].CodeID)
+		require.Nil(t, codes[0].UsedAt)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UseRecoveryCode", func(t *testing.T) {
+		codeID := uuid.New()
+
+		mock.ExpectExec(useRecoveryCodeQuery).WithArgs(codeID).WillReturnResult(sqlmock.NewResult(0, 1))
+		used, err := mfaPGRepository.UseRecoveryCode(context.Background(), codeID)
+		require.NoError(t, err)
+		require.True(t, used)
+
+		mock.ExpectExec(useRecoveryCodeQuery).WithArgs(codeID).WillReturnResult(sqlmock.NewResult(0, 0))
+		used, err = mfaPGRepository.UseRecoveryCode(context.Background(), codeID)
+		require.NoError(t, err)
+		require.False(t, used)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMfaRepository_UpdateTOTPLastStep(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	mfaPGRepository := NewMfaPGRepository(sqlxDB)
+	userID := uuid.New()
+
+	mock.ExpectExec(updateTOTPLastStepQuery).WithArgs(userID, int64(100)).WillReturnResult(sqlmock.NewResult(0, 1))
+	updated, err := mfaPGRepository.UpdateTOTPLastStep(context.Background(), userID, 100)
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	mock.ExpectExec(updateTOTPLastStepQuery).WithArgs(userID, int64(100)).WillReturnResult(sqlmock.NewResult(0, 0))
+	updated, err = mfaPGRepository.UpdateTOTPLastStep(context.Background(), userID, 100)
+	require.NoError(t, err)
+	require.False(t, updated)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
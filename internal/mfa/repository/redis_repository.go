@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+const (
+	challengePrefix    = "mfa_challenges:"
+	totpAttemptsPrefix = "mfa_totp_attempts:"
+
+	recoveryCodeAttemptsPrefix = "mfa_recovery_code_attempts:"
+)
+
+// Counts verification attempt of challenge and returns its data with the number of attempts
+var attemptChallengeScript = redis.NewScript(`
+local data = redis.call('HGET', KEYS[1], 'data')
+if not data then
+	return false
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+return {data, attempts}
+`)
+
+// Counts code attempt of user, the counter expires ARGV[1] milliseconds after the first attempt
+var attemptScript = redis.NewScript(`
+local attempts = redis.call('INCR', KEYS[1])
+if attempts == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return attempts
+`)
+
+// MFA challenges redis repository
+type mfaRedisRepo struct {
+	redisClient *redis.Client
+	basePrefix  string
+}
+
+// MFA challenges redis repository constructor
+func NewMfaRedisRepo(redisClient *redis.Client) *mfaRedisRepo {
+	return &mfaRedisRepo{redisClient: redisClient, basePrefix: challengePrefix}
+}
+
+// Create challenge with expiration in seconds
+func (r *mfaRedisRepo) CreateChallenge(ctx context.Context, challenge *models.MFAChallenge, expire int) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaRedisRepo.CreateChallenge")
+	defer span.Finish()
+
+	challenge.ChallengeID = uuid.New().String()
+	challengeBytes, err := json.Marshal(challenge)
+	if err != nil {
+		return "", errors.WithMessage(err, "mfaRedisRepo.CreateChallenge.json.Marshal")
+	}
+
+	challengeKey := r.createKey(challenge.ChallengeID)
+	pipe := r.redisClient.TxPipeline()
+	pipe.HMSet(ctx, challengeKey, "data", challengeBytes, "attempts", 0)
+	pipe.Expire(ctx, challengeKey, time.Second*time.Duration(expire))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", errors.Wrap(err, "mfaRedisRepo.CreateChallenge.Exec")
+	}
+
+	return challenge.ChallengeID, nil
+}
+
+// Count verification attempt and returns challenge, redis.Nil if it does not exist or expired
+func (r *mfaRedisRepo) AttemptChallenge(ctx context.Context, challengeID string) (*models.MFAChallenge, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaRedisRepo.AttemptChallenge")
+	defer span.Finish()
+
+	result, err := attemptChallengeScript.Run(ctx, r.redisClient, []string{r.createKey(challengeID)}).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "mfaRedisRepo.AttemptChallenge.Run")
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 2 {
+		return nil, errors.Errorf("mfaRedisRepo.AttemptChallenge: unexpected script result %v", result)
+	}
+	data, _ := values[0].(string)
+	attempts, _ := values[1].(int64)
+
+	challenge := &models.MFAChallenge{}
+	if err := json.Unmarshal([]byte(data), challenge); err != nil {
+		return nil, errors.Wrap(err, "mfaRedisRepo.AttemptChallenge.json.Unmarshal")
+	}
+	challenge.Attempts = int(attempts)
+
+	return challenge, nil
+}
+
+// Delete challenge, returns false if it was already deleted
+func (r *mfaRedisRepo) DeleteChallenge(ctx context.Context, challengeID string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaRedisRepo.DeleteChallenge")
+	defer span.Finish()
+
+	deleted, err := r.redisClient.Del(ctx, r.createKey(challengeID)).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "mfaRedisRepo.DeleteChallenge.Del")
+	}
+
+	return deleted == 1, nil
+}
+
+// Count TOTP code attempt of user outside of login challenges, returns the number of attempts
+// within expire seconds since the first one
+func (r *mfaRedisRepo) AttemptTOTP(ctx context.Context, userID uuid.UUID, expire int) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaRedisRepo.AttemptTOTP")
+	defer span.Finish()
+
+	attempts, err := r.attempt(ctx, r.createTOTPAttemptsKey(userID), expire)
+	if err != nil {
+		return 0, errors.Wrap(err, "mfaRedisRepo.AttemptTOTP")
+	}
+
+	return attempts, nil
+}
+
+// Reset TOTP code attempts counter of user
+func (r *mfaRedisRepo) ResetTOTPAttempts(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaRedisRepo.ResetTOTPAttempts")
+	defer span.Finish()
+
+	if err := r.redisClient.Del(ctx, r.createTOTPAttemptsKey(userID)).Err(); err != nil {
+		return errors.Wrap(err, "mfaRedisRepo.ResetTOTPAttempts.Del")
+	}
+
+	return nil
+}
+
+// Count recovery code attempt of user, returns the number of attempts within expire seconds since the first one
+func (r *mfaRedisRepo) AttemptRecoveryCode(ctx context.Context, userID uuid.UUID, expire int) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaRedisRepo.AttemptRecoveryCode")
+	defer span.Finish()
+
+	attempts, err := r.attempt(ctx, r.createRecoveryCodeAttemptsKey(userID), expire)
+	if err != nil {
+		return 0, errors.Wrap(err, "mfaRedisRepo.AttemptRecoveryCode")
+	}
+
+	return attempts, nil
+}
+
+// Reset recovery code attempts counter of user
+func (r *mfaRedisRepo) ResetRecoveryCodeAttempts(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaRedisRepo.ResetRecoveryCodeAttempts")
+	defer span.Finish()
+
+	if err := r.redisClient.Del(ctx, r.createRecoveryCodeAttemptsKey(userID)).Err(); err != nil {
+		return errors.Wrap(err, "mfaRedisRepo.ResetRecoveryCodeAttempts.Del")
+	}
+
+	return nil
+}
+
+func (r *mfaRedisRepo) attempt(ctx context.Context, key string, expire int) (int, error) {
+	expiration := time.Second * time.Duration(expire)
+	attempts, err := attemptScript.Run(ctx, r.redisClient, []string{key}, expiration.Milliseconds()).Int()
+	if err != nil {
+		return 0, errors.Wrap(err, "attemptScript.Run")
+	}
+	return attempts, nil
+}
+
+func (r *mfaRedisRepo) createRecoveryCodeAttemptsKey(userID uuid.UUID) string {
+	return fmt.Sprintf("%s: %s", recoveryCodeAttemptsPrefix, userID.String())
+}
+
+func (r *mfaRedisRepo) createTOTPAttemptsKey(userID uuid.UUID) string {
+	return fmt.Sprintf("%s: %s", totpAttemptsPrefix, userID.String())
+}
+
+func (r *mfaRedisRepo) createKey(challengeID string) string {
+	return fmt.Sprintf("%s: %s", r.basePrefix, challengeID)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+func SetupRedis() *mfaRedisRepo {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	mfaRedisRepository := NewMfaRedisRepo(client)
+	return mfaRedisRepository
+}
+
+func TestMfaRedisRepo_Challenge(t *testing.T) {
+	t.Parallel()
+
+	redisRepo := SetupRedis()
+
+	t.Run("AttemptChallenge", func(t *testing.T) {
+		userID := uuid.New()
+
+		challengeID, err := redisRepo.CreateChallenge(context.Background(), &models.MFAChallenge{UserID: userID}, 10)
+		require.NoError(t, err)
+		require.NotEqual(t, "", challengeID)
+
+		challenge, err := redisRepo.AttemptChallenge(context.Background(), challengeID)
+		require.NoError(t, err)
+		require.Equal(t, userID, challenge.UserID)
+		require.Equal(t, 1, challenge.Attempts)
+
+		challenge, err = redisRepo.AttemptChallenge(context.Background(), challengeID)
+		require.NoError(t, err)
+		require.Equal(t, 2, challenge.Attempts)
+	})
+
+	t.Run("DeleteChallenge", func(t *testing.T) {
+		challengeID, err := redisRepo.CreateChallenge(context.Background(), &models.MFAChallenge{UserID: uuid.New()}, 10)
+		require.NoError(t, err)
+
+		deleted, err := redisRepo.DeleteChallenge(context.Background(), challengeID)
+		require.NoError(t, err)
+		require.True(t, deleted)
+
+		deleted, err = redisRepo.DeleteChallenge(context.Background(), challengeID)
+		require.NoError(t, err)
+		require.False(t, deleted)
+
+		_, err = redisRepo.AttemptChallenge(context.Background(), challengeID)
+		require.Error(t, err)
+	})
+}
+
+func TestMfaRedisRepo_TOTPAttempts(t *testing.T) {
+	t.Parallel()
+
+	redisRepo := SetupRedis()
+	userID := uuid.New()
+
+	for i := 1; i <= 3; i++ {
+		attempts, err := redisRepo.AttemptTOTP(context.Background(), userID, 10)
+		require.NoError(t, err)
+		require.Equal(t, i, attempts)
+	}
+
+	ttl, err := redisRepo.redisClient.TTL(context.Background(), redisRepo.createTOTPAttemptsKey(userID)).Result()
+	require.NoError(t, err)
+	require.True(t, ttl > 0 && ttl <= 10*time.Second)
+
+	err = redisRepo.ResetTOTPAttempts(context.Background(), userID)
+	require.NoError(t, err)
+
+	attempts, err := redisRepo.AttemptTOTP(context.Background(), userID, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+}
+
+func TestMfaRedisRepo_RecoveryCodeAttempts(t *testing.T) {
+	t.Parallel()
+
+	redisRepo := SetupRedis()
+	userID := uuid.New()
+
+	for i := 1; i <= 3; i++ {
+		attempts, err := redisRepo.AttemptRecoveryCode(context.Background(), userID, 10)
+		require.NoError(t, err)
+		require.Equal(t, i, attempts)
+	}
+
+	// counters of TOTP and recovery codes are separate
+	attempts, err := redisRepo.AttemptTOTP(context.Background(), userID, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+
+	err = redisRepo.ResetRecoveryCodeAttempts(context.Background(), userID)
+	require.NoError(t, err)
+
+	attempts, err = redisRepo.AttemptRecoveryCode(context.Background(), userID, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+}
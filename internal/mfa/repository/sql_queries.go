@@ -0,0 +1,21 @@
+package repository
+
+const (
+	getByUserIDQuery = `SELECT user_id, totp_secret, totp_enabled, totp_last_step, created_at, updated_at FROM user_mfa WHERE user_id = $1`
+
+	upsertQuery = `INSERT INTO user_mfa (user_id, totp_secret, totp_enabled) VALUES ($1, $2, $3) 
+		ON CONFLICT (user_id) DO UPDATE SET totp_secret = EXCLUDED.totp_secret, totp_enabled = EXCLUDED.totp_enabled, updated_at = NOW()`
+
+	updateTOTPLastStepQuery = `UPDATE user_mfa SET totp_last_step = $2 WHERE user_id = $1 AND totp_last_step < $2`
+
+	deleteByUserIDQuery = `DELETE FROM user_mfa WHERE user_id = $1`
+
+	deleteRecoveryCodesQuery = `DELETE FROM user_recovery_codes WHERE user_id = $1`
+
+	createRecoveryCodeQuery = `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`
+
+	findUnusedRecoveryCodesQuery = `SELECT code_id, user_id, code_hash, used_at, created_at FROM user_recovery_codes 
+		WHERE user_id = $1 AND used_at IS NULL`
+
+	useRecoveryCodeQuery = `UPDATE user_recovery_codes SET used_at = NOW() WHERE code_id = $1 AND used_at IS NULL`
+)
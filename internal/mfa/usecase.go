@@ -0,0 +1,23 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase.go -package mock
+package mfa
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// MFA UseCase interface
+type MfaUseCase interface {
+	EnableTOTP(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	GetStatus(ctx context.Context, userID uuid.UUID) (*models.MFAStatus, error)
+	CreateChallenge(ctx context.Context, userID uuid.UUID) (string, error)
+	VerifyChallenge(ctx context.Context, challengeID string, code string) (uuid.UUID, error)
+	GenerateRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error)
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (int, error)
+}
@@ -0,0 +1,381 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/internal/mfa"
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/pkg/encryption"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+)
+
+const (
+	totpPeriod = 30
+	totpSkew   = 1
+
+	recoveryCodesCount = 10
+	recoveryCodeBytes  = 10
+)
+
+// MFA UseCase
+type mfaUseCase struct {
+	cfg       *config.Config
+	mfaPgRepo mfa.MfaPGRepository
+	redisRepo mfa.MfaRedisRepository
+	encryptor encryption.Encryptor
+}
+
+// New MFA UseCase, TOTP secrets are encrypted with encryptor before they are stored
+func NewMfaUseCase(
+	cfg *config.Config,
+	mfaPgRepo mfa.MfaPGRepository,
+	redisRepo mfa.MfaRedisRepository,
+	encryptor encryption.Encryptor,
+) *mfaUseCase {
+	return &mfaUseCase{cfg: cfg, mfaPgRepo: mfaPgRepo, redisRepo: redisRepo, encryptor: encryptor}
+}
+
+// Generate new TOTP secret for user, it is enabled after the first code is confirmed
+func (u *mfaUseCase) EnableTOTP(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaUseCase.EnableTOTP")
+	defer span.Finish()
+
+	enabled, err := u.IsEnabled(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, grpc_errors.ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      u.cfg.MFA.Issuer,
+		AccountName: user.Email,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "totp.Generate")
+	}
+
+	encryptedSecret, err := u.encryptor.Encrypt([]byte(key.Secret()))
+	if err != nil {
+		return nil, errors.Wrap(err, "encryptor.Encrypt")
+	}
+
+	if err := u.mfaPgRepo.Upsert(ctx, &models.UserMFA{UserID: user.UserID, TOTPSecret: encryptedSecret}); err != nil {
+		return nil, errors.Wrap(err, "mfaPgRepo.Upsert")
+	}
+
+	return &models.TOTPEnrollment{Secret: key.Secret(), URI: key.URL()}, nil
+}
+
+// Enable pending TOTP enrollment of user with code from authenticator app,
+// the user is locked out for MFA.ChallengeExpire after MFA.MaxAttempts invalid codes
+func (u *mfaUseCase) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaUseCase.ConfirmTOTP")
+	defer span.Finish()
+
+	userMFA, err := u.mfaPgRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.Wrap(grpc_errors.ErrMFANotEnabled, "no pending TOTP enrollment")
+		}
+		return errors.Wrap(err, "mfaPgRepo.GetByUserID")
+	}
+	if userMFA.TOTPEnabled {
+		return grpc_errors.ErrMFAAlreadyEnabled
+	}
+
+	if err := u.validateCodeAttempt(ctx, userMFA, code); err != nil {
+		return err
+	}
+
+	userMFA.TOTPEnabled = true
+	if err := u.mfaPgRepo.Upsert(ctx, userMFA); err != nil {
+		return errors.Wrap(err, "mfaPgRepo.Upsert")
+	}
+
+	return nil
+}
+
+// Disable TOTP of user, current code is required and attempts are limited like in ConfirmTOTP
+func (u *mfaUseCase) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaUseCase.DisableTOTP")
+	defer span.Finish()
+
+	userMFA, err := u.getEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.validateCodeAttempt(ctx, userMFA, code); err != nil {
+		return err
+	}
+
+	if err := u.mfaPgRepo.DeleteByUserID(ctx, userID); err != nil {
+		return errors.Wrap(err, "mfaPgRepo.DeleteByUserID")
+	}
+
+	return nil
+}
+
+// Check if user has confirmed TOTP
+func (u *mfaUseCase) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaUseCase.IsEnabled")
+	defer span.Finish()
+
+	userMFA, err := u.mfaPgRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "mfaPgRepo.GetByUserID")
+	}
+
+	return userMFA.TOTPEnabled, nil
+}
+
+// Get MFA enrollment state of user
+func (u *mfaUseCase) GetStatus(ctx context.Context, userID uuid.UUID) (*models.MFAStatus, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaUseCase.GetStatus")
+	defer span.Finish()
+
+	mfaStatus := &models.MFAStatus{}
+	userMFA, err := u.mfaPgRepo.GetByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "mfaPgRepo.GetByUserID")
+	}
+	if userMFA != nil && userMFA.TOTPEnabled {
+		mfaStatus.TOTPEnabled = true
+		mfaStatus.EnabledAt = &userMFA.UpdatedAt
+	}
+
+	recoveryCodes, err := u.mfaPgRepo.FindUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "mfaPgRepo.FindUnusedRecoveryCodes")
+	}
+	mfaStatus.RecoveryCodesRemaining = len(recoveryCodes)
+
+	return mfaStatus, nil
+}
+
+// Create pending second factor challenge of user login
+func (u *mfaUseCase) CreateChallenge(ctx context.Context, userID uuid.UUID) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaUseCase.CreateChallenge")
+	defer span.Finish()
+
+	return u.redisRepo.CreateChallenge(ctx, &models.MFAChallenge{UserID: userID}, u.cfg.MFA.ChallengeExpire)
+}
+
+// Verify TOTP code of challenge and returns its user, challenge is single use
+// and is discarded after MFA.MaxAttempts invalid codes. Invalid codes are also counted per user
+// like in ConfirmTOTP, so new challenges don't give more guesses.
+func (u *mfaUseCase) VerifyChallenge(ctx context.Context, challengeID string, code string) (uuid.UUID, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaUseCase.VerifyChallenge")
+	defer span.Finish()
+
+	challenge, err := u.redisRepo.AttemptChallenge(ctx, challengeID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return uuid.Nil, errors.Wrap(grpc_errors.ErrInvalidMFAChallenge, "redisRepo.AttemptChallenge")
+		}
+		return uuid.Nil, err
+	}
+	if challenge.Attempts > u.cfg.MFA.MaxAttempts {
+		if _, err := u.redisRepo.DeleteChallenge(ctx, challengeID); err != nil {
+			return uuid.Nil, err
+		}
+		return uuid.Nil, errors.Wrap(grpc_errors.ErrInvalidMFAChallenge, "too many attempts")
+	}
+
+	userMFA, err := u.getEnabled(ctx, challenge.UserID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := u.validateCodeAttempt(ctx, userMFA, code); err != nil {
+		return uuid.Nil, err
+	}
+
+	deleted, err := u.redisRepo.DeleteChallenge(ctx, challengeID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !deleted {
+		return uuid.Nil, errors.Wrap(grpc_errors.ErrInvalidMFAChallenge, "challenge already used")
+	}
+
+	return challenge.UserID, nil
+}
+
+// Generate new set of recovery codes for user, previous codes are invalidated.
+// Plain codes are returned only once, only their hashes are stored.
+func (u *mfaUseCase) GenerateRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaUseCase.GenerateRecoveryCodes")
+	defer span.Finish()
+
+	codes := make([]string, 0, recoveryCodesCount)
+	recoveryCodes := make([]*models.RecoveryCode, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errors.Wrap(err, "generateRecoveryCode")
+		}
+
+		recoveryCode := &models.RecoveryCode{UserID: userID}
+		if err := recoveryCode.HashCode(code); err != nil {
+			return nil, errors.Wrap(err, "recoveryCode.HashCode")
+		}
+
+		codes = append(codes, code)
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+	}
+
+	if err := u.mfaPgRepo.ReplaceRecoveryCodes(ctx, userID, recoveryCodes); err != nil {
+		return nil, errors.Wrap(err, "mfaPgRepo.ReplaceRecoveryCodes")
+	}
+
+	return codes, nil
+}
+
+// Consume recovery code of user, returns the number of codes left.
+// The user is locked out for MFA.ChallengeExpire after MFA.MaxAttempts invalid codes.
+func (u *mfaUseCase) UseRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "mfaUseCase.UseRecoveryCode")
+	defer span.Finish()
+
+	attempts, err := u.redisRepo.AttemptRecoveryCode(ctx, userID, u.cfg.MFA.ChallengeExpire)
+	if err != nil {
+		return 0, err
+	}
+	if attempts > u.cfg.MFA.MaxAttempts {
+		return 0, grpc_errors.ErrTooManyMFAAttempts
+	}
+
+	recoveryCodes, err := u.mfaPgRepo.FindUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return 0, errors.Wrap(err, "mfaPgRepo.FindUnusedRecoveryCodes")
+	}
+
+	for _, recoveryCode := range recoveryCodes {
+		if err := recoveryCode.CompareCode(code); err != nil {
+			continue
+		}
+
+		used, err := u.mfaPgRepo.UseRecoveryCode(ctx, recoveryCode.CodeID)
+		if err != nil {
+			return 0, errors.Wrap(err, "mfaPgRepo.UseRecoveryCode")
+		}
+		if !used {
+			break
+		}
+		if err := u.redisRepo.ResetRecoveryCodeAttempts(ctx, userID); err != nil {
+			return 0, err
+		}
+
+		return len(recoveryCodes) - 1, nil
+	}
+
+	return 0, grpc_errors.ErrInvalidRecoveryCode
+}
+
+func (u *mfaUseCase) getEnabled(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error) {
+	userMFA, err := u.mfaPgRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, grpc_errors.ErrMFANotEnabled
+		}
+		return nil, errors.Wrap(err, "mfaPgRepo.GetByUserID")
+	}
+	if !userMFA.TOTPEnabled {
+		return nil, grpc_errors.ErrMFANotEnabled
+	}
+
+	return userMFA, nil
+}
+
+// Validate code counting the attempt of user, the counter is reset once a code is accepted
+func (u *mfaUseCase) validateCodeAttempt(ctx context.Context, userMFA *models.UserMFA, code string) error {
+	attempts, err := u.redisRepo.AttemptTOTP(ctx, userMFA.UserID, u.cfg.MFA.ChallengeExpire)
+	if err != nil {
+		return err
+	}
+	if attempts > u.cfg.MFA.MaxAttempts {
+		return grpc_errors.ErrTooManyMFAAttempts
+	}
+
+	if err := u.validateCode(ctx, userMFA, code); err != nil {
+		return err
+	}
+
+	return u.redisRepo.ResetTOTPAttempts(ctx, userMFA.UserID)
+}
+
+// Validate code against time steps within skew of the current one. The accepted step is stored,
+// so a code can't be replayed and codes of earlier steps are rejected.
+func (u *mfaUseCase) validateCode(ctx context.Context, userMFA *models.UserMFA, code string) error {
+	secret, err := u.encryptor.Decrypt(userMFA.TOTPSecret)
+	if err != nil {
+		return errors.Wrap(err, "encryptor.Decrypt")
+	}
+
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	currentStep := time.Now().Unix() / totpPeriod
+	for step := currentStep - totpSkew; step <= currentStep+totpSkew; step++ {
+		expected, err := totp.GenerateCodeCustom(string(secret), time.Unix(step*totpPeriod, 0), opts)
+		if err != nil {
+			return errors.Wrap(err, "totp.GenerateCodeCustom")
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+			continue
+		}
+
+		if step <= userMFA.TOTPLastStep {
+			return errors.Wrap(grpc_errors.ErrInvalidMFACode, "code already used")
+		}
+		updated, err := u.mfaPgRepo.UpdateTOTPLastStep(ctx, userMFA.UserID, step)
+		if err != nil {
+			return errors.Wrap(err, "mfaPgRepo.UpdateTOTPLastStep")
+		}
+		if !updated {
+			return errors.Wrap(grpc_errors.ErrInvalidMFACode, "code already used")
+		}
+		userMFA.TOTPLastStep = step
+
+		return nil
+	}
+
+	return grpc_errors.ErrInvalidMFACode
+}
+
+// Random 80 bit recovery code formatted as groups of 4 characters, e.g. abcd-efgh-ijkl-mnop
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	groups := make([]string, 0, len(code)/4)
+	for i := 0; i < len(code); i += 4 {
+		groups = append(groups, code[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
@@ -0,0 +1,329 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/internal/mfa/mock"
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/pkg/encryption"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+)
+
+const testEncryptionKey = "dGhpcy1pcy1hLWRldmVsb3BtZW50LW9ubHkta2V5ISE="
+
+func setupMfaUseCase(t *testing.T, ctrl *gomock.Controller) (*mfaUseCase, *mock.MockMfaPGRepository, *mock.MockMfaRedisRepository) {
+	encryptor, err := encryption.NewAESEncryptor(testEncryptionKey)
+	require.NoError(t, err)
+
+	cfg := &config.Config{MFA: config.MFA{Issuer: "auth", ChallengeExpire: 60, MaxAttempts: 3}}
+	mfaPGRepository := mock.NewMockMfaPGRepository(ctrl)
+	mfaRedisRepository := mock.NewMockMfaRedisRepository(ctrl)
+
+	return NewMfaUseCase(cfg, mfaPGRepository, mfaRedisRepository, encryptor), mfaPGRepository, mfaRedisRepository
+}
+
+func TestMfaUseCase_EnableTOTP(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mfaUC, mfaPGRepository, mfaRedisRepository := setupMfaUseCase(t, ctrl)
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+
+	var stored *models.UserMFA
+	mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), user.UserID).Return(nil, sql.ErrNoRows)
+	mfaPGRepository.EXPECT().Upsert(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, userMFA *models.UserMFA) error {
+		stored = userMFA
+		return nil
+	})
+
+	enrollment, err := mfaUC.EnableTOTP(context.Background(), user)
+	require.NoError(t, err)
+	require.NotEqual(t, "", enrollment.Secret)
+	require.Contains(t, enrollment.URI, "otpauth://totp/")
+	require.False(t, stored.TOTPEnabled)
+	require.NotContains(t, string(stored.TOTPSecret), enrollment.Secret)
+
+	now := time.Now()
+	code, err := totp.GenerateCode(enrollment.Secret, now)
+	require.NoError(t, err)
+	nextCode, err := totp.GenerateCode(enrollment.Secret, now.Add(totpPeriod*time.Second))
+	require.NoError(t, err)
+
+	t.Run("ConfirmTOTP", func(t *testing.T) {
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), user.UserID).Return(stored, nil)
+		mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), user.UserID, 60).Return(1, nil)
+		mfaPGRepository.EXPECT().UpdateTOTPLastStep(gomock.Any(), user.UserID, now.Unix()/totpPeriod).Return(true, nil)
+		mfaRedisRepository.EXPECT().ResetTOTPAttempts(gomock.Any(), user.UserID).Return(nil)
+		mfaPGRepository.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := mfaUC.ConfirmTOTP(context.Background(), user.UserID, code)
+		require.NoError(t, err)
+		require.True(t, stored.TOTPEnabled)
+		require.Equal(t, now.Unix()/totpPeriod, stored.TOTPLastStep)
+	})
+
+	t.Run("Already enabled", func(t *testing.T) {
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), user.UserID).Return(stored, nil)
+
+		_, err := mfaUC.EnableTOTP(context.Background(), user)
+		require.True(t, errors.Is(err, grpc_errors.ErrMFAAlreadyEnabled))
+	})
+
+	t.Run("DisableTOTP invalid code", func(t *testing.T) {
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), user.UserID).Return(stored, nil)
+		mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), user.UserID, 60).Return(1, nil)
+
+		err := mfaUC.DisableTOTP(context.Background(), user.UserID, "000000x")
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidMFACode))
+	})
+
+	t.Run("DisableTOTP replayed code", func(t *testing.T) {
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), user.UserID).Return(stored, nil)
+		mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), user.UserID, 60).Return(2, nil)
+
+		err := mfaUC.DisableTOTP(context.Background(), user.UserID, code)
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidMFACode))
+	})
+
+	t.Run("DisableTOTP too many attempts", func(t *testing.T) {
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), user.UserID).Return(stored, nil)
+		mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), user.UserID, 60).Return(4, nil)
+
+		err := mfaUC.DisableTOTP(context.Background(), user.UserID, nextCode)
+		require.True(t, errors.Is(err, grpc_errors.ErrTooManyMFAAttempts))
+	})
+
+	t.Run("DisableTOTP", func(t *testing.T) {
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), user.UserID).Return(stored, nil)
+		mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), user.UserID, 60).Return(3, nil)
+		mfaPGRepository.EXPECT().UpdateTOTPLastStep(gomock.Any(), user.UserID, now.Unix()/totpPeriod+1).Return(true, nil)
+		mfaRedisRepository.EXPECT().ResetTOTPAttempts(gomock.Any(), user.UserID).Return(nil)
+		mfaPGRepository.EXPECT().DeleteByUserID(gomock.Any(), user.UserID).Return(nil)
+
+		err := mfaUC.DisableTOTP(context.Background(), user.UserID, nextCode)
+		require.NoError(t, err)
+	})
+}
+
+func TestMfaUseCase_VerifyChallenge(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mfaUC, mfaPGRepository, mfaRedisRepository := setupMfaUseCase(t, ctrl)
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "auth", AccountName: "email@gmail.com"})
+	require.NoError(t, err)
+	encryptedSecret, err := mfaUC.encryptor.Encrypt([]byte(key.Secret()))
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	userMFA := &models.UserMFA{UserID: userID, TOTPSecret: encryptedSecret, TOTPEnabled: true}
+	now := time.Now()
+	code, err := totp.GenerateCode(key.Secret(), now)
+	require.NoError(t, err)
+
+	t.Run("Verify", func(t *testing.T) {
+		challenge := &models.MFAChallenge{ChallengeID: "valid", UserID: userID, Attempts: 1}
+
+		mfaRedisRepository.EXPECT().AttemptChallenge(gomock.Any(), challenge.ChallengeID).Return(challenge, nil)
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), userID).Return(userMFA, nil)
+		mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), userID, 60).Return(1, nil)
+		mfaPGRepository.EXPECT().UpdateTOTPLastStep(gomock.Any(), userID, now.Unix()/totpPeriod).Return(true, nil)
+		mfaRedisRepository.EXPECT().ResetTOTPAttempts(gomock.Any(), userID).Return(nil)
+		mfaRedisRepository.EXPECT().DeleteChallenge(gomock.Any(), challenge.ChallengeID).Return(true, nil)
+
+		verifiedUserID, err := mfaUC.VerifyChallenge(context.Background(), challenge.ChallengeID, code)
+		require.NoError(t, err)
+		require.Equal(t, userID, verifiedUserID)
+	})
+
+	t.Run("Already used", func(t *testing.T) {
+		challenge := &models.MFAChallenge{ChallengeID: "used", UserID: userID, Attempts: 1}
+		otherCode, err := totp.GenerateCode(key.Secret(), now.Add(totpPeriod*time.Second))
+		require.NoError(t, err)
+
+		mfaRedisRepository.EXPECT().AttemptChallenge(gomock.Any(), challenge.ChallengeID).Return(challenge, nil)
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), userID).Return(userMFA, nil)
+		mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), userID, 60).Return(1, nil)
+		mfaPGRepository.EXPECT().UpdateTOTPLastStep(gomock.Any(), userID, now.Unix()/totpPeriod+1).Return(true, nil)
+		mfaRedisRepository.EXPECT().ResetTOTPAttempts(gomock.Any(), userID).Return(nil)
+		mfaRedisRepository.EXPECT().DeleteChallenge(gomock.Any(), challenge.ChallengeID).Return(false, nil)
+
+		_, err = mfaUC.VerifyChallenge(context.Background(), challenge.ChallengeID, otherCode)
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidMFAChallenge))
+	})
+
+	t.Run("Replayed code", func(t *testing.T) {
+		challenge := &models.MFAChallenge{ChallengeID: "replayed", UserID: userID, Attempts: 1}
+		replayedMFA := &models.UserMFA{UserID: userID, TOTPSecret: encryptedSecret, TOTPEnabled: true}
+
+		mfaRedisRepository.EXPECT().AttemptChallenge(gomock.Any(), challenge.ChallengeID).Return(challenge, nil)
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), userID).Return(replayedMFA, nil)
+		mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), userID, 60).Return(1, nil)
+		// step was concurrently accepted by another request
+		mfaPGRepository.EXPECT().UpdateTOTPLastStep(gomock.Any(), userID, now.Unix()/totpPeriod).Return(false, nil)
+
+		_, err := mfaUC.VerifyChallenge(context.Background(), challenge.ChallengeID, code)
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidMFACode))
+	})
+
+	t.Run("Too many attempts", func(t *testing.T) {
+		challenge := &models.MFAChallenge{ChallengeID: "exhausted", UserID: userID, Attempts: 4}
+
+		mfaRedisRepository.EXPECT().AttemptChallenge(gomock.Any(), challenge.ChallengeID).Return(challenge, nil)
+		mfaRedisRepository.EXPECT().DeleteChallenge(gomock.Any(), challenge.ChallengeID).Return(true, nil)
+
+		_, err := mfaUC.VerifyChallenge(context.Background(), challenge.ChallengeID, code)
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidMFAChallenge))
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		challenge := &models.MFAChallenge{ChallengeID: "invalid", UserID: userID, Attempts: 1}
+
+		mfaRedisRepository.EXPECT().AttemptChallenge(gomock.Any(), challenge.ChallengeID).Return(challenge, nil)
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), userID).Return(userMFA, nil)
+		mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), userID, 60).Return(1, nil)
+
+		_, err := mfaUC.VerifyChallenge(context.Background(), challenge.ChallengeID, "abcdef")
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidMFACode))
+	})
+
+	t.Run("Too many attempts across challenges", func(t *testing.T) {
+		otherUserID := uuid.New()
+		otherMFA := &models.UserMFA{UserID: otherUserID, TOTPSecret: encryptedSecret, TOTPEnabled: true}
+
+		for attempts := 1; attempts <= 4; attempts++ {
+			// every login creates a fresh challenge with its own attempts counter
+			challenge := &models.MFAChallenge{ChallengeID: uuid.New().String(), UserID: otherUserID, Attempts: 1}
+
+			mfaRedisRepository.EXPECT().AttemptChallenge(gomock.Any(), challenge.ChallengeID).Return(challenge, nil)
+			mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), otherUserID).Return(otherMFA, nil)
+			mfaRedisRepository.EXPECT().AttemptTOTP(gomock.Any(), otherUserID, 60).Return(attempts, nil)
+
+			if attempts <= 3 {
+				_, err := mfaUC.VerifyChallenge(context.Background(), challenge.ChallengeID, "abcdef")
+				require.True(t, errors.Is(err, grpc_errors.ErrInvalidMFACode))
+				continue
+			}
+			// even the valid code is rejected once the user is locked out
+			_, err := mfaUC.VerifyChallenge(context.Background(), challenge.ChallengeID, code)
+			require.True(t, errors.Is(err, grpc_errors.ErrTooManyMFAAttempts))
+		}
+	})
+}
+
+func TestMfaUseCase_RecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mfaUC, mfaPGRepository, mfaRedisRepository := setupMfaUseCase(t, ctrl)
+	userID := uuid.New()
+
+	var stored []*models.RecoveryCode
+	mfaPGRepository.EXPECT().ReplaceRecoveryCodes(gomock.Any(), userID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, codes []*models.RecoveryCode) error {
+			for _, code := range codes {
+				code.CodeID = uuid.New()
+			}
+			stored = codes
+			return nil
+		},
+	)
+
+	codes, err := mfaUC.GenerateRecoveryCodes(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodesCount)
+	require.Len(t, stored, recoveryCodesCount)
+	require.NotEqual(t, codes[0], codes[1])
+	require.NotContains(t, stored[0].CodeHash, models.NormalizeRecoveryCode(codes[0]))
+
+	t.Run("UseRecoveryCode", func(t *testing.T) {
+		mfaRedisRepository.EXPECT().AttemptRecoveryCode(gomock.Any(), userID, 60).Return(1, nil)
+		mfaPGRepository.EXPECT().FindUnusedRecoveryCodes(gomock.Any(), userID).Return(stored, nil)
+		mfaPGRepository.EXPECT().UseRecoveryCode(gomock.Any(), stored[1].CodeID).Return(true, nil)
+		mfaRedisRepository.EXPECT().ResetRecoveryCodeAttempts(gomock.Any(), userID).Return(nil)
+
+		remaining, err := mfaUC.UseRecoveryCode(context.Background(), userID, strings.ToUpper(codes[1]))
+		require.NoError(t, err)
+		require.Equal(t, recoveryCodesCount-1, remaining)
+	})
+
+	t.Run("Used concurrently", func(t *testing.T) {
+		mfaRedisRepository.EXPECT().AttemptRecoveryCode(gomock.Any(), userID, 60).Return(1, nil)
+		mfaPGRepository.EXPECT().FindUnusedRecoveryCodes(gomock.Any(), userID).Return(stored[:1], nil)
+		mfaPGRepository.EXPECT().UseRecoveryCode(gomock.Any(), stored[0].CodeID).Return(false, nil)
+
+		_, err := mfaUC.UseRecoveryCode(context.Background(), userID, codes[0])
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidRecoveryCode))
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		mfaRedisRepository.EXPECT().AttemptRecoveryCode(gomock.Any(), userID, 60).Return(2, nil)
+		mfaPGRepository.EXPECT().FindUnusedRecoveryCodes(gomock.Any(), userID).Return(stored[:2], nil)
+
+		_, err := mfaUC.UseRecoveryCode(context.Background(), userID, "aaaa-bbbb-cccc-dddd")
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidRecoveryCode))
+	})
+
+	t.Run("Too many attempts", func(t *testing.T) {
+		// codes are not compared once the user is locked out, even the valid one is rejected
+		mfaRedisRepository.EXPECT().AttemptRecoveryCode(gomock.Any(), userID, 60).Return(4, nil)
+
+		_, err := mfaUC.UseRecoveryCode(context.Background(), userID, codes[2])
+		require.True(t, errors.Is(err, grpc_errors.ErrTooManyMFAAttempts))
+	})
+}
+
+func TestMfaUseCase_GetStatus(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mfaUC, mfaPGRepository, _ := setupMfaUseCase(t, ctrl)
+	userID := uuid.New()
+
+	t.Run("Enabled", func(t *testing.T) {
+		enabledAt := time.Now()
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), userID).Return(&models.UserMFA{
+			UserID:      userID,
+			TOTPSecret:  []byte("secret"),
+			TOTPEnabled: true,
+			UpdatedAt:   enabledAt,
+		}, nil)
+		mfaPGRepository.EXPECT().FindUnusedRecoveryCodes(gomock.Any(), userID).Return([]*models.RecoveryCode{{}, {}}, nil)
+
+		mfaStatus, err := mfaUC.GetStatus(context.Background(), userID)
+		require.NoError(t, err)
+		require.True(t, mfaStatus.TOTPEnabled)
+		require.Equal(t, enabledAt, *mfaStatus.EnabledAt)
+		require.Equal(t, 2, mfaStatus.RecoveryCodesRemaining)
+	})
+
+	t.Run("Not enrolled", func(t *testing.T) {
+		mfaPGRepository.EXPECT().GetByUserID(gomock.Any(), userID).Return(nil, sql.ErrNoRows)
+		mfaPGRepository.EXPECT().FindUnusedRecoveryCodes(gomock.Any(), userID).Return(nil, nil)
+
+		mfaStatus, err := mfaUC.GetStatus(context.Background(), userID)
+		require.NoError(t, err)
+		require.False(t, mfaStatus.TOTPEnabled)
+		require.Nil(t, mfaStatus.EnabledAt)
+	})
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	AuditEventLogin              = "login"
+	AuditEventLogout             = "logout"
+	AuditEventPasswordChanged    = "password_changed"
+	AuditEventPasswordReset      = "password_reset"
+	AuditEventEmailChanged       = "email_changed"
+	AuditEventAccountDeleted     = "account_deleted"
+	AuditEventMFAEnabled         = "mfa_enabled"
+	AuditEventMFADisabled        = "mfa_disabled"
+	AuditEventPasskeyRegistered  = "passkey_registered"
+	AuditEventDataExported       = "data_exported"
+	AuditEventSessionRevoked     = "session_revoked"
+	AuditEventSessionsRevoked    = "other_sessions_revoked"
+	AuditEventAllSessionsRevoked = "all_sessions_revoked"
+)
+
+// Security relevant action on user account
+type AuditEvent struct {
+	EventID   uuid.UUID     `json:"event_id" db:"event_id"`
+	UserID    uuid.UUID     `json:"user_id" db:"user_id"`
+	Type      string        `json:"type" db:"event_type"`
+	Metadata  AuditMetadata `json:"metadata" db:"metadata"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+}
+
+// Free-form details of audit event, stored as jsonb
+type AuditMetadata map[string]string
+
+// Value implements driver.Valuer
+func (m AuditMetadata) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner
+func (m *AuditMetadata) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*m = AuditMetadata{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("AuditMetadata.Scan: unsupported type %T", src)
+	}
+	return json.Unmarshal(data, m)
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Machine-readable archive of data stored about user, secrets like password hash,
+// TOTP secret and session tokens are never included
+type UserDataExport struct {
+	ExportedAt   time.Time        `json:"exported_at"`
+	Profile      *User            `json:"profile"`
+	Sessions     []*ExportSession `json:"sessions"`
+	MFA          *MFAStatus       `json:"mfa"`
+	Passkeys     []*Passkey       `json:"passkeys"`
+	LoginHistory []*AuditEvent    `json:"login_history"`
+	AuditEvents  []*AuditEvent    `json:"audit_events"`
+}
+
+// Active session of user without its id, session id is a bearer token
+type ExportSession struct {
+	IPAddress   string    `json:"ip_address,omitempty"`
+	UserAgent   string    `json:"user_agent,omitempty"`
+	LoginMethod string    `json:"login_method,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
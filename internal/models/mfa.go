@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// User multi-factor authentication settings, TOTP secret is stored encrypted.
+// TOTPLastStep is the time step of the last accepted code, codes of this or earlier steps are rejected.
+type UserMFA struct {
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	TOTPSecret   []byte    `json:"-" db:"totp_secret"`
+	TOTPEnabled  bool      `json:"totp_enabled" db:"totp_enabled"`
+	TOTPLastStep int64     `json:"-" db:"totp_last_step"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TOTP enrollment returned to user once to configure authenticator app
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// Pending second factor verification of login
+type MFAChallenge struct {
+	ChallengeID string    `json:"challenge_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Attempts    int       `json:"-"`
+}
+
+// Single-use account recovery code, only bcrypt hash of code is stored
+type RecoveryCode struct {
+	CodeID    uuid.UUID  `json:"code_id" db:"code_id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	CodeHash  string     `json:"-" db:"code_hash"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Hash recovery code
+func (c *RecoveryCode) HashCode(code string) error {
+	hashedCode, err := bcrypt.GenerateFromPassword([]byte(NormalizeRecoveryCode(code)), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	c.CodeHash = string(hashedCode)
+	return nil
+}
+
+// Compare recovery code with stored hash
+func (c *RecoveryCode) CompareCode(code string) error {
+	return bcrypt.CompareHashAndPassword([]byte(c.CodeHash), []byte(NormalizeRecoveryCode(code)))
+}
+
+// Normalize recovery code typed by user, separators and case are ignored
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// MFA enrollment state of user without secrets
+type MFAStatus struct {
+	TOTPEnabled            bool       `json:"totp_enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PasskeyCeremonyRegistration = "registration"
+	PasskeyCeremonyLogin        = "login"
+
+	MaxPasskeyNameLength = 64
+	defaultPasskeyName   = "Passkey"
+)
+
+// WebAuthn credential of user
+type Passkey struct {
+	CredentialID    []byte     `json:"credential_id" db:"credential_id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	Name            string     `json:"name" db:"name"`
+	PublicKey       []byte     `json:"-" db:"public_key"`
+	AttestationType string     `json:"attestation_type" db:"attestation_type"`
+	AAGUID          []byte     `json:"aaguid" db:"aaguid"`
+	SignCount       uint32     `json:"sign_count" db:"sign_count"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// Prepare passkey for create
+func (p *Passkey) PrepareCreate() {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		p.Name = defaultPasskeyName
+	}
+}
+
+// Pending WebAuthn registration or login ceremony
+type PasskeyChallenge struct {
+	ChallengeID          string    `json:"challenge_id"`
+	UserID               uuid.UUID `json:"user_id"`
+	Ceremony             string    `json:"ceremony"`
+	Challenge            string    `json:"challenge"`
+	AllowedCredentialIDs [][]byte  `json:"allowed_credential_ids,omitempty"`
+	UserVerification     string    `json:"user_verification"`
+}
+
+// WebAuthn ceremony started by relying party, options are passed to navigator.credentials as JSON
+type PasskeyCeremony struct {
+	ChallengeID string
+	Options     []byte
+}
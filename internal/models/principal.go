@@ -0,0 +1,17 @@
+package models
+
+import "github.com/google/uuid"
+
+// Authenticated caller of rpc method
+type Principal struct {
+	UserID      uuid.UUID
+	Roles       []string
+	Permissions []string
+	SessionID   string
+}
+
+// Check if caller is granted permission by any of its roles
+func (p *Principal) HasPermission(permission string) bool {
+	_, ok := MatchPermission(p.Permissions, permission)
+	return ok
+}
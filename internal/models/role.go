@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Built-in roles
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Built-in permissions, named as resource:action
+const (
+	PermissionUsersRead        = "users:read"
+	PermissionUsersWrite       = "users:write"
+	PermissionRolesManage      = "roles:manage"
+	PermissionKeysRotate       = "keys:rotate"
+	PermissionPermissionsCheck = "permissions:check"
+	PermissionTokensIntrospect = "tokens:introspect"
+)
+
+// Role model, permissions are granted to users through their roles
+type Role struct {
+	Name        string    `json:"name" db:"name" validate:"required,lte=64"`
+	Description string    `json:"description" db:"description" validate:"lte=250"`
+	Permissions []string  `json:"permissions" db:"-" validate:"dive,required,lte=64"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Prepare role for create
+func (r *Role) PrepareCreate() {
+	r.Name = NormalizeAccessName(r.Name)
+	r.Description = strings.TrimSpace(r.Description)
+	for i, permission := range r.Permissions {
+		r.Permissions[i] = NormalizeAccessName(permission)
+	}
+}
+
+// Normalize role or permission name
+func NormalizeAccessName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Authorization decision for user permission
+type PermissionDecision struct {
+	Permission string `json:"permission"`
+	Allowed    bool   `json:"allowed"`
+	Reason     string `json:"reason"`
+}
+
+// Build permission name of action on resource
+func PermissionName(resource string, action string) string {
+	return NormalizeAccessName(resource) + ":" + NormalizeAccessName(action)
+}
+
+// Find granted permission matching required one, a grant matches exactly,
+// by resource wildcard (users:*) or by full wildcard (*)
+func MatchPermission(granted []string, permission string) (string, bool) {
+	resourceWildcard := permission
+	if i := strings.Index(permission, ":"); i >= 0 {
+		resourceWildcard = permission[:i] + ":*"
+	}
+
+	match := ""
+	for _, grant := range granted {
+		switch grant {
+		case permission:
+			return grant, true
+		case resourceWildcard, "*", "*:*":
+			match = grant
+		}
+	}
+
+	return match, match != ""
+}
@@ -1,9 +1,58 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
 
-// Session model
+	"github.com/google/uuid"
+)
+
+// Session model, client details are captured at login and refresh so user can recognise the device,
+// authenticated at is the time of login which started refresh family and is kept by refreshed sessions
 type Session struct {
-	SessionID string    `json:"session_id"`
-	UserID    uuid.UUID `json:"user_id"`
+	SessionID       string    `json:"session_id"`
+	UserID          uuid.UUID `json:"user_id"`
+	FamilyID        string    `json:"family_id"`
+	IPAddress       string    `json:"ip_address,omitempty"`
+	UserAgent       string    `json:"user_agent,omitempty"`
+	LoginMethod     string    `json:"login_method,omitempty"`
+	AuthenticatedAt time.Time `json:"authenticated_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	LastSeenAt      time.Time `json:"last_seen_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+// Session limit policies applied when user logs in having the maximum number of sessions
+const (
+	SessionLimitPolicyReject      = "reject"
+	SessionLimitPolicyEvictOldest = "evict_oldest"
+)
+
+// Maximum number of simultaneous sessions of user, evict oldest replaces the oldest session instead of rejecting login
+type SessionLimit struct {
+	MaxSessions int
+	EvictOldest bool
+}
+
+// Client calling rpc method
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Public id of session which can be shown to its user, session id itself is a bearer token
+func (s *Session) PublicID() string {
+	sum := sha256.Sum256([]byte(s.SessionID))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Refresh token model, every rotated token of one login shares the same family
+type RefreshToken struct {
+	FamilyID        string    `json:"family_id"`
+	UserID          uuid.UUID `json:"user_id"`
+	SessionID       string    `json:"session_id"`
+	LoginMethod     string    `json:"login_method,omitempty"`
+	AuthenticatedAt time.Time `json:"authenticated_at"`
+	Reused          bool      `json:"-"`
 }
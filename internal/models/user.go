@@ -10,15 +10,17 @@ import (
 
 // User base model
 type User struct {
-	UserID    uuid.UUID `json:"user_id" db:"user_id" validate:"omitempty"`
-	Email     string    `json:"email" db:"email" validate:"omitempty,lte=60,email"`
-	FirstName string    `json:"first_name" db:"first_name" validate:"required,lte=30"`
-	LastName  string    `json:"last_name" db:"last_name" validate:"required,lte=30"`
-	Role      string    `json:"role" db:"role" validate:"required"`
-	Avatar    *string   `json:"avatar" db:"avatar"`
-	Password  string    `json:"password,omitempty" db:"password"`
-	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id" validate:"omitempty"`
+	Email         string    `json:"email" db:"email" validate:"omitempty,lte=60,email"`
+	EmailVerified bool      `json:"email_verified" db:"email_verified"`
+	FirstName     string    `json:"first_name" db:"first_name" validate:"required,lte=30"`
+	LastName      string    `json:"last_name" db:"last_name" validate:"required,lte=30"`
+	Roles         []string  `json:"roles" db:"-"`
+	Permissions   []string  `json:"permissions" db:"-"`
+	Avatar        *string   `json:"avatar" db:"avatar"`
+	Password      string    `json:"password,omitempty" db:"password"`
+	CreatedAt     time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at,omitempty" db:"updated_at"`
 }
 
 // Sanitize password
@@ -41,19 +43,31 @@ func (u *User) ComparePasswords(password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 }
 
+// Replace password with hash of the new one
+func (u *User) SetPassword(password string) error {
+	u.Password = NormalizePassword(password)
+	return u.HashPassword()
+}
+
+// Normalize password typed by user, surrounding whitespace is ignored
+func NormalizePassword(password string) string {
+	return strings.TrimSpace(password)
+}
+
+// Normalize email address for storage and lookup
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 // Prepare user for register
 func (u *User) PrepareCreate() error {
-	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
-	u.Password = strings.TrimSpace(u.Password)
+	u.Email = NormalizeEmail(u.Email)
+	u.Password = NormalizePassword(u.Password)
 
 	if err := u.HashPassword(); err != nil {
 		return err
 	}
 
-	if u.Role != "" {
-		u.Role = strings.ToLower(strings.TrimSpace(u.Role))
-	}
-
 	return nil
 }
 
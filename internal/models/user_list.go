@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	UserStatusActive     = "active"
+	UserStatusUnverified = "unverified"
+	UserStatusDeleted    = "deleted"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// Filter and page of users list, users are ordered by creation time and id
+type UserListFilter struct {
+	Role          string
+	Status        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Prefix        string
+	SortOrder     string
+	Limit         int
+	Cursor        *UserListCursor
+}
+
+// Ranked search of users by names and email, results are paged by offset
+type UserSearchFilter struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// User found by search with its relevance, higher rank goes first
+type UserSearchResult struct {
+	User
+	Rank float64 `json:"rank" db:"rank"`
+}
+
+// Page of users search results
+type UserSearchPage struct {
+	Results []*UserSearchResult
+	HasMore bool
+}
+
+// Position of the last user of page, next page starts right after it
+type UserListCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+// Page of users list
+type UserList struct {
+	Users      []*User
+	NextCursor *UserListCursor
+}
+
+// Encode cursor to opaque page token
+func (c *UserListCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode page token created by UserListCursor.Encode
+func DecodeUserListCursor(token string) (*UserListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "base64.DecodeString")
+	}
+
+	cursor := &UserListCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal")
+	}
+	if cursor.UserID == uuid.Nil || cursor.CreatedAt.IsZero() {
+		return nil, errors.New("incomplete cursor")
+	}
+
+	return cursor, nil
+}
+
+// Escape LIKE pattern wildcards of prefix typed by user
+func EscapeLikePattern(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix)
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailChange       = "email_change"
+)
+
+// One-time token emailed to user, bound to the current email address of the user.
+// NewEmail is set for email change tokens, which are sent to the new address.
+type UserToken struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Purpose  string    `json:"purpose"`
+	Email    string    `json:"email"`
+	NewEmail string    `json:"new_email,omitempty"`
+}
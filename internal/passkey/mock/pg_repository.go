@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pg_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	reflect "reflect"
+)
+
+// MockPasskeyPGRepository is a mock of PasskeyPGRepository interface
+type MockPasskeyPGRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasskeyPGRepositoryMockRecorder
+}
+
+// MockPasskeyPGRepositoryMockRecorder is the mock recorder for MockPasskeyPGRepository
+type MockPasskeyPGRepositoryMockRecorder struct {
+	mock *MockPasskeyPGRepository
+}
+
+// NewMockPasskeyPGRepository creates a new mock instance
+func NewMockPasskeyPGRepository(ctrl *gomock.Controller) *MockPasskeyPGRepository {
+	mock := &MockPasskeyPGRepository{ctrl: ctrl}
+	mock.recorder = &MockPasskeyPGRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPasskeyPGRepository) EXPECT() *MockPasskeyPGRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockPasskeyPGRepository) Create(ctx context.Context, passkey *models.Passkey) (*models.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, passkey)
+	ret0, _ := ret[0].(*models.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockPasskeyPGRepositoryMockRecorder) Create(ctx, passkey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPasskeyPGRepository)(nil).Create), ctx, passkey)
+}
+
+// FindByUserID mocks base method
+func (m *MockPasskeyPGRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID
+func (mr *MockPasskeyPGRepositoryMockRecorder) FindByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockPasskeyPGRepository)(nil).FindByUserID), ctx, userID)
+}
+
+// UpdateSignCount mocks base method
+func (m *MockPasskeyPGRepository) UpdateSignCount(ctx context.Context, credentialID []byte, signCount uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSignCount", ctx, credentialID, signCount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSignCount indicates an expected call of UpdateSignCount
+func (mr *MockPasskeyPGRepositoryMockRecorder) UpdateSignCount(ctx, credentialID, signCount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSignCount", reflect.TypeOf((*MockPasskeyPGRepository)(nil).UpdateSignCount), ctx, credentialID, signCount)
+}
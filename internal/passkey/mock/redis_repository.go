@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: redis_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockPasskeyRedisRepository is a mock of PasskeyRedisRepository interface
+type MockPasskeyRedisRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasskeyRedisRepositoryMockRecorder
+}
+
+// MockPasskeyRedisRepositoryMockRecorder is the mock recorder for MockPasskeyRedisRepository
+type MockPasskeyRedisRepositoryMockRecorder struct {
+	mock *MockPasskeyRedisRepository
+}
+
+// NewMockPasskeyRedisRepository creates a new mock instance
+func NewMockPasskeyRedisRepository(ctrl *gomock.Controller) *MockPasskeyRedisRepository {
+	mock := &MockPasskeyRedisRepository{ctrl: ctrl}
+	mock.recorder = &MockPasskeyRedisRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPasskeyRedisRepository) EXPECT() *MockPasskeyRedisRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateChallenge mocks base method
+func (m *MockPasskeyRedisRepository) CreateChallenge(ctx context.Context, challenge *models.PasskeyChallenge, expire int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChallenge", ctx, challenge, expire)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateChallenge indicates an expected call of CreateChallenge
+func (mr *MockPasskeyRedisRepositoryMockRecorder) CreateChallenge(ctx, challenge, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChallenge", reflect.TypeOf((*MockPasskeyRedisRepository)(nil).CreateChallenge), ctx, challenge, expire)
+}
+
+// TakeChallenge mocks base method
+func (m *MockPasskeyRedisRepository) TakeChallenge(ctx context.Context, challengeID string) (*models.PasskeyChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeChallenge", ctx, challengeID)
+	ret0, _ := ret[0].(*models.PasskeyChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeChallenge indicates an expected call of TakeChallenge
+func (mr *MockPasskeyRedisRepositoryMockRecorder) TakeChallenge(ctx, challengeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeChallenge", reflect.TypeOf((*MockPasskeyRedisRepository)(nil).TakeChallenge), ctx, challengeID)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	reflect "reflect"
+)
+
+// MockPasskeyUseCase is a mock of PasskeyUseCase interface
+type MockPasskeyUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasskeyUseCaseMockRecorder
+}
+
+// MockPasskeyUseCaseMockRecorder is the mock recorder for MockPasskeyUseCase
+type MockPasskeyUseCaseMockRecorder struct {
+	mock *MockPasskeyUseCase
+}
+
+// NewMockPasskeyUseCase creates a new mock instance
+func NewMockPasskeyUseCase(ctrl *gomock.Controller) *MockPasskeyUseCase {
+	mock := &MockPasskeyUseCase{ctrl: ctrl}
+	mock.recorder = &MockPasskeyUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPasskeyUseCase) EXPECT() *MockPasskeyUseCaseMockRecorder {
+	return m.recorder
+}
+
+// BeginRegistration mocks base method
+func (m *MockPasskeyUseCase) BeginRegistration(ctx context.Context, user *models.User) (*models.PasskeyCeremony, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginRegistration", ctx, user)
+	ret0, _ := ret[0].(*models.PasskeyCeremony)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginRegistration indicates an expected call of BeginRegistration
+func (mr *MockPasskeyUseCaseMockRecorder) BeginRegistration(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginRegistration", reflect.TypeOf((*MockPasskeyUseCase)(nil).BeginRegistration), ctx, user)
+}
+
+// FinishRegistration mocks base method
+func (m *MockPasskeyUseCase) FinishRegistration(ctx context.Context, user *models.User, challengeID, name string, credential []byte) (*models.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRegistration", ctx, user, challengeID, name, credential)
+	ret0, _ := ret[0].(*models.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishRegistration indicates an expected call of FinishRegistration
+func (mr *MockPasskeyUseCaseMockRecorder) FinishRegistration(ctx, user, challengeID, name, credential interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRegistration", reflect.TypeOf((*MockPasskeyUseCase)(nil).FinishRegistration), ctx, user, challengeID, name, credential)
+}
+
+// BeginLogin mocks base method
+func (m *MockPasskeyUseCase) BeginLogin(ctx context.Context, user *models.User) (*models.PasskeyCeremony, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginLogin", ctx, user)
+	ret0, _ := ret[0].(*models.PasskeyCeremony)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginLogin indicates an expected call of BeginLogin
+func (mr *MockPasskeyUseCaseMockRecorder) BeginLogin(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginLogin", reflect.TypeOf((*MockPasskeyUseCase)(nil).BeginLogin), ctx, user)
+}
+
+// FinishLogin mocks base method
+func (m *MockPasskeyUseCase) FinishLogin(ctx context.Context, challengeID string, credential []byte) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishLogin", ctx, challengeID, credential)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishLogin indicates an expected call of FinishLogin
+func (mr *MockPasskeyUseCaseMockRecorder) FinishLogin(ctx, challengeID, credential interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishLogin", reflect.TypeOf((*MockPasskeyUseCase)(nil).FinishLogin), ctx, challengeID, credential)
+}
+
+// List mocks base method
+func (m *MockPasskeyUseCase) List(ctx context.Context, userID uuid.UUID) ([]*models.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, userID)
+	ret0, _ := ret[0].([]*models.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockPasskeyUseCaseMockRecorder) List(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPasskeyUseCase)(nil).List), ctx, userID)
+}
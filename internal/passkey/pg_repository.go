@@ -0,0 +1,17 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository.go -package mock
+package passkey
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Passkeys pg repository
+type PasskeyPGRepository interface {
+	Create(ctx context.Context, passkey *models.Passkey) (*models.Passkey, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Passkey, error)
+	UpdateSignCount(ctx context.Context, credentialID []byte, signCount uint32) error
+}
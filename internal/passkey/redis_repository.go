@@ -0,0 +1,14 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository.go -package mock
+package passkey
+
+import (
+	"context"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Passkey challenges redis repository
+type PasskeyRedisRepository interface {
+	CreateChallenge(ctx context.Context, challenge *models.PasskeyChallenge, expire int) (string, error)
+	TakeChallenge(ctx context.Context, challengeID string) (*models.PasskeyChallenge, error)
+}
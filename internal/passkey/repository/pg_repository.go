@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Passkeys repository
+type PasskeyRepository struct {
+	db *sqlx.DB
+}
+
+// Passkeys repository constructor
+func NewPasskeyPGRepository(db *sqlx.DB) *PasskeyRepository {
+	return &PasskeyRepository{db: db}
+}
+
+// Create new passkey
+func (r *PasskeyRepository) Create(ctx context.Context, passkey *models.Passkey) (*models.Passkey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "PasskeyRepository.Create")
+	defer span.Finish()
+
+	createdPasskey := &models.Passkey{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		createPasskeyQuery,
+		passkey.CredentialID,
+		passkey.UserID,
+		passkey.Name,
+		passkey.PublicKey,
+		passkey.AttestationType,
+		passkey.AAGUID,
+		passkey.SignCount,
+	).StructScan(createdPasskey); err != nil {
+		return nil, errors.Wrap(err, "Create.QueryRowxContext")
+	}
+
+	return createdPasskey, nil
+}
+
+// Find all passkeys of user
+func (r *PasskeyRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Passkey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "PasskeyRepository.FindByUserID")
+	defer span.Finish()
+
+	var passkeys []*models.Passkey
+	if err := r.db.SelectContext(ctx, &passkeys, findByUserIDQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "FindByUserID.SelectContext")
+	}
+
+	return passkeys, nil
+}
+
+// Store signature counter of passkey after successful login
+func (r *PasskeyRepository) UpdateSignCount(ctx context.Context, credentialID []byte, signCount uint32) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "PasskeyRepository.UpdateSignCount")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(ctx, updateSignCountQuery, credentialID, signCount); err != nil {
+		return errors.Wrap(err, "UpdateSignCount.ExecContext")
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+const (
+	challengePrefix = "passkey_challenges:"
+)
+
+// Passkey challenges redis repository
+type passkeyRedisRepo struct {
+	redisClient *redis.Client
+	basePrefix  string
+}
+
+// Passkey challenges redis repository constructor
+func NewPasskeyRedisRepo(redisClient *redis.Client) *passkeyRedisRepo {
+	return &passkeyRedisRepo{redisClient: redisClient, basePrefix: challengePrefix}
+}
+
+// Create challenge with expiration in seconds
+func (r *passkeyRedisRepo) CreateChallenge(ctx context.Context, challenge *models.PasskeyChallenge, expire int) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passkeyRedisRepo.CreateChallenge")
+	defer span.Finish()
+
+	challenge.ChallengeID = uuid.New().String()
+	challengeBytes, err := json.Marshal(challenge)
+	if err != nil {
+		return "", errors.WithMessage(err, "passkeyRedisRepo.CreateChallenge.json.Marshal")
+	}
+
+	if err := r.redisClient.Set(ctx, r.createKey(challenge.ChallengeID), challengeBytes, time.Second*time.Duration(expire)).Err(); err != nil {
+		return "", errors.Wrap(err, "passkeyRedisRepo.CreateChallenge.Set")
+	}
+
+	return challenge.ChallengeID, nil
+}
+
+// Get and delete challenge, so every challenge can be answered once. Returns redis.Nil if it does not exist or expired
+func (r *passkeyRedisRepo) TakeChallenge(ctx context.Context, challengeID string) (*models.PasskeyChallenge, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passkeyRedisRepo.TakeChallenge")
+	defer span.Finish()
+
+	challengeKey := r.createKey(challengeID)
+	pipe := r.redisClient.TxPipeline()
+	get := pipe.Get(ctx, challengeKey)
+	pipe.Del(ctx, challengeKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errors.Wrap(err, "passkeyRedisRepo.TakeChallenge.Exec")
+	}
+
+	challengeBytes, err := get.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "passkeyRedisRepo.TakeChallenge.Get")
+	}
+
+	challenge := &models.PasskeyChallenge{}
+	if err := json.Unmarshal(challengeBytes, challenge); err != nil {
+		return nil, errors.Wrap(err, "passkeyRedisRepo.TakeChallenge.json.Unmarshal")
+	}
+
+	return challenge, nil
+}
+
+func (r *passkeyRedisRepo) createKey(challengeID string) string {
+	return fmt.Sprintf("%s: %s", r.basePrefix, challengeID)
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+func SetupRedis() *passkeyRedisRepo {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	passkeyRedisRepository := NewPasskeyRedisRepo(client)
+	return passkeyRedisRepository
+}
+
+func TestPasskeyRedisRepo_TakeChallenge(t *testing.T) {
+	t.Parallel()
+
+	redisRepo := SetupRedis()
+
+	challenge := &models.PasskeyChallenge{
+		UserID:               uuid.New(),
+		Ceremony:             models.PasskeyCeremonyLogin,
+		Challenge:            "challenge",
+		AllowedCredentialIDs: [][]byte{[]byte("credential")},
+	}
+	challengeID, err := redisRepo.CreateChallenge(context.Background(), challenge, 10)
+	require.NoError(t, err)
+
+	takenChallenge, err := redisRepo.TakeChallenge(context.Background(), challengeID)
+	require.NoError(t, err)
+	require.Equal(t, challenge, takenChallenge)
+
+	_, err = redisRepo.TakeChallenge(context.Background(), challengeID)
+	require.True(t, errors.Is(err, redis.Nil))
+}
@@ -0,0 +1,11 @@
+package repository
+
+const (
+	createPasskeyQuery = `INSERT INTO user_passkeys (credential_id, user_id, name, public_key, attestation_type, aaguid, sign_count) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *`
+
+	findByUserIDQuery = `SELECT credential_id, user_id, name, public_key, attestation_type, aaguid, sign_count, created_at, last_used_at 
+		FROM user_passkeys WHERE user_id = $1 ORDER BY created_at`
+
+	updateSignCountQuery = `UPDATE user_passkeys SET sign_count = $2, last_used_at = NOW() WHERE credential_id = $1`
+)
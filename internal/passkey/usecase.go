@@ -0,0 +1,19 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase.go -package mock
+package passkey
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Passkey UseCase interface
+type PasskeyUseCase interface {
+	BeginRegistration(ctx context.Context, user *models.User) (*models.PasskeyCeremony, error)
+	FinishRegistration(ctx context.Context, user *models.User, challengeID string, name string, credential []byte) (*models.Passkey, error)
+	BeginLogin(ctx context.Context, user *models.User) (*models.PasskeyCeremony, error)
+	FinishLogin(ctx context.Context, challengeID string, credential []byte) (uuid.UUID, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*models.Passkey, error)
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/protocol/webauthncose"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Software authenticator with single ES256 credential, answers ceremonies like a browser with a platform authenticator
+type softAuthenticator struct {
+	t            *testing.T
+	origin       string
+	credentialID []byte
+	privateKey   *ecdsa.PrivateKey
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T, origin string) *softAuthenticator {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	return &softAuthenticator{t: t, origin: origin, credentialID: credentialID, privateKey: privateKey}
+}
+
+// Create credential for navigator.credentials.create options
+func (a *softAuthenticator) create(options []byte) []byte {
+	creation := &protocol.CredentialCreation{}
+	require.NoError(a.t, json.Unmarshal(options, creation))
+	a.userHandle = creation.Response.User.ID
+
+	publicKey, err := cbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1,
+		XCoord: padCoord(a.privateKey.X.Bytes()),
+		YCoord: padCoord(a.privateKey.Y.Bytes()),
+	})
+	require.NoError(a.t, err)
+
+	authData := a.authData(creation.Response.RelyingParty.ID, protocol.FlagAttestedCredentialData)
+	authData = append(authData, make([]byte, 16)...)
+	authData = append(authData, byte(len(a.credentialID)>>8), byte(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, publicKey...)
+
+	attestationObject, err := cbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	require.NoError(a.t, err)
+
+	return a.marshalCredential(map[string]interface{}{
+		"clientDataJSON":    a.clientData(protocol.CreateCeremony, creation.Response.Challenge),
+		"attestationObject": encode(attestationObject),
+	})
+}
+
+// Sign assertion for navigator.credentials.get options
+func (a *softAuthenticator) get(options []byte) []byte {
+	assertion := &protocol.CredentialAssertion{}
+	require.NoError(a.t, json.Unmarshal(options, assertion))
+
+	a.signCount++
+	authData := a.authData(assertion.Response.RelyingPartyID, 0)
+	clientData := a.clientData(protocol.AssertCeremony, assertion.Response.Challenge)
+	clientDataBytes, err := base64.RawURLEncoding.DecodeString(clientData)
+	require.NoError(a.t, err)
+
+	clientDataHash := sha256.Sum256(clientDataBytes)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.privateKey, digest[:])
+	require.NoError(a.t, err)
+
+	return a.marshalCredential(map[string]interface{}{
+		"clientDataJSON":    clientData,
+		"authenticatorData": encode(authData),
+		"signature":         encode(signature),
+		"userHandle":        encode(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) authData(rpID string, flags protocol.AuthenticatorFlags) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	authData := append(rpIDHash[:], byte(protocol.FlagUserPresent|protocol.FlagUserVerified|flags))
+	counter := make([]byte, 4)
+	binary.BigEndian.PutUint32(counter, a.signCount)
+	return append(authData, counter...)
+}
+
+func (a *softAuthenticator) clientData(ceremony protocol.CeremonyType, challenge protocol.Challenge) string {
+	clientData, err := json.Marshal(protocol.CollectedClientData{
+		Type:      ceremony,
+		Challenge: challenge.String(),
+		Origin:    a.origin,
+	})
+	require.NoError(a.t, err)
+	return encode(clientData)
+}
+
+func (a *softAuthenticator) marshalCredential(response map[string]interface{}) []byte {
+	credential, err := json.Marshal(map[string]interface{}{
+		"id":       encode(a.credentialID),
+		"rawId":    encode(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	require.NoError(a.t, err)
+	return credential
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func padCoord(b []byte) []byte {
+	padded := make([]byte, 32)
+	copy(padded[32-len(b):], b)
+	return padded
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/internal/passkey"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+)
+
+// Passkey UseCase
+type passkeyUseCase struct {
+	cfg         *config.Config
+	webAuthn    *webauthn.WebAuthn
+	passkeyRepo passkey.PasskeyPGRepository
+	redisRepo   passkey.PasskeyRedisRepository
+}
+
+// New Passkey UseCase, relying party is configured by WebAuthn config
+func NewPasskeyUseCase(
+	cfg *config.Config,
+	passkeyRepo passkey.PasskeyPGRepository,
+	redisRepo passkey.PasskeyRedisRepository,
+) (*passkeyUseCase, error) {
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: cfg.WebAuthn.RPDisplayName,
+		RPOrigin:      cfg.WebAuthn.RPOrigin,
+		Timeout:       cfg.WebAuthn.ChallengeExpire * 1000,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "webauthn.New")
+	}
+
+	return &passkeyUseCase{cfg: cfg, webAuthn: webAuthn, passkeyRepo: passkeyRepo, redisRepo: redisRepo}, nil
+}
+
+// Start passkey registration of user, already registered passkeys are excluded
+func (u *passkeyUseCase) BeginRegistration(ctx context.Context, user *models.User) (*models.PasskeyCeremony, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passkeyUseCase.BeginRegistration")
+	defer span.Finish()
+
+	passkeys, err := u.passkeyRepo.FindByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "passkeyRepo.FindByUserID")
+	}
+
+	webAuthnUser := newWebAuthnUser(user, passkeys)
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(passkeys))
+	for _, credential := range webAuthnUser.WebAuthnCredentials() {
+		exclusions = append(exclusions, protocol.CredentialDescriptor{
+			Type:         protocol.PublicKeyCredentialType,
+			CredentialID: credential.ID,
+		})
+	}
+
+	options, sessionData, err := u.webAuthn.BeginRegistration(webAuthnUser, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, errors.Wrap(err, "webAuthn.BeginRegistration")
+	}
+
+	return u.createCeremony(ctx, user.UserID, models.PasskeyCeremonyRegistration, sessionData, options)
+}
+
+// Verify attestation of new credential and store it as passkey of user
+func (u *passkeyUseCase) FinishRegistration(
+	ctx context.Context,
+	user *models.User,
+	challengeID string,
+	name string,
+	credential []byte,
+) (*models.Passkey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passkeyUseCase.FinishRegistration")
+	defer span.Finish()
+
+	challenge, err := u.takeChallenge(ctx, challengeID, models.PasskeyCeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.UserID != user.UserID {
+		return nil, errors.Wrap(grpc_errors.ErrInvalidPasskeyChallenge, "challenge of another user")
+	}
+
+	parsedResponse, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(credential))
+	if err != nil {
+		return nil, errors.Wrapf(grpc_errors.ErrInvalidPasskey, "ParseCredentialCreationResponseBody: %v", err)
+	}
+
+	webAuthnCredential, err := u.webAuthn.CreateCredential(newWebAuthnUser(user, nil), sessionData(challenge), parsedResponse)
+	if err != nil {
+		return nil, errors.Wrapf(grpc_errors.ErrInvalidPasskey, "webAuthn.CreateCredential: %v", err)
+	}
+
+	newPasskey := &models.Passkey{
+		CredentialID:    webAuthnCredential.ID,
+		UserID:          user.UserID,
+		Name:            name,
+		PublicKey:       webAuthnCredential.PublicKey,
+		AttestationType: webAuthnCredential.AttestationType,
+		AAGUID:          webAuthnCredential.Authenticator.AAGUID,
+		SignCount:       webAuthnCredential.Authenticator.SignCount,
+	}
+	newPasskey.PrepareCreate()
+
+	createdPasskey, err := u.passkeyRepo.Create(ctx, newPasskey)
+	if err != nil {
+		return nil, errors.Wrap(err, "passkeyRepo.Create")
+	}
+
+	return createdPasskey, nil
+}
+
+// Start passkey login of user
+func (u *passkeyUseCase) BeginLogin(ctx context.Context, user *models.User) (*models.PasskeyCeremony, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passkeyUseCase.BeginLogin")
+	defer span.Finish()
+
+	passkeys, err := u.passkeyRepo.FindByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "passkeyRepo.FindByUserID")
+	}
+	if len(passkeys) == 0 {
+		return nil, grpc_errors.ErrNoPasskeys
+	}
+
+	options, sessionData, err := u.webAuthn.BeginLogin(newWebAuthnUser(user, passkeys))
+	if err != nil {
+		return nil, errors.Wrap(err, "webAuthn.BeginLogin")
+	}
+
+	return u.createCeremony(ctx, user.UserID, models.PasskeyCeremonyLogin, sessionData, options)
+}
+
+// Verify assertion signed by one of user passkeys and returns the user
+func (u *passkeyUseCase) FinishLogin(ctx context.Context, challengeID string, credential []byte) (uuid.UUID, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passkeyUseCase.FinishLogin")
+	defer span.Finish()
+
+	challenge, err := u.takeChallenge(ctx, challengeID, models.PasskeyCeremonyLogin)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(credential))
+	if err != nil {
+		return uuid.Nil, errors.Wrapf(grpc_errors.ErrInvalidPasskey, "ParseCredentialRequestResponseBody: %v", err)
+	}
+
+	passkeys, err := u.passkeyRepo.FindByUserID(ctx, challenge.UserID)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "passkeyRepo.FindByUserID")
+	}
+
+	webAuthnUser := newWebAuthnUser(&models.User{UserID: challenge.UserID}, passkeys)
+	webAuthnCredential, err := u.webAuthn.ValidateLogin(webAuthnUser, sessionData(challenge), parsedResponse)
+	if err != nil {
+		return uuid.Nil, errors.Wrapf(grpc_errors.ErrInvalidPasskey, "webAuthn.ValidateLogin: %v", err)
+	}
+	if webAuthnCredential.Authenticator.CloneWarning {
+		return uuid.Nil, errors.Wrap(grpc_errors.ErrInvalidPasskey, "signature counter did not increase, authenticator may be cloned")
+	}
+
+	if err := u.passkeyRepo.UpdateSignCount(ctx, webAuthnCredential.ID, webAuthnCredential.Authenticator.SignCount); err != nil {
+		return uuid.Nil, errors.Wrap(err, "passkeyRepo.UpdateSignCount")
+	}
+
+	return challenge.UserID, nil
+}
+
+// List registered passkeys of user
+func (u *passkeyUseCase) List(ctx context.Context, userID uuid.UUID) ([]*models.Passkey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "passkeyUseCase.List")
+	defer span.Finish()
+
+	passkeys, err := u.passkeyRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "passkeyRepo.FindByUserID")
+	}
+
+	return passkeys, nil
+}
+
+func (u *passkeyUseCase) createCeremony(
+	ctx context.Context,
+	userID uuid.UUID,
+	ceremony string,
+	sessionData *webauthn.SessionData,
+	options interface{},
+) (*models.PasskeyCeremony, error) {
+	optionsBytes, err := json.Marshal(options)
+	if err != nil {
+		return nil, errors.Wrap(err, "json.Marshal")
+	}
+
+	challengeID, err := u.redisRepo.CreateChallenge(ctx, &models.PasskeyChallenge{
+		UserID:               userID,
+		Ceremony:             ceremony,
+		Challenge:            sessionData.Challenge,
+		AllowedCredentialIDs: sessionData.AllowedCredentialIDs,
+		UserVerification:     string(sessionData.UserVerification),
+	}, u.cfg.WebAuthn.ChallengeExpire)
+	if err != nil {
+		return nil, errors.Wrap(err, "redisRepo.CreateChallenge")
+	}
+
+	return &models.PasskeyCeremony{ChallengeID: challengeID, Options: optionsBytes}, nil
+}
+
+func (u *passkeyUseCase) takeChallenge(ctx context.Context, challengeID string, ceremony string) (*models.PasskeyChallenge, error) {
+	challenge, err := u.redisRepo.TakeChallenge(ctx, challengeID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.Wrap(grpc_errors.ErrInvalidPasskeyChallenge, "redisRepo.TakeChallenge")
+		}
+		return nil, err
+	}
+	if challenge.Ceremony != ceremony {
+		return nil, errors.Wrapf(grpc_errors.ErrInvalidPasskeyChallenge, "unexpected ceremony: %s", challenge.Ceremony)
+	}
+
+	return challenge, nil
+}
+
+func sessionData(challenge *models.PasskeyChallenge) webauthn.SessionData {
+	return webauthn.SessionData{
+		Challenge:            challenge.Challenge,
+		UserID:               userHandle(challenge.UserID),
+		AllowedCredentialIDs: challenge.AllowedCredentialIDs,
+		UserVerification:     protocol.UserVerificationRequirement(challenge.UserVerification),
+	}
+}
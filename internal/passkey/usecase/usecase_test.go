@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/internal/passkey/mock"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+)
+
+const testOrigin = "https://auth.example.com"
+
+func setupPasskeyUseCase(t *testing.T, ctrl *gomock.Controller) (*passkeyUseCase, *mock.MockPasskeyPGRepository, *mock.MockPasskeyRedisRepository) {
+	cfg := &config.Config{WebAuthn: config.WebAuthn{
+		RPID:            "auth.example.com",
+		RPDisplayName:   "Auth",
+		RPOrigin:        testOrigin,
+		ChallengeExpire: 60,
+	}}
+	passkeyPGRepository := mock.NewMockPasskeyPGRepository(ctrl)
+	passkeyRedisRepository := mock.NewMockPasskeyRedisRepository(ctrl)
+
+	passkeyUC, err := NewPasskeyUseCase(cfg, passkeyPGRepository, passkeyRedisRepository)
+	require.NoError(t, err)
+
+	return passkeyUC, passkeyPGRepository, passkeyRedisRepository
+}
+
+// Challenges are kept in memory instead of redis
+func storeChallenges(redisRepository *mock.MockPasskeyRedisRepository) {
+	challenges := make(map[string]*models.PasskeyChallenge)
+	redisRepository.EXPECT().CreateChallenge(gomock.Any(), gomock.Any(), 60).DoAndReturn(
+		func(_ context.Context, challenge *models.PasskeyChallenge, _ int) (string, error) {
+			challenge.ChallengeID = uuid.New().String()
+			challenges[challenge.ChallengeID] = challenge
+			return challenge.ChallengeID, nil
+		},
+	).AnyTimes()
+	redisRepository.EXPECT().TakeChallenge(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, challengeID string) (*models.PasskeyChallenge, error) {
+			challenge, ok := challenges[challengeID]
+			if !ok {
+				return nil, redis.Nil
+			}
+			delete(challenges, challengeID)
+			return challenge, nil
+		},
+	).AnyTimes()
+}
+
+func TestPasskeyUseCase_RegistrationAndLogin(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	passkeyUC, passkeyPGRepository, passkeyRedisRepository := setupPasskeyUseCase(t, ctrl)
+	storeChallenges(passkeyRedisRepository)
+
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com", FirstName: "FirstName", LastName: "LastName"}
+	authenticator := newSoftAuthenticator(t, testOrigin)
+
+	passkeyPGRepository.EXPECT().FindByUserID(gomock.Any(), user.UserID).Return(nil, nil)
+	ceremony, err := passkeyUC.BeginRegistration(context.Background(), user)
+	require.NoError(t, err)
+	require.NotEqual(t, "", ceremony.ChallengeID)
+
+	var registered *models.Passkey
+	passkeyPGRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, passkey *models.Passkey) (*models.Passkey, error) {
+			registered = passkey
+			return passkey, nil
+		},
+	)
+	createdPasskey, err := passkeyUC.FinishRegistration(context.Background(), user, ceremony.ChallengeID, " ", authenticator.create(ceremony.Options))
+	require.NoError(t, err)
+	require.Equal(t, authenticator.credentialID, createdPasskey.CredentialID)
+	require.Equal(t, user.UserID, createdPasskey.UserID)
+	require.Equal(t, "Passkey", createdPasskey.Name)
+	require.Equal(t, "none", createdPasskey.AttestationType)
+
+	t.Run("Challenge is single use", func(t *testing.T) {
+		_, err := passkeyUC.FinishRegistration(context.Background(), user, ceremony.ChallengeID, "", authenticator.create(ceremony.Options))
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidPasskeyChallenge))
+	})
+
+	passkeyPGRepository.EXPECT().FindByUserID(gomock.Any(), user.UserID).Return([]*models.Passkey{registered}, nil).AnyTimes()
+
+	t.Run("Login", func(t *testing.T) {
+		ceremony, err := passkeyUC.BeginLogin(context.Background(), user)
+		require.NoError(t, err)
+
+		passkeyPGRepository.EXPECT().UpdateSignCount(gomock.Any(), authenticator.credentialID, uint32(1)).Return(nil)
+
+		userID, err := passkeyUC.FinishLogin(context.Background(), ceremony.ChallengeID, authenticator.get(ceremony.Options))
+		require.NoError(t, err)
+		require.Equal(t, user.UserID, userID)
+	})
+
+	t.Run("Login with registration challenge", func(t *testing.T) {
+		ceremony, err := passkeyUC.BeginRegistration(context.Background(), user)
+		require.NoError(t, err)
+
+		_, err = passkeyUC.FinishLogin(context.Background(), ceremony.ChallengeID, authenticator.get(ceremony.Options))
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidPasskeyChallenge))
+	})
+
+	t.Run("Unknown authenticator", func(t *testing.T) {
+		ceremony, err := passkeyUC.BeginLogin(context.Background(), user)
+		require.NoError(t, err)
+
+		other := newSoftAuthenticator(t, testOrigin)
+		_, err = passkeyUC.FinishLogin(context.Background(), ceremony.ChallengeID, other.get(ceremony.Options))
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidPasskey))
+	})
+
+	t.Run("Wrong origin", func(t *testing.T) {
+		ceremony, err := passkeyUC.BeginLogin(context.Background(), user)
+		require.NoError(t, err)
+
+		authenticator.origin = "https://evil.example.com"
+		defer func() { authenticator.origin = testOrigin }()
+		_, err = passkeyUC.FinishLogin(context.Background(), ceremony.ChallengeID, authenticator.get(ceremony.Options))
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidPasskey))
+	})
+}
+
+func TestPasskeyUseCase_BeginLogin(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	passkeyUC, passkeyPGRepository, _ := setupPasskeyUseCase(t, ctrl)
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+
+	passkeyPGRepository.EXPECT().FindByUserID(gomock.Any(), user.UserID).Return(nil, nil)
+
+	ceremony, err := passkeyUC.BeginLogin(context.Background(), user)
+	require.Nil(t, ceremony)
+	require.True(t, errors.Is(err, grpc_errors.ErrNoPasskeys))
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// User with passkeys as WebAuthn relying party user
+type webAuthnUser struct {
+	user        *models.User
+	credentials []webauthn.Credential
+}
+
+func newWebAuthnUser(user *models.User, passkeys []*models.Passkey) *webAuthnUser {
+	credentials := make([]webauthn.Credential, 0, len(passkeys))
+	for _, p := range passkeys {
+		credentials = append(credentials, webauthn.Credential{
+			ID:              p.CredentialID,
+			PublicKey:       p.PublicKey,
+			AttestationType: p.AttestationType,
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    p.AAGUID,
+				SignCount: p.SignCount,
+			},
+		})
+	}
+
+	return &webAuthnUser{user: user, credentials: credentials}
+}
+
+// User handle is uuid bytes, it does not reveal email of user to authenticator
+func (w *webAuthnUser) WebAuthnID() []byte {
+	return userHandle(w.user.UserID)
+}
+
+func (w *webAuthnUser) WebAuthnName() string {
+	return w.user.Email
+}
+
+func (w *webAuthnUser) WebAuthnDisplayName() string {
+	return w.user.FirstName + " " + w.user.LastName
+}
+
+func (w *webAuthnUser) WebAuthnIcon() string {
+	return w.user.GetAvatar()
+}
+
+func (w *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return w.credentials
+}
+
+func userHandle(userID uuid.UUID) []byte {
+	handle := userID
+	return handle[:]
+}
@@ -0,0 +1,152 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pg_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	reflect "reflect"
+)
+
+// MockRolePGRepository is a mock of RolePGRepository interface
+type MockRolePGRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRolePGRepositoryMockRecorder
+}
+
+// MockRolePGRepositoryMockRecorder is the mock recorder for MockRolePGRepository
+type MockRolePGRepositoryMockRecorder struct {
+	mock *MockRolePGRepository
+}
+
+// NewMockRolePGRepository creates a new mock instance
+func NewMockRolePGRepository(ctrl *gomock.Controller) *MockRolePGRepository {
+	mock := &MockRolePGRepository{ctrl: ctrl}
+	mock.recorder = &MockRolePGRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRolePGRepository) EXPECT() *MockRolePGRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockRolePGRepository) Create(ctx context.Context, role *models.Role) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, role)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockRolePGRepositoryMockRecorder) Create(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRolePGRepository)(nil).Create), ctx, role)
+}
+
+// FindByName mocks base method
+func (m *MockRolePGRepository) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByName", ctx, name)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByName indicates an expected call of FindByName
+func (mr *MockRolePGRepositoryMockRecorder) FindByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockRolePGRepository)(nil).FindByName), ctx, name)
+}
+
+// GrantPermission mocks base method
+func (m *MockRolePGRepository) GrantPermission(ctx context.Context, role, permission string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantPermission", ctx, role, permission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantPermission indicates an expected call of GrantPermission
+func (mr *MockRolePGRepositoryMockRecorder) GrantPermission(ctx, role, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantPermission", reflect.TypeOf((*MockRolePGRepository)(nil).GrantPermission), ctx, role, permission)
+}
+
+// RevokePermission mocks base method
+func (m *MockRolePGRepository) RevokePermission(ctx context.Context, role, permission string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokePermission", ctx, role, permission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokePermission indicates an expected call of RevokePermission
+func (mr *MockRolePGRepositoryMockRecorder) RevokePermission(ctx, role, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokePermission", reflect.TypeOf((*MockRolePGRepository)(nil).RevokePermission), ctx, role, permission)
+}
+
+// AssignRole mocks base method
+func (m *MockRolePGRepository) AssignRole(ctx context.Context, userID uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignRole indicates an expected call of AssignRole
+func (mr *MockRolePGRepositoryMockRecorder) AssignRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockRolePGRepository)(nil).AssignRole), ctx, userID, role)
+}
+
+// UnassignRole mocks base method
+func (m *MockRolePGRepository) UnassignRole(ctx context.Context, userID uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnassignRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnassignRole indicates an expected call of UnassignRole
+func (mr *MockRolePGRepositoryMockRecorder) UnassignRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignRole", reflect.TypeOf((*MockRolePGRepository)(nil).UnassignRole), ctx, userID, role)
+}
+
+// FindUserIDsByRole mocks base method
+func (m *MockRolePGRepository) FindUserIDsByRole(ctx context.Context, role string) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserIDsByRole", ctx, role)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserIDsByRole indicates an expected call of FindUserIDsByRole
+func (mr *MockRolePGRepositoryMockRecorder) FindUserIDsByRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserIDsByRole", reflect.TypeOf((*MockRolePGRepository)(nil).FindUserIDsByRole), ctx, role)
+}
+
+// FindUserPermissions mocks base method
+func (m *MockRolePGRepository) FindUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserPermissions", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserPermissions indicates an expected call of FindUserPermissions
+func (mr *MockRolePGRepositoryMockRecorder) FindUserPermissions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserPermissions", reflect.TypeOf((*MockRolePGRepository)(nil).FindUserPermissions), ctx, userID)
+}
@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: redis_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockRoleRedisRepository is a mock of RoleRedisRepository interface
+type MockRoleRedisRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleRedisRepositoryMockRecorder
+}
+
+// MockRoleRedisRepositoryMockRecorder is the mock recorder for MockRoleRedisRepository
+type MockRoleRedisRepositoryMockRecorder struct {
+	mock *MockRoleRedisRepository
+}
+
+// NewMockRoleRedisRepository creates a new mock instance
+func NewMockRoleRedisRepository(ctrl *gomock.Controller) *MockRoleRedisRepository {
+	mock := &MockRoleRedisRepository{ctrl: ctrl}
+	mock.recorder = &MockRoleRedisRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRoleRedisRepository) EXPECT() *MockRoleRedisRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetDecisionCtx mocks base method
+func (m *MockRoleRedisRepository) GetDecisionCtx(ctx context.Context, key, permission string) (*models.PermissionDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDecisionCtx", ctx, key, permission)
+	ret0, _ := ret[0].(*models.PermissionDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDecisionCtx indicates an expected call of GetDecisionCtx
+func (mr *MockRoleRedisRepositoryMockRecorder) GetDecisionCtx(ctx, key, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDecisionCtx", reflect.TypeOf((*MockRoleRedisRepository)(nil).GetDecisionCtx), ctx, key, permission)
+}
+
+// SetDecisionCtx mocks base method
+func (m *MockRoleRedisRepository) SetDecisionCtx(ctx context.Context, key string, seconds int, decision *models.PermissionDecision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDecisionCtx", ctx, key, seconds, decision)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDecisionCtx indicates an expected call of SetDecisionCtx
+func (mr *MockRoleRedisRepositoryMockRecorder) SetDecisionCtx(ctx, key, seconds, decision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDecisionCtx", reflect.TypeOf((*MockRoleRedisRepository)(nil).SetDecisionCtx), ctx, key, seconds, decision)
+}
+
+// DeleteDecisionsCtx mocks base method
+func (m *MockRoleRedisRepository) DeleteDecisionsCtx(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDecisionsCtx", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDecisionsCtx indicates an expected call of DeleteDecisionsCtx
+func (mr *MockRoleRedisRepositoryMockRecorder) DeleteDecisionsCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDecisionsCtx", reflect.TypeOf((*MockRoleRedisRepository)(nil).DeleteDecisionsCtx), ctx, key)
+}
@@ -0,0 +1,166 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	models "github.com/AleksK1NG/auth-microservice/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	reflect "reflect"
+)
+
+// MockRoleUseCase is a mock of RoleUseCase interface
+type MockRoleUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleUseCaseMockRecorder
+}
+
+// MockRoleUseCaseMockRecorder is the mock recorder for MockRoleUseCase
+type MockRoleUseCaseMockRecorder struct {
+	mock *MockRoleUseCase
+}
+
+// NewMockRoleUseCase creates a new mock instance
+func NewMockRoleUseCase(ctrl *gomock.Controller) *MockRoleUseCase {
+	mock := &MockRoleUseCase{ctrl: ctrl}
+	mock.recorder = &MockRoleUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRoleUseCase) EXPECT() *MockRoleUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CreateRole mocks base method
+func (m *MockRoleUseCase) CreateRole(ctx context.Context, role *models.Role) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", ctx, role)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRole indicates an expected call of CreateRole
+func (mr *MockRoleUseCaseMockRecorder) CreateRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockRoleUseCase)(nil).CreateRole), ctx, role)
+}
+
+// FindByName mocks base method
+func (m *MockRoleUseCase) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByName", ctx, name)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByName indicates an expected call of FindByName
+func (mr *MockRoleUseCaseMockRecorder) FindByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockRoleUseCase)(nil).FindByName), ctx, name)
+}
+
+// GrantPermission mocks base method
+func (m *MockRoleUseCase) GrantPermission(ctx context.Context, role, permission string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantPermission", ctx, role, permission)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantPermission indicates an expected call of GrantPermission
+func (mr *MockRoleUseCaseMockRecorder) GrantPermission(ctx, role, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantPermission", reflect.TypeOf((*MockRoleUseCase)(nil).GrantPermission), ctx, role, permission)
+}
+
+// RevokePermission mocks base method
+func (m *MockRoleUseCase) RevokePermission(ctx context.Context, role, permission string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokePermission", ctx, role, permission)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokePermission indicates an expected call of RevokePermission
+func (mr *MockRoleUseCaseMockRecorder) RevokePermission(ctx, role, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokePermission", reflect.TypeOf((*MockRoleUseCase)(nil).RevokePermission), ctx, role, permission)
+}
+
+// AssignRole mocks base method
+func (m *MockRoleUseCase) AssignRole(ctx context.Context, userID uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignRole indicates an expected call of AssignRole
+func (mr *MockRoleUseCaseMockRecorder) AssignRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockRoleUseCase)(nil).AssignRole), ctx, userID, role)
+}
+
+// UnassignRole mocks base method
+func (m *MockRoleUseCase) UnassignRole(ctx context.Context, userID uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnassignRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnassignRole indicates an expected call of UnassignRole
+func (mr *MockRoleUseCaseMockRecorder) UnassignRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignRole", reflect.TypeOf((*MockRoleUseCase)(nil).UnassignRole), ctx, userID, role)
+}
+
+// CheckPermission mocks base method
+func (m *MockRoleUseCase) CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (*models.PermissionDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPermission", ctx, userID, permission)
+	ret0, _ := ret[0].(*models.PermissionDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckPermission indicates an expected call of CheckPermission
+func (mr *MockRoleUseCaseMockRecorder) CheckPermission(ctx, userID, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPermission", reflect.TypeOf((*MockRoleUseCase)(nil).CheckPermission), ctx, userID, permission)
+}
+
+// CheckPermissions mocks base method
+func (m *MockRoleUseCase) CheckPermissions(ctx context.Context, userID uuid.UUID, permissions []string) ([]*models.PermissionDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPermissions", ctx, userID, permissions)
+	ret0, _ := ret[0].([]*models.PermissionDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckPermissions indicates an expected call of CheckPermissions
+func (mr *MockRoleUseCaseMockRecorder) CheckPermissions(ctx, userID, permissions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPermissions", reflect.TypeOf((*MockRoleUseCase)(nil).CheckPermissions), ctx, userID, permissions)
+}
+
+// InvalidateUser mocks base method
+func (m *MockRoleUseCase) InvalidateUser(ctx context.Context, userID uuid.UUID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateUser", ctx, userID)
+}
+
+// InvalidateUser indicates an expected call of InvalidateUser
+func (mr *MockRoleUseCaseMockRecorder) InvalidateUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateUser", reflect.TypeOf((*MockRoleUseCase)(nil).InvalidateUser), ctx, userID)
+}
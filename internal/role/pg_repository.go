@@ -0,0 +1,22 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository.go -package mock
+package role
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Role pg repository
+type RolePGRepository interface {
+	Create(ctx context.Context, role *models.Role) (*models.Role, error)
+	FindByName(ctx context.Context, name string) (*models.Role, error)
+	GrantPermission(ctx context.Context, role string, permission string) error
+	RevokePermission(ctx context.Context, role string, permission string) error
+	AssignRole(ctx context.Context, userID uuid.UUID, role string) error
+	UnassignRole(ctx context.Context, userID uuid.UUID, role string) error
+	FindUserIDsByRole(ctx context.Context, role string) ([]uuid.UUID, error)
+	FindUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
+}
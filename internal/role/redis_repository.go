@@ -0,0 +1,15 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository.go -package mock
+package role
+
+import (
+	"context"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Permission decisions Redis repository interface, decisions are cached per user
+type RoleRedisRepository interface {
+	GetDecisionCtx(ctx context.Context, key string, permission string) (*models.PermissionDecision, error)
+	SetDecisionCtx(ctx context.Context, key string, seconds int, decision *models.PermissionDecision) error
+	DeleteDecisionsCtx(ctx context.Context, key string) error
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Role repository
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+// Role repository constructor
+func NewRolePGRepository(db *sqlx.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// Create new role with its permissions, unknown permissions are created
+func (r *RoleRepository) Create(ctx context.Context, role *models.Role) (*models.Role, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleRepository.Create")
+	defer span.Finish()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Create.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	createdRole := &models.Role{}
+	if err := tx.QueryRowxContext(ctx, createRoleQuery, role.Name, role.Description).StructScan(createdRole); err != nil {
+		return nil, errors.Wrap(err, "Create.QueryRowxContext")
+	}
+
+	for _, permission := range role.Permissions {
+		if err := grantPermission(ctx, tx, role.Name, permission); err != nil {
+			return nil, errors.Wrap(err, "Create.grantPermission")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "Create.Commit")
+	}
+	createdRole.Permissions = role.Permissions
+
+	return createdRole, nil
+}
+
+// Find role with its permissions by name
+func (r *RoleRepository) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleRepository.FindByName")
+	defer span.Finish()
+
+	role := &models.Role{}
+	if err := r.db.GetContext(ctx, role, findRoleByNameQuery, name); err != nil {
+		return nil, errors.Wrap(err, "FindByName.GetContext")
+	}
+
+	role.Permissions = make([]string, 0)
+	if err := r.db.SelectContext(ctx, &role.Permissions, findRolePermissionsQuery, name); err != nil {
+		return nil, errors.Wrap(err, "FindByName.SelectContext")
+	}
+
+	return role, nil
+}
+
+// Grant permission to role, unknown permission is created
+func (r *RoleRepository) GrantPermission(ctx context.Context, role string, permission string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleRepository.GrantPermission")
+	defer span.Finish()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "GrantPermission.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if err := grantPermission(ctx, tx, role, permission); err != nil {
+		return errors.Wrap(err, "GrantPermission.grantPermission")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "GrantPermission.Commit")
+	}
+
+	return nil
+}
+
+// Revoke permission from role
+func (r *RoleRepository) RevokePermission(ctx context.Context, role string, permission string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleRepository.RevokePermission")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(ctx, revokePermissionQuery, role, permission); err != nil {
+		return errors.Wrap(err, "RevokePermission.ExecContext")
+	}
+
+	return nil
+}
+
+// Assign role to user
+func (r *RoleRepository) AssignRole(ctx context.Context, userID uuid.UUID, role string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleRepository.AssignRole")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(ctx, assignRoleQuery, userID, role); err != nil {
+		return errors.Wrap(err, "AssignRole.ExecContext")
+	}
+
+	return nil
+}
+
+// Unassign role from user
+func (r *RoleRepository) UnassignRole(ctx context.Context, userID uuid.UUID, role string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleRepository.UnassignRole")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(ctx, unassignRoleQuery, userID, role); err != nil {
+		return errors.Wrap(err, "UnassignRole.ExecContext")
+	}
+
+	return nil
+}
+
+// Find ids of users having role
+func (r *RoleRepository) FindUserIDsByRole(ctx context.Context, role string) ([]uuid.UUID, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleRepository.FindUserIDsByRole")
+	defer span.Finish()
+
+	userIDs := make([]uuid.UUID, 0)
+	if err := r.db.SelectContext(ctx, &userIDs, findUserIDsByRoleQuery, role); err != nil {
+		return nil, errors.Wrap(err, "FindUserIDsByRole.SelectContext")
+	}
+
+	return userIDs, nil
+}
+
+// Find permissions granted to user by its roles
+func (r *RoleRepository) FindUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleRepository.FindUserPermissions")
+	defer span.Finish()
+
+	permissions := make([]string, 0)
+	if err := r.db.SelectContext(ctx, &permissions, findUserPermissionsQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "FindUserPermissions.SelectContext")
+	}
+
+	return permissions, nil
+}
+
+func grantPermission(ctx context.Context, tx *sqlx.Tx, role string, permission string) error {
+	if _, err := tx.ExecContext(ctx, createPermissionQuery, permission); err != nil {
+		return errors.Wrap(err, "createPermissionQuery")
+	}
+	if _, err := tx.ExecContext(ctx, grantPermissionQuery, role, permission); err != nil {
+		return errors.Wrap(err, "grantPermissionQuery")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+func TestRoleRepository_Create(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	rolePGRepository := NewRolePGRepository(sqlxDB)
+
+	mockRole := &models.Role{
+		Name:        "editor",
+		Description: "Editor",
+		Permissions: []string{models.PermissionUsersRead},
+	}
+
+	rows := sqlmock.NewRows([]string{"name", "description", "created_at"}).AddRow(mockRole.Name, mockRole.Description, time.Now())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(createRoleQuery).WithArgs(mockRole.Name, mockRole.Description).WillReturnRows(rows)
+	mock.ExpectExec(createPermissionQuery).WithArgs(models.PermissionUsersRead).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(grantPermissionQuery).WithArgs(mockRole.Name, models.PermissionUsersRead).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	createdRole, err := rolePGRepository.Create(context.Background(), mockRole)
+	require.NoError(t, err)
+	require.NotNil(t, createdRole)
+	require.Equal(t, mockRole.Name, createdRole.Name)
+	require.Equal(t, mockRole.Permissions, createdRole.Permissions)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_FindByName(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	rolePGRepository := NewRolePGRepository(sqlxDB)
+
+	rows := sqlmock.NewRows([]string{"name", "description", "created_at"}).AddRow(models.RoleAdmin, "Administrator", time.Now())
+	permissionRows := sqlmock.NewRows([]string{"permission"}).
+		AddRow(models.PermissionKeysRotate).
+		AddRow(models.PermissionRolesManage)
+
+	mock.ExpectQuery(findRoleByNameQuery).WithArgs(models.RoleAdmin).WillReturnRows(rows)
+	mock.ExpectQuery(findRolePermissionsQuery).WithArgs(models.RoleAdmin).WillReturnRows(permissionRows)
+
+	foundRole, err := rolePGRepository.FindByName(context.Background(), models.RoleAdmin)
+	require.NoError(t, err)
+	require.Equal(t, models.RoleAdmin, foundRole.Name)
+	require.Equal(t, []string{models.PermissionKeysRotate, models.PermissionRolesManage}, foundRole.Permissions)
+}
+
+func TestRoleRepository_GrantPermission(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	rolePGRepository := NewRolePGRepository(sqlxDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(createPermissionQuery).WithArgs("reports:read").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(grantPermissionQuery).WithArgs(models.RoleUser, "reports:read").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = rolePGRepository.GrantPermission(context.Background(), models.RoleUser, "reports:read")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_FindUserIDsByRole(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	rolePGRepository := NewRolePGRepository(sqlxDB)
+
+	userID := uuid.New()
+	mock.ExpectQuery(findUserIDsByRoleQuery).WithArgs(models.RoleAdmin).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+
+	userIDs, err := rolePGRepository.FindUserIDsByRole(context.Background(), models.RoleAdmin)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{userID}, userIDs)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+)
+
+// Permission decisions redis repository, decisions of user are stored in one hash by permission
+type roleRedisRepo struct {
+	redisClient *redis.Client
+	basePrefix  string
+	logger      logger.Logger
+}
+
+// Permission decisions redis repository constructor
+func NewRoleRedisRepo(redisClient *redis.Client, logger logger.Logger) *roleRedisRepo {
+	return &roleRedisRepo{redisClient: redisClient, basePrefix: "permission_decisions:", logger: logger}
+}
+
+// Get cached decision of user permission
+func (r *roleRedisRepo) GetDecisionCtx(ctx context.Context, key string, permission string) (*models.PermissionDecision, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "roleRedisRepo.GetDecisionCtx")
+	defer span.Finish()
+
+	decisionBytes, err := r.redisClient.HGet(ctx, r.createKey(key), permission).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &models.PermissionDecision{}
+	if err := json.Unmarshal(decisionBytes, decision); err != nil {
+		return nil, err
+	}
+
+	return decision, nil
+}
+
+// Cache decision of user permission, duration in seconds is applied to all decisions of user
+func (r *roleRedisRepo) SetDecisionCtx(ctx context.Context, key string, seconds int, decision *models.PermissionDecision) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "roleRedisRepo.SetDecisionCtx")
+	defer span.Finish()
+
+	decisionBytes, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.HSet(ctx, r.createKey(key), decision.Permission, decisionBytes)
+	pipe.Expire(ctx, r.createKey(key), time.Second*time.Duration(seconds))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Delete all cached decisions of user
+func (r *roleRedisRepo) DeleteDecisionsCtx(ctx context.Context, key string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "roleRedisRepo.DeleteDecisionsCtx")
+	defer span.Finish()
+
+	return r.redisClient.Del(ctx, r.createKey(key)).Err()
+}
+
+func (r *roleRedisRepo) createKey(value string) string {
+	return fmt.Sprintf("%s: %s", r.basePrefix, value)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+func SetupRedis() *roleRedisRepo {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	roleRedisRepository := NewRoleRedisRepo(client, nil)
+	return roleRedisRepository
+}
+
+func TestRoleRedisRepo_Decisions(t *testing.T) {
+	t.Parallel()
+
+	redisRepo := SetupRedis()
+
+	t.Run("SetDecisionCtx", func(t *testing.T) {
+		userID := uuid.New().String()
+		decision := &models.PermissionDecision{Permission: models.PermissionUsersRead, Allowed: true, Reason: "granted by users:read"}
+
+		err := redisRepo.SetDecisionCtx(context.Background(), userID, 10, decision)
+		require.NoError(t, err)
+
+		cachedDecision, err := redisRepo.GetDecisionCtx(context.Background(), userID, models.PermissionUsersRead)
+		require.NoError(t, err)
+		require.Equal(t, decision, cachedDecision)
+
+		_, err = redisRepo.GetDecisionCtx(context.Background(), userID, models.PermissionRolesManage)
+		require.Equal(t, redis.Nil, err)
+	})
+
+	t.Run("DeleteDecisionsCtx", func(t *testing.T) {
+		userID := uuid.New().String()
+		decision := &models.PermissionDecision{Permission: models.PermissionUsersRead, Reason: "no role grants users:read"}
+
+		err := redisRepo.SetDecisionCtx(context.Background(), userID, 10, decision)
+		require.NoError(t, err)
+
+		err = redisRepo.DeleteDecisionsCtx(context.Background(), userID)
+		require.NoError(t, err)
+
+		_, err = redisRepo.GetDecisionCtx(context.Background(), userID, models.PermissionUsersRead)
+		require.Equal(t, redis.Nil, err)
+	})
+}
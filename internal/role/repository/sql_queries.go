@@ -0,0 +1,26 @@
+package repository
+
+const (
+	createRoleQuery = `INSERT INTO roles (name, description) VALUES ($1, $2) RETURNING name, description, created_at`
+
+	findRoleByNameQuery = `SELECT name, description, created_at FROM roles WHERE name = $1`
+
+	findRolePermissionsQuery = `SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission`
+
+	createPermissionQuery = `INSERT INTO permissions (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`
+
+	grantPermissionQuery = `INSERT INTO role_permissions (role, permission) VALUES ($1, $2) ON CONFLICT (role, permission) DO NOTHING`
+
+	revokePermissionQuery = `DELETE FROM role_permissions WHERE role = $1 AND permission = $2`
+
+	assignRoleQuery = `INSERT INTO user_roles (user_id, role) VALUES ($1, $2) ON CONFLICT (user_id, role) DO NOTHING`
+
+	unassignRoleQuery = `DELETE FROM user_roles WHERE user_id = $1 AND role = $2`
+
+	findUserIDsByRoleQuery = `SELECT user_id FROM user_roles WHERE role = $1`
+
+	findUserPermissionsQuery = `SELECT DISTINCT rp.permission FROM role_permissions rp 
+		JOIN user_roles ur ON ur.role = rp.role 
+		JOIN users u ON u.user_id = ur.user_id AND u.deleted_at IS NULL 
+		WHERE ur.user_id = $1 ORDER BY rp.permission`
+)
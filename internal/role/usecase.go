@@ -0,0 +1,23 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase.go -package mock
+package role
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+)
+
+// Role UseCase interface
+type RoleUseCase interface {
+	CreateRole(ctx context.Context, role *models.Role) (*models.Role, error)
+	FindByName(ctx context.Context, name string) (*models.Role, error)
+	GrantPermission(ctx context.Context, role string, permission string) (*models.Role, error)
+	RevokePermission(ctx context.Context, role string, permission string) (*models.Role, error)
+	AssignRole(ctx context.Context, userID uuid.UUID, role string) error
+	UnassignRole(ctx context.Context, userID uuid.UUID, role string) error
+	CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (*models.PermissionDecision, error)
+	CheckPermissions(ctx context.Context, userID uuid.UUID, permissions []string) ([]*models.PermissionDecision, error)
+	InvalidateUser(ctx context.Context, userID uuid.UUID)
+}
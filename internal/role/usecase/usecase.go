@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/internal/role"
+	"github.com/AleksK1NG/auth-microservice/internal/user"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+)
+
+const (
+	permissionDecisionCacheDuration = 600
+)
+
+// Role UseCase
+type roleUseCase struct {
+	logger        logger.Logger
+	rolePgRepo    role.RolePGRepository
+	redisRepo     role.RoleRedisRepository
+	userRedisRepo user.UserRedisRepository
+}
+
+// New Role UseCase, cached users and permission decisions are invalidated when their roles or permissions change
+func NewRoleUseCase(
+	logger logger.Logger,
+	rolePgRepo role.RolePGRepository,
+	redisRepo role.RoleRedisRepository,
+	userRedisRepo user.UserRedisRepository,
+) *roleUseCase {
+	return &roleUseCase{logger: logger, rolePgRepo: rolePgRepo, redisRepo: redisRepo, userRedisRepo: userRedisRepo}
+}
+
+// Create new role
+func (u *roleUseCase) CreateRole(ctx context.Context, role *models.Role) (*models.Role, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleUseCase.CreateRole")
+	defer span.Finish()
+
+	existsRole, err := u.rolePgRepo.FindByName(ctx, role.Name)
+	if existsRole != nil || err == nil {
+		return nil, grpc_errors.ErrRoleExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "rolePgRepo.FindByName")
+	}
+
+	return u.rolePgRepo.Create(ctx, role)
+}
+
+// Find role with its permissions by name
+func (u *roleUseCase) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleUseCase.FindByName")
+	defer span.Finish()
+
+	foundRole, err := u.rolePgRepo.FindByName(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "rolePgRepo.FindByName")
+	}
+
+	return foundRole, nil
+}
+
+// Grant permission to role and returns updated role
+func (u *roleUseCase) GrantPermission(ctx context.Context, roleName string, permission string) (*models.Role, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleUseCase.GrantPermission")
+	defer span.Finish()
+
+	if _, err := u.rolePgRepo.FindByName(ctx, roleName); err != nil {
+		return nil, errors.Wrap(err, "rolePgRepo.FindByName")
+	}
+
+	if err := u.rolePgRepo.GrantPermission(ctx, roleName, permission); err != nil {
+		return nil, errors.Wrap(err, "rolePgRepo.GrantPermission")
+	}
+	u.invalidateRoleUsers(ctx, roleName)
+
+	return u.FindByName(ctx, roleName)
+}
+
+// Revoke permission from role and returns updated role
+func (u *roleUseCase) RevokePermission(ctx context.Context, roleName string, permission string) (*models.Role, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleUseCase.RevokePermission")
+	defer span.Finish()
+
+	if _, err := u.rolePgRepo.FindByName(ctx, roleName); err != nil {
+		return nil, errors.Wrap(err, "rolePgRepo.FindByName")
+	}
+
+	if err := u.rolePgRepo.RevokePermission(ctx, roleName, permission); err != nil {
+		return nil, errors.Wrap(err, "rolePgRepo.RevokePermission")
+	}
+	u.invalidateRoleUsers(ctx, roleName)
+
+	return u.FindByName(ctx, roleName)
+}
+
+// Assign role to user
+func (u *roleUseCase) AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleUseCase.AssignRole")
+	defer span.Finish()
+
+	if _, err := u.rolePgRepo.FindByName(ctx, roleName); err != nil {
+		return errors.Wrap(err, "rolePgRepo.FindByName")
+	}
+
+	if err := u.rolePgRepo.AssignRole(ctx, userID, roleName); err != nil {
+		return errors.Wrap(err, "rolePgRepo.AssignRole")
+	}
+	u.invalidateUser(ctx, userID)
+
+	return nil
+}
+
+// Unassign role from user
+func (u *roleUseCase) UnassignRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleUseCase.UnassignRole")
+	defer span.Finish()
+
+	if err := u.rolePgRepo.UnassignRole(ctx, userID, roleName); err != nil {
+		return errors.Wrap(err, "rolePgRepo.UnassignRole")
+	}
+	u.invalidateUser(ctx, userID)
+
+	return nil
+}
+
+// Check if user is granted permission by its roles
+func (u *roleUseCase) CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (*models.PermissionDecision, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleUseCase.CheckPermission")
+	defer span.Finish()
+
+	decisions, err := u.CheckPermissions(ctx, userID, []string{permission})
+	if err != nil {
+		return nil, err
+	}
+
+	return decisions[0], nil
+}
+
+// Check if user is granted permissions by its roles, decisions are returned in order of permissions.
+// Cached decisions are used, user permissions are loaded once if any decision is missing.
+func (u *roleUseCase) CheckPermissions(ctx context.Context, userID uuid.UUID, permissions []string) ([]*models.PermissionDecision, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleUseCase.CheckPermissions")
+	defer span.Finish()
+
+	decisions := make([]*models.PermissionDecision, len(permissions))
+	var (
+		granted []string
+		loaded  bool
+	)
+	for i, permission := range permissions {
+		cachedDecision, err := u.redisRepo.GetDecisionCtx(ctx, userID.String(), permission)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			u.logger.Errorf("redisRepo.GetDecisionCtx: %v", err)
+		}
+		if cachedDecision != nil {
+			decisions[i] = cachedDecision
+			continue
+		}
+
+		if !loaded {
+			granted, err = u.rolePgRepo.FindUserPermissions(ctx, userID)
+			if err != nil {
+				return nil, errors.Wrap(err, "rolePgRepo.FindUserPermissions")
+			}
+			loaded = true
+		}
+
+		decisions[i] = decide(granted, permission)
+		if err := u.redisRepo.SetDecisionCtx(ctx, userID.String(), permissionDecisionCacheDuration, decisions[i]); err != nil {
+			u.logger.Errorf("redisRepo.SetDecisionCtx: %v", err)
+		}
+	}
+
+	return decisions, nil
+}
+
+// Drop cached user and permission decisions, e.g. after the user was deleted or restored
+func (u *roleUseCase) InvalidateUser(ctx context.Context, userID uuid.UUID) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RoleUseCase.InvalidateUser")
+	defer span.Finish()
+
+	u.invalidateUser(ctx, userID)
+}
+
+func decide(granted []string, permission string) *models.PermissionDecision {
+	match, ok := models.MatchPermission(granted, permission)
+	if !ok {
+		return &models.PermissionDecision{Permission: permission, Reason: fmt.Sprintf("no role grants %s", permission)}
+	}
+	return &models.PermissionDecision{Permission: permission, Allowed: true, Reason: fmt.Sprintf("granted by %s", match)}
+}
+
+func (u *roleUseCase) invalidateRoleUsers(ctx context.Context, roleName string) {
+	userIDs, err := u.rolePgRepo.FindUserIDsByRole(ctx, roleName)
+	if err != nil {
+		u.logger.Errorf("rolePgRepo.FindUserIDsByRole: %v", err)
+		return
+	}
+	for _, userID := range userIDs {
+		u.invalidateUser(ctx, userID)
+	}
+}
+
+func (u *roleUseCase) invalidateUser(ctx context.Context, userID uuid.UUID) {
+	if err := u.userRedisRepo.DeleteUserCtx(ctx, userID.String()); err != nil {
+		u.logger.Errorf("userRedisRepo.DeleteUserCtx: %v", err)
+	}
+	if err := u.redisRepo.DeleteDecisionsCtx(ctx, userID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteDecisionsCtx: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/internal/role/mock"
+	userMock "github.com/AleksK1NG/auth-microservice/internal/user/mock"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+)
+
+func TestRoleUseCase_CreateRole(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rolePGRepository := mock.NewMockRolePGRepository(ctrl)
+	roleRedisRepository := mock.NewMockRoleRedisRepository(ctrl)
+	userRedisRepository := userMock.NewMockUserRedisRepository(ctrl)
+	roleUC := NewRoleUseCase(logger.NewAPILogger(nil), rolePGRepository, roleRedisRepository, userRedisRepository)
+
+	t.Run("Create", func(t *testing.T) {
+		role := &models.Role{Name: "editor", Permissions: []string{models.PermissionUsersRead}}
+
+		rolePGRepository.EXPECT().FindByName(gomock.Any(), role.Name).Return(nil, sql.ErrNoRows)
+		rolePGRepository.EXPECT().Create(gomock.Any(), role).Return(role, nil)
+
+		createdRole, err := roleUC.CreateRole(context.Background(), role)
+		require.NoError(t, err)
+		require.Equal(t, role.Name, createdRole.Name)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		role := &models.Role{Name: models.RoleAdmin}
+
+		rolePGRepository.EXPECT().FindByName(gomock.Any(), role.Name).Return(role, nil)
+
+		createdRole, err := roleUC.CreateRole(context.Background(), role)
+		require.Nil(t, createdRole)
+		require.True(t, errors.Is(err, grpc_errors.ErrRoleExists))
+	})
+}
+
+func TestRoleUseCase_GrantPermission(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rolePGRepository := mock.NewMockRolePGRepository(ctrl)
+	roleRedisRepository := mock.NewMockRoleRedisRepository(ctrl)
+	userRedisRepository := userMock.NewMockUserRedisRepository(ctrl)
+	roleUC := NewRoleUseCase(logger.NewAPILogger(nil), rolePGRepository, roleRedisRepository, userRedisRepository)
+
+	role := &models.Role{Name: "editor"}
+	updatedRole := &models.Role{Name: "editor", Permissions: []string{models.PermissionUsersRead}}
+	userIDs := []uuid.UUID{uuid.New(), uuid.New()}
+
+	gomock.InOrder(
+		rolePGRepository.EXPECT().FindByName(gomock.Any(), role.Name).Return(role, nil),
+		rolePGRepository.EXPECT().GrantPermission(gomock.Any(), role.Name, models.PermissionUsersRead).Return(nil),
+		rolePGRepository.EXPECT().FindUserIDsByRole(gomock.Any(), role.Name).Return(userIDs, nil),
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), userIDs[0].String()).Return(nil),
+		roleRedisRepository.EXPECT().DeleteDecisionsCtx(gomock.Any(), userIDs[0].String()).Return(nil),
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), userIDs[1].String()).Return(nil),
+		roleRedisRepository.EXPECT().DeleteDecisionsCtx(gomock.Any(), userIDs[1].String()).Return(nil),
+		rolePGRepository.EXPECT().FindByName(gomock.Any(), role.Name).Return(updatedRole, nil),
+	)
+
+	grantedRole, err := roleUC.GrantPermission(context.Background(), role.Name, models.PermissionUsersRead)
+	require.NoError(t, err)
+	require.Equal(t, updatedRole.Permissions, grantedRole.Permissions)
+}
+
+func TestRoleUseCase_AssignRole(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rolePGRepository := mock.NewMockRolePGRepository(ctrl)
+	roleRedisRepository := mock.NewMockRoleRedisRepository(ctrl)
+	userRedisRepository := userMock.NewMockUserRedisRepository(ctrl)
+	roleUC := NewRoleUseCase(logger.NewAPILogger(nil), rolePGRepository, roleRedisRepository, userRedisRepository)
+
+	t.Run("Assign", func(t *testing.T) {
+		userID := uuid.New()
+
+		rolePGRepository.EXPECT().FindByName(gomock.Any(), models.RoleAdmin).Return(&models.Role{Name: models.RoleAdmin}, nil)
+		rolePGRepository.EXPECT().AssignRole(gomock.Any(), userID, models.RoleAdmin).Return(nil)
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), userID.String()).Return(nil)
+		roleRedisRepository.EXPECT().DeleteDecisionsCtx(gomock.Any(), userID.String()).Return(nil)
+
+		err := roleUC.AssignRole(context.Background(), userID, models.RoleAdmin)
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		userID := uuid.New()
+
+		rolePGRepository.EXPECT().FindByName(gomock.Any(), "unknown").Return(nil, sql.ErrNoRows)
+
+		err := roleUC.AssignRole(context.Background(), userID, "unknown")
+		require.True(t, errors.Is(err, sql.ErrNoRows))
+	})
+}
+
+func TestRoleUseCase_CheckPermissions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rolePGRepository := mock.NewMockRolePGRepository(ctrl)
+	roleRedisRepository := mock.NewMockRoleRedisRepository(ctrl)
+	userRedisRepository := userMock.NewMockUserRedisRepository(ctrl)
+	roleUC := NewRoleUseCase(logger.NewAPILogger(nil), rolePGRepository, roleRedisRepository, userRedisRepository)
+
+	t.Run("Cached", func(t *testing.T) {
+		userID := uuid.New()
+		cached := &models.PermissionDecision{Permission: models.PermissionUsersRead, Allowed: true, Reason: "granted by users:read"}
+
+		roleRedisRepository.EXPECT().GetDecisionCtx(gomock.Any(), userID.String(), models.PermissionUsersRead).Return(cached, nil)
+
+		decision, err := roleUC.CheckPermission(context.Background(), userID, models.PermissionUsersRead)
+		require.NoError(t, err)
+		require.Equal(t, cached, decision)
+	})
+
+	t.Run("Evaluated", func(t *testing.T) {
+		userID := uuid.New()
+		permissions := []string{"users:read", "users:delete", "reports:read"}
+
+		for _, permission := range permissions {
+			roleRedisRepository.EXPECT().GetDecisionCtx(gomock.Any(), userID.String(), permission).Return(nil, redis.Nil)
+			roleRedisRepository.EXPECT().SetDecisionCtx(gomock.Any(), userID.String(), permissionDecisionCacheDuration, gomock.Any()).Return(nil)
+		}
+		rolePGRepository.EXPECT().FindUserPermissions(gomock.Any(), userID).Return([]string{"users:*"}, nil).Times(1)
+
+		decisions, err := roleUC.CheckPermissions(context.Background(), userID, permissions)
+		require.NoError(t, err)
+		require.Len(t, decisions, 3)
+		require.True(t, decisions[0].Allowed)
+		require.Equal(t, "granted by users:*", decisions[0].Reason)
+		require.True(t, decisions[1].Allowed)
+		require.False(t, decisions[2].Allowed)
+		require.Equal(t, "no role grants reports:read", decisions[2].Reason)
+	})
+}
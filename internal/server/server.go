@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"os"
@@ -13,19 +14,32 @@ import (
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/jmoiron/sqlx"
+	"github.com/labstack/echo/v4"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/AleksK1NG/auth-microservice/config"
+	auditRepository "github.com/AleksK1NG/auth-microservice/internal/audit/repository"
+	auditUseCase "github.com/AleksK1NG/auth-microservice/internal/audit/usecase"
 	"github.com/AleksK1NG/auth-microservice/internal/interceptors"
+	mfaRepository "github.com/AleksK1NG/auth-microservice/internal/mfa/repository"
+	mfaUseCase "github.com/AleksK1NG/auth-microservice/internal/mfa/usecase"
+	passkeyRepository "github.com/AleksK1NG/auth-microservice/internal/passkey/repository"
+	passkeyUseCase "github.com/AleksK1NG/auth-microservice/internal/passkey/usecase"
+	roleRepository "github.com/AleksK1NG/auth-microservice/internal/role/repository"
+	roleUseCase "github.com/AleksK1NG/auth-microservice/internal/role/usecase"
 	sessRepository "github.com/AleksK1NG/auth-microservice/internal/session/repository"
 	sessUseCase "github.com/AleksK1NG/auth-microservice/internal/session/usecase"
+	"github.com/AleksK1NG/auth-microservice/internal/user"
 	authServerGRPC "github.com/AleksK1NG/auth-microservice/internal/user/delivery/grpc/service"
 	userRepository "github.com/AleksK1NG/auth-microservice/internal/user/repository"
 	userUseCase "github.com/AleksK1NG/auth-microservice/internal/user/usecase"
+	"github.com/AleksK1NG/auth-microservice/pkg/encryption"
+	"github.com/AleksK1NG/auth-microservice/pkg/jwt"
 	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+	"github.com/AleksK1NG/auth-microservice/pkg/mailer"
 	"github.com/AleksK1NG/auth-microservice/pkg/metric"
 	userService "github.com/AleksK1NG/auth-microservice/proto"
 )
@@ -55,12 +69,38 @@ func (s *Server) Run() error {
 		s.cfg.Metrics.ServiceName,
 	)
 
-	im := interceptors.NewInterceptorManager(s.logger, s.cfg, metrics)
 	userRepo := userRepository.NewUserPGRepository(s.db)
 	sessRepo := sessRepository.NewSessionRepository(s.redisClient, s.cfg)
 	userRedisRepo := userRepository.NewUserRedisRepo(s.redisClient, s.logger)
-	userUC := userUseCase.NewUserUseCase(s.logger, userRepo, userRedisRepo)
+	userMailer, err := mailer.NewMailer(s.cfg, s.logger)
+	if err != nil {
+		return err
+	}
+	userUC := userUseCase.NewUserUseCase(s.logger, s.cfg, userRepo, userRedisRepo, userMailer)
 	sessUC := sessUseCase.NewSessionUseCase(sessRepo, s.cfg)
+	roleRepo := roleRepository.NewRolePGRepository(s.db)
+	roleRedisRepo := roleRepository.NewRoleRedisRepo(s.redisClient, s.logger)
+	roleUC := roleUseCase.NewRoleUseCase(s.logger, roleRepo, roleRedisRepo, userRedisRepo)
+	mfaEncryptor, err := encryption.NewAESEncryptor(s.cfg.MFA.EncryptionKey)
+	if err != nil {
+		return err
+	}
+	mfaRepo := mfaRepository.NewMfaPGRepository(s.db)
+	mfaRedisRepo := mfaRepository.NewMfaRedisRepo(s.redisClient)
+	mfaUC := mfaUseCase.NewMfaUseCase(s.cfg, mfaRepo, mfaRedisRepo, mfaEncryptor)
+	passkeyRepo := passkeyRepository.NewPasskeyPGRepository(s.db)
+	passkeyRedisRepo := passkeyRepository.NewPasskeyRedisRepo(s.redisClient)
+	passkeyUC, err := passkeyUseCase.NewPasskeyUseCase(s.cfg, passkeyRepo, passkeyRedisRepo)
+	if err != nil {
+		return err
+	}
+	auditRepo := auditRepository.NewAuditPGRepository(s.db)
+	auditUC := auditUseCase.NewAuditUseCase(auditRepo)
+	jwtManager, err := jwt.NewJwtManager(s.logger, s.cfg)
+	if err != nil {
+		return err
+	}
+	im := interceptors.NewInterceptorManager(s.logger, s.cfg, metrics, sessUC, userUC, jwtManager)
 
 	l, err := net.Listen("tcp", s.cfg.Server.Port)
 	if err != nil {
@@ -79,6 +119,15 @@ func (s *Server) Run() error {
 			grpc_ctxtags.UnaryServerInterceptor(),
 			grpc_prometheus.UnaryServerInterceptor,
 			grpcrecovery.UnaryServerInterceptor(),
+			im.AuthUnary,
+			im.RBACUnary,
+		),
+		grpc.ChainStreamInterceptor(
+			grpc_ctxtags.StreamServerInterceptor(),
+			grpc_prometheus.StreamServerInterceptor,
+			grpcrecovery.StreamServerInterceptor(),
+			im.AuthStream,
+			im.RBACStream,
 		),
 	)
 
@@ -86,12 +135,27 @@ func (s *Server) Run() error {
 		reflection.Register(server)
 	}
 
-	authGRPCServer := authServerGRPC.NewAuthServerGRPC(s.logger, s.cfg, userUC, sessUC)
+	authGRPCServer := authServerGRPC.NewAuthServerGRPC(s.logger, s.cfg, userUC, sessUC, roleUC, mfaUC, passkeyUC, auditUC, jwtManager)
 	userService.RegisterUserServiceServer(server, authGRPCServer)
 
 	grpc_prometheus.Register(server)
 	http.Handle("/metrics", promhttp.Handler())
 
+	go func() {
+		router := echo.New()
+		router.GET("/.well-known/jwks.json", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, jwtManager.JWKS())
+		})
+		s.logger.Infof("JWKS is available on: %v", s.cfg.Jwt.JwksAddr)
+		if err := router.Start(s.cfg.Jwt.JwksAddr); err != nil {
+			s.logger.Errorf("JWKS server: %v", err)
+		}
+	}()
+
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	go s.runPurgeDeletedUsers(jobsCtx, userUC)
+
 	go func() {
 		s.logger.Infof("Server is listening on port: %v", s.cfg.Server.Port)
 		if err := server.Serve(l); err != nil {
@@ -108,3 +172,29 @@ func (s *Server) Run() error {
 
 	return nil
 }
+
+// Periodically purge deleted users whose grace period ended
+func (s *Server) runPurgeDeletedUsers(ctx context.Context, userUC user.UserUseCase) {
+	if s.cfg.AccountDeletion.PurgeInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Second * time.Duration(s.cfg.AccountDeletion.PurgeInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := userUC.PurgeDeleted(ctx)
+			if err != nil {
+				s.logger.Errorf("userUC.PurgeDeleted: %v", err)
+				continue
+			}
+			if purged > 0 {
+				s.logger.Infof("Purged deleted users: %d", purged)
+			}
+		}
+	}
+}
@@ -8,7 +8,9 @@ import (
 	context "context"
 	models "github.com/AleksK1NG/auth-microservice/internal/models"
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	reflect "reflect"
+	time "time"
 )
 
 // MockSessRepository is a mock of SessRepository interface
@@ -49,6 +51,22 @@ func (mr *MockSessRepositoryMockRecorder) CreateSession(ctx, session, expire int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessRepository)(nil).CreateSession), ctx, session, expire)
 }
 
+// CreateSessionWithLimit mocks base method
+func (m *MockSessRepository) CreateSessionWithLimit(ctx context.Context, session *models.Session, expire int, limit *models.SessionLimit) (string, []*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSessionWithLimit", ctx, session, expire, limit)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]*models.Session)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateSessionWithLimit indicates an expected call of CreateSessionWithLimit
+func (mr *MockSessRepositoryMockRecorder) CreateSessionWithLimit(ctx, session, expire, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSessionWithLimit", reflect.TypeOf((*MockSessRepository)(nil).CreateSessionWithLimit), ctx, session, expire, limit)
+}
+
 // GetSessionByID mocks base method
 func (m *MockSessRepository) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +82,35 @@ func (mr *MockSessRepositoryMockRecorder) GetSessionByID(ctx, sessionID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByID", reflect.TypeOf((*MockSessRepository)(nil).GetSessionByID), ctx, sessionID)
 }
 
+// GetSessionsByUserID mocks base method
+func (m *MockSessRepository) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsByUserID indicates an expected call of GetSessionsByUserID
+func (mr *MockSessRepositoryMockRecorder) GetSessionsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByUserID", reflect.TypeOf((*MockSessRepository)(nil).GetSessionsByUserID), ctx, userID)
+}
+
+// TouchSession mocks base method
+func (m *MockSessRepository) TouchSession(ctx context.Context, session *models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession
+func (mr *MockSessRepositoryMockRecorder) TouchSession(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockSessRepository)(nil).TouchSession), ctx, session)
+}
+
 // DeleteByID mocks base method
 func (m *MockSessRepository) DeleteByID(ctx context.Context, sessionID string) error {
 	m.ctrl.T.Helper()
@@ -77,3 +124,104 @@ func (mr *MockSessRepositoryMockRecorder) DeleteByID(ctx, sessionID interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockSessRepository)(nil).DeleteByID), ctx, sessionID)
 }
+
+// DeleteByUserID mocks base method
+func (m *MockSessRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID
+func (mr *MockSessRepositoryMockRecorder) DeleteByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockSessRepository)(nil).DeleteByUserID), ctx, userID)
+}
+
+// DeleteOtherSessions mocks base method
+func (m *MockSessRepository) DeleteOtherSessions(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOtherSessions", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOtherSessions indicates an expected call of DeleteOtherSessions
+func (mr *MockSessRepositoryMockRecorder) DeleteOtherSessions(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOtherSessions", reflect.TypeOf((*MockSessRepository)(nil).DeleteOtherSessions), ctx, userID, sessionID)
+}
+
+// CreateRefreshToken mocks base method
+func (m *MockSessRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken, expire int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, token, expire)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken
+func (mr *MockSessRepositoryMockRecorder) CreateRefreshToken(ctx, token, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockSessRepository)(nil).CreateRefreshToken), ctx, token, expire)
+}
+
+// UseRefreshToken mocks base method
+func (m *MockSessRepository) UseRefreshToken(ctx context.Context, refreshToken string) (*models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(*models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRefreshToken indicates an expected call of UseRefreshToken
+func (mr *MockSessRepositoryMockRecorder) UseRefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRefreshToken", reflect.TypeOf((*MockSessRepository)(nil).UseRefreshToken), ctx, refreshToken)
+}
+
+// DeleteRefreshFamily mocks base method
+func (m *MockSessRepository) DeleteRefreshFamily(ctx context.Context, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRefreshFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRefreshFamily indicates an expected call of DeleteRefreshFamily
+func (mr *MockSessRepositoryMockRecorder) DeleteRefreshFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshFamily", reflect.TypeOf((*MockSessRepository)(nil).DeleteRefreshFamily), ctx, familyID)
+}
+
+// SetSessionsValidAfter mocks base method
+func (m *MockSessRepository) SetSessionsValidAfter(ctx context.Context, userID uuid.UUID, validAfter time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSessionsValidAfter", ctx, userID, validAfter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSessionsValidAfter indicates an expected call of SetSessionsValidAfter
+func (mr *MockSessRepositoryMockRecorder) SetSessionsValidAfter(ctx, userID, validAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSessionsValidAfter", reflect.TypeOf((*MockSessRepository)(nil).SetSessionsValidAfter), ctx, userID, validAfter)
+}
+
+// GetSessionsValidAfter mocks base method
+func (m *MockSessRepository) GetSessionsValidAfter(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsValidAfter", ctx, userID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsValidAfter indicates an expected call of GetSessionsValidAfter
+func (mr *MockSessRepositoryMockRecorder) GetSessionsValidAfter(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsValidAfter", reflect.TypeOf((*MockSessRepository)(nil).GetSessionsValidAfter), ctx, userID)
+}
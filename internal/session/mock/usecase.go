@@ -8,6 +8,7 @@ import (
 	context "context"
 	models "github.com/AleksK1NG/auth-microservice/internal/models"
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	reflect "reflect"
 )
 
@@ -35,18 +36,18 @@ func (m *MockSessionUseCase) EXPECT() *MockSessionUseCaseMockRecorder {
 }
 
 // CreateSession mocks base method
-func (m *MockSessionUseCase) CreateSession(ctx context.Context, session *models.Session, expire int) (string, error) {
+func (m *MockSessionUseCase) CreateSession(ctx context.Context, session *models.Session, roles []string, expire int) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", ctx, session, expire)
+	ret := m.ctrl.Call(m, "CreateSession", ctx, session, roles, expire)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateSession indicates an expected call of CreateSession
-func (mr *MockSessionUseCaseMockRecorder) CreateSession(ctx, session, expire interface{}) *gomock.Call {
+func (mr *MockSessionUseCaseMockRecorder) CreateSession(ctx, session, roles, expire interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionUseCase)(nil).CreateSession), ctx, session, expire)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionUseCase)(nil).CreateSession), ctx, session, roles, expire)
 }
 
 // GetSessionByID mocks base method
@@ -64,6 +65,35 @@ func (mr *MockSessionUseCaseMockRecorder) GetSessionByID(ctx, sessionID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByID", reflect.TypeOf((*MockSessionUseCase)(nil).GetSessionByID), ctx, sessionID)
 }
 
+// GetSessionsByUserID mocks base method
+func (m *MockSessionUseCase) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsByUserID indicates an expected call of GetSessionsByUserID
+func (mr *MockSessionUseCaseMockRecorder) GetSessionsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByUserID", reflect.TypeOf((*MockSessionUseCase)(nil).GetSessionsByUserID), ctx, userID)
+}
+
+// Touch mocks base method
+func (m *MockSessionUseCase) Touch(ctx context.Context, session *models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch
+func (mr *MockSessionUseCaseMockRecorder) Touch(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockSessionUseCase)(nil).Touch), ctx, session)
+}
+
 // DeleteByID mocks base method
 func (m *MockSessionUseCase) DeleteByID(ctx context.Context, sessionID string) error {
 	m.ctrl.T.Helper()
@@ -77,3 +107,90 @@ func (mr *MockSessionUseCaseMockRecorder) DeleteByID(ctx, sessionID interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockSessionUseCase)(nil).DeleteByID), ctx, sessionID)
 }
+
+// DeleteByUserID mocks base method
+func (m *MockSessionUseCase) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID
+func (mr *MockSessionUseCaseMockRecorder) DeleteByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockSessionUseCase)(nil).DeleteByUserID), ctx, userID)
+}
+
+// DeleteByPublicID mocks base method
+func (m *MockSessionUseCase) DeleteByPublicID(ctx context.Context, userID uuid.UUID, publicID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByPublicID", ctx, userID, publicID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByPublicID indicates an expected call of DeleteByPublicID
+func (mr *MockSessionUseCaseMockRecorder) DeleteByPublicID(ctx, userID, publicID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByPublicID", reflect.TypeOf((*MockSessionUseCase)(nil).DeleteByPublicID), ctx, userID, publicID)
+}
+
+// DeleteOtherSessions mocks base method
+func (m *MockSessionUseCase) DeleteOtherSessions(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOtherSessions", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOtherSessions indicates an expected call of DeleteOtherSessions
+func (mr *MockSessionUseCaseMockRecorder) DeleteOtherSessions(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOtherSessions", reflect.TypeOf((*MockSessionUseCase)(nil).DeleteOtherSessions), ctx, userID, sessionID)
+}
+
+// RevokeUserSessions mocks base method
+func (m *MockSessionUseCase) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions
+func (mr *MockSessionUseCaseMockRecorder) RevokeUserSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockSessionUseCase)(nil).RevokeUserSessions), ctx, userID)
+}
+
+// CreateRefreshToken mocks base method
+func (m *MockSessionUseCase) CreateRefreshToken(ctx context.Context, session *models.Session) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, session)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken
+func (mr *MockSessionUseCaseMockRecorder) CreateRefreshToken(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockSessionUseCase)(nil).CreateRefreshToken), ctx, session)
+}
+
+// RefreshSession mocks base method
+func (m *MockSessionUseCase) RefreshSession(ctx context.Context, refreshToken string, client *models.ClientInfo) (*models.Session, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSession", ctx, refreshToken, client)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RefreshSession indicates an expected call of RefreshSession
+func (mr *MockSessionUseCaseMockRecorder) RefreshSession(ctx, refreshToken, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockSessionUseCase)(nil).RefreshSession), ctx, refreshToken, client)
+}
@@ -3,6 +3,9 @@ package session
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 )
@@ -10,6 +13,16 @@ import (
 // Session repository
 type SessRepository interface {
 	CreateSession(ctx context.Context, session *models.Session, expire int) (string, error)
+	CreateSessionWithLimit(ctx context.Context, session *models.Session, expire int, limit *models.SessionLimit) (string, []*models.Session, error)
 	GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error)
+	GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error)
+	TouchSession(ctx context.Context, session *models.Session) error
 	DeleteByID(ctx context.Context, sessionID string) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteOtherSessions(ctx context.Context, userID uuid.UUID, sessionID string) error
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken, expire int) (string, error)
+	UseRefreshToken(ctx context.Context, refreshToken string) (*models.RefreshToken, error)
+	DeleteRefreshFamily(ctx context.Context, familyID string) error
+	SetSessionsValidAfter(ctx context.Context, userID uuid.UUID, validAfter time.Time) error
+	GetSessionsValidAfter(ctx context.Context, userID uuid.UUID) (time.Time, error)
 }
@@ -2,8 +2,14 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -14,12 +20,117 @@ import (
 	"github.com/AleksK1NG/auth-microservice/config"
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 	"github.com/AleksK1NG/auth-microservice/internal/session"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
 )
 
 const (
-	basePrefix = "sessions:"
+	basePrefix                = "sessions:"
+	userSessionIndexPrefix    = "user_session_index:"
+	refreshTokenPrefix        = "refresh_tokens:"
+	refreshFamilyPrefix       = "refresh_families:"
+	userRefreshFamiliesPrefix = "user_refresh_families:"
+	sessionsValidAfterPrefix  = "sessions_valid_after:"
+	refreshTokenBytes         = 32
+	// Attempts to create limited session while concurrent logins keep changing user session index
+	createSessionWithLimitAttempts = 5
 )
 
+// Marks refresh token as used and returns its data with the number of times it was presented
+var useRefreshTokenScript = redis.NewScript(`
+local data = redis.call('HGET', KEYS[1], 'data')
+if not data then
+	return false
+end
+local used = redis.call('HINCRBY', KEYS[1], 'used', 1)
+return {data, used}
+`)
+
+// Extends key ttl to the given milliseconds, ttl which is already longer is kept,
+// key without ttl gets the given one
+var extendTTLScript = redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < tonumber(ARGV[1]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return ttl
+`)
+
+// Patches last seen and expires at of stored session, so concurrent updates of other fields are kept.
+// Missing or expired session is not recreated and expiration is only extended, together with its index entry.
+var touchSessionScript = redis.NewScript(`
+local data = redis.call('GET', KEYS[1])
+if not data then
+	return 0
+end
+local ttl = redis.call('PTTL', KEYS[1])
+local session = cjson.decode(data)
+session['last_seen_at'] = ARGV[1]
+if ttl >= 0 and ttl < tonumber(ARGV[3]) then
+	session['expires_at'] = ARGV[2]
+	ttl = tonumber(ARGV[3])
+	redis.call('ZADD', KEYS[2], 'XX', ARGV[4], ARGV[5])
+	if redis.call('PTTL', KEYS[2]) < ttl then
+		redis.call('PEXPIRE', KEYS[2], ARGV[3])
+	end
+end
+if ttl > 0 then
+	redis.call('SET', KEYS[1], cjson.encode(session), 'PX', string.format('%d', ttl))
+end
+return 1
+`)
+
+// Creates session and adds it to user session index if index has less than limit live sessions,
+// with eviction enabled the oldest sessions are deleted to make room and their data is returned.
+// Every touched key is declared, so caller passes indexed sessions ordered by creation and script returns 0
+// without changes if index members differ from them, caller reads the index again and retries.
+// KEYS: index, session, indexed sessions. ARGV: limit, evict, session id, expires at, session data, ttl ms, indexed ids
+var createSessionWithLimitScript = redis.NewScript(`
+local indexedCount = #KEYS - 2
+local members = redis.call('ZRANGE', KEYS[1], 0, -1)
+if #members ~= indexedCount then
+	return 0
+end
+local expected = {}
+for i = 1, indexedCount do
+	expected[ARGV[6 + i]] = true
+end
+for _, id in ipairs(members) do
+	if not expected[id] then
+		return 0
+	end
+end
+
+local live = {}
+for i = 1, indexedCount do
+	if redis.call('EXISTS', KEYS[2 + i]) == 1 then
+		table.insert(live, i)
+	else
+		redis.call('ZREM', KEYS[1], ARGV[6 + i])
+	end
+end
+
+local evicted = {}
+local excess = #live - tonumber(ARGV[1]) + 1
+if excess > 0 then
+	if ARGV[2] ~= '1' then
+		return false
+	end
+	for j = 1, excess do
+		local i = live[j]
+		table.insert(evicted, redis.call('GET', KEYS[2 + i]))
+		redis.call('DEL', KEYS[2 + i])
+		redis.call('ZREM', KEYS[1], ARGV[6 + i])
+	end
+end
+
+redis.call('SET', KEYS[2], ARGV[5], 'PX', ARGV[6])
+redis.call('ZADD', KEYS[1], ARGV[4], ARGV[3])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[6]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[6])
+end
+return evicted
+`)
+
 // Session repository
 type sessionRepo struct {
 	redisClient *redis.Client
@@ -37,19 +148,87 @@ func (s *sessionRepo) CreateSession(ctx context.Context, sess *models.Session, e
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.CreateSession")
 	defer span.Finish()
 
-	sess.SessionID = uuid.New().String()
-	sessionKey := s.createKey(sess.SessionID)
-
-	sessBytes, err := json.Marshal(&sess)
+	sessBytes, err := s.initSession(sess, expire)
 	if err != nil {
-		return "", errors.WithMessage(err, "sessionRepo.CreateSession.json.Marshal")
+		return "", errors.WithMessage(err, "sessionRepo.CreateSession.initSession")
 	}
-	if err = s.redisClient.Set(ctx, sessionKey, sessBytes, time.Second*time.Duration(expire)).Err(); err != nil {
+
+	// index members are scored by expiration, so expired sessions can be pruned without reading them,
+	// index lives as long as its longest living session
+	userSessionIndexKey := s.createUserSessionIndexKey(sess.UserID)
+	pipe := s.redisClient.TxPipeline()
+	pipe.Set(ctx, s.createKey(sess.SessionID), sessBytes, time.Second*time.Duration(expire))
+	pipe.ZRemRangeByScore(ctx, userSessionIndexKey, "-inf", strconv.FormatInt(sess.CreatedAt.Unix(), 10))
+	pipe.ZAdd(ctx, userSessionIndexKey, &redis.Z{Score: float64(sess.ExpiresAt.Unix()), Member: sess.SessionID})
+	extendTTLScript.Eval(ctx, pipe, []string{userSessionIndexKey}, expire*1000)
+	if _, err = pipe.Exec(ctx); err != nil {
 		return "", errors.Wrap(err, "sessionRepo.CreateSession.redisClient.Set")
 	}
 	return sess.SessionID, nil
 }
 
+// Create session in redis unless user already has the maximum number of sessions, with evict oldest
+// the earliest created sessions are deleted to make room and returned so their refresh tokens can be revoked.
+// Counting and creation happen in one script so concurrent logins can't exceed the limit
+func (s *sessionRepo) CreateSessionWithLimit(ctx context.Context, sess *models.Session, expire int, limit *models.SessionLimit) (string, []*models.Session, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.CreateSessionWithLimit")
+	defer span.Finish()
+
+	sessBytes, err := s.initSession(sess, expire)
+	if err != nil {
+		return "", nil, errors.WithMessage(err, "sessionRepo.CreateSessionWithLimit.initSession")
+	}
+
+	evictOldest := 0
+	if limit.EvictOldest {
+		evictOldest = 1
+	}
+
+	userSessionIndexKey := s.createUserSessionIndexKey(sess.UserID)
+	for attempt := 0; attempt < createSessionWithLimitAttempts; attempt++ {
+		sessionIDs, err := s.getSessionIDsByCreation(ctx, userSessionIndexKey)
+		if err != nil {
+			return "", nil, err
+		}
+
+		keys := []string{userSessionIndexKey, s.createKey(sess.SessionID)}
+		args := []interface{}{limit.MaxSessions, evictOldest, sess.SessionID, sess.ExpiresAt.Unix(), sessBytes, expire * 1000}
+		for _, sessionID := range sessionIDs {
+			keys = append(keys, s.createKey(sessionID))
+			args = append(args, sessionID)
+		}
+
+		res, err := createSessionWithLimitScript.Run(ctx, s.redisClient, keys, args...).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				return "", nil, grpc_errors.ErrSessionLimitReached
+			}
+			return "", nil, errors.Wrap(err, "sessionRepo.CreateSessionWithLimit.Run")
+		}
+
+		switch values := res.(type) {
+		case int64:
+			// index changed since it was read
+			continue
+		case []interface{}:
+			evicted := make([]*models.Session, 0, len(values))
+			for _, value := range values {
+				data, _ := value.(string)
+				evictedSess := &models.Session{}
+				if err := json.Unmarshal([]byte(data), evictedSess); err != nil {
+					return "", nil, errors.Wrap(err, "sessionRepo.CreateSessionWithLimit.json.Unmarshal")
+				}
+				evicted = append(evicted, evictedSess)
+			}
+			return sess.SessionID, evicted, nil
+		default:
+			return "", nil, errors.Errorf("sessionRepo.CreateSessionWithLimit: unexpected script result %v", res)
+		}
+	}
+
+	return "", nil, errors.Errorf("sessionRepo.CreateSessionWithLimit: session index changed %d times", createSessionWithLimitAttempts)
+}
+
 // Get session by id
 func (s *sessionRepo) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.GetSessionByID")
@@ -67,17 +246,391 @@ func (s *sessionRepo) GetSessionByID(ctx context.Context, sessionID string) (*mo
 	return sess, nil
 }
 
-// Delete session by id
+// Update last seen and expires at of existing session, other fields are left as stored
+func (s *sessionRepo) TouchSession(ctx context.Context, sess *models.Session) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.TouchSession")
+	defer span.Finish()
+
+	ttl := time.Until(sess.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	keys := []string{s.createKey(sess.SessionID), s.createUserSessionIndexKey(sess.UserID)}
+	args := []interface{}{
+		sess.LastSeenAt.Format(time.RFC3339Nano),
+		sess.ExpiresAt.Format(time.RFC3339Nano),
+		ttl.Milliseconds(),
+		sess.ExpiresAt.Unix(),
+		sess.SessionID,
+	}
+	if err := touchSessionScript.Run(ctx, s.redisClient, keys, args...).Err(); err != nil {
+		return errors.Wrap(err, "sessionRepo.TouchSession.Run")
+	}
+	return nil
+}
+
+// Get active sessions of user ordered by expiration, index entries of expired or deleted sessions are removed
+func (s *sessionRepo) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.GetSessionsByUserID")
+	defer span.Finish()
+
+	sessionIDs, err := s.getIndexedSessionIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*models.Session, 0, len(sessionIDs))
+	if len(sessionIDs) == 0 {
+		return sessions, nil
+	}
+
+	keys := make([]string, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, s.createKey(sessionID))
+	}
+	values, err := s.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "sessionRepo.GetSessionsByUserID.MGet")
+	}
+
+	stale := make([]interface{}, 0)
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			stale = append(stale, sessionIDs[i])
+			continue
+		}
+		sess := &models.Session{}
+		if err := json.Unmarshal([]byte(data), sess); err != nil {
+			return nil, errors.Wrap(err, "sessionRepo.GetSessionsByUserID.json.Unmarshal")
+		}
+		sessions = append(sessions, sess)
+	}
+
+	if len(stale) > 0 {
+		if err := s.redisClient.ZRem(ctx, s.createUserSessionIndexKey(userID), stale...).Err(); err != nil {
+			return nil, errors.Wrap(err, "sessionRepo.GetSessionsByUserID.ZRem")
+		}
+	}
+	return sessions, nil
+}
+
+// Delete session by id and remove it from session index of its user
 func (s *sessionRepo) DeleteByID(ctx context.Context, sessionID string) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.DeleteByID")
 	defer span.Finish()
 
-	if err := s.redisClient.Del(ctx, s.createKey(sessionID)).Err(); err != nil {
+	sess, err := s.GetSessionByID(ctx, sessionID)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return errors.Wrap(err, "sessionRepo.DeleteByID.GetSessionByID")
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, s.createKey(sessionID))
+	if sess != nil {
+		pipe.ZRem(ctx, s.createUserSessionIndexKey(sess.UserID), sessionID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
 		return errors.Wrap(err, "sessionRepo.DeleteByID")
 	}
 	return nil
 }
 
+// Delete all sessions and refresh token families of the user
+func (s *sessionRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.DeleteByUserID")
+	defer span.Finish()
+
+	userSessionIndexKey := s.createUserSessionIndexKey(userID)
+	sessionIDs, err := s.redisClient.ZRange(ctx, userSessionIndexKey, 0, -1).Result()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteByUserID.ZRange")
+	}
+
+	userFamiliesKey := s.createUserRefreshFamiliesKey(userID)
+	familyIDs, err := s.redisClient.SMembers(ctx, userFamiliesKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteByUserID.SMembers")
+	}
+
+	for _, familyID := range familyIDs {
+		if err := s.DeleteRefreshFamily(ctx, familyID); err != nil {
+			return err
+		}
+	}
+
+	keys := []string{userSessionIndexKey, userFamiliesKey}
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, s.createKey(sessionID))
+	}
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteByUserID.Del")
+	}
+	return nil
+}
+
+// Delete all sessions and refresh token families of the user except the given session and its family
+func (s *sessionRepo) DeleteOtherSessions(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.DeleteOtherSessions")
+	defer span.Finish()
+
+	sess, err := s.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteOtherSessions.GetSessionByID")
+	}
+
+	userSessionIndexKey := s.createUserSessionIndexKey(userID)
+	sessionIDs, err := s.redisClient.ZRange(ctx, userSessionIndexKey, 0, -1).Result()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteOtherSessions.ZRange")
+	}
+
+	userFamiliesKey := s.createUserRefreshFamiliesKey(userID)
+	familyIDs, err := s.redisClient.SMembers(ctx, userFamiliesKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteOtherSessions.SMembers")
+	}
+
+	for _, familyID := range familyIDs {
+		if familyID == sess.FamilyID {
+			continue
+		}
+		if err := s.DeleteRefreshFamily(ctx, familyID); err != nil {
+			return err
+		}
+		if err := s.redisClient.SRem(ctx, userFamiliesKey, familyID).Err(); err != nil {
+			return errors.Wrap(err, "sessionRepo.DeleteOtherSessions.SRem")
+		}
+	}
+
+	keys := make([]string, 0, len(sessionIDs))
+	members := make([]interface{}, 0, len(sessionIDs))
+	for _, otherID := range sessionIDs {
+		if otherID != sessionID {
+			keys = append(keys, s.createKey(otherID))
+			members = append(members, otherID)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, keys...)
+	pipe.ZRem(ctx, userSessionIndexKey, members...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteOtherSessions.Exec")
+	}
+	return nil
+}
+
+// Create refresh token in redis, only token hash is stored
+func (s *sessionRepo) CreateRefreshToken(ctx context.Context, token *models.RefreshToken, expire int) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.CreateRefreshToken")
+	defer span.Finish()
+
+	tokenBytes := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", errors.Wrap(err, "sessionRepo.CreateRefreshToken.rand.Read")
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(tokenBytes)
+	tokenHash := hashRefreshToken(refreshToken)
+
+	tokenData, err := json.Marshal(token)
+	if err != nil {
+		return "", errors.WithMessage(err, "sessionRepo.CreateRefreshToken.json.Marshal")
+	}
+
+	expiration := time.Second * time.Duration(expire)
+	tokenKey := s.createRefreshTokenKey(tokenHash)
+	familyKey := s.createRefreshFamilyKey(token.FamilyID)
+	userFamiliesKey := s.createUserRefreshFamiliesKey(token.UserID)
+
+	// tokens of older logins may be capped by session max age to live shorter than existing ones,
+	// so family sets are only ever extended to outlive every token they reference
+	pipe := s.redisClient.TxPipeline()
+	pipe.HMSet(ctx, tokenKey, "data", tokenData, "used", 0)
+	pipe.Expire(ctx, tokenKey, expiration)
+	pipe.SAdd(ctx, familyKey, tokenHash)
+	extendTTLScript.Eval(ctx, pipe, []string{familyKey}, expiration.Milliseconds())
+	pipe.SAdd(ctx, userFamiliesKey, token.FamilyID)
+	extendTTLScript.Eval(ctx, pipe, []string{userFamiliesKey}, expiration.Milliseconds())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", errors.Wrap(err, "sessionRepo.CreateRefreshToken.TxPipeline.Exec")
+	}
+
+	return refreshToken, nil
+}
+
+// Mark refresh token as used, returned token is flagged as reused if it was already presented before
+func (s *sessionRepo) UseRefreshToken(ctx context.Context, refreshToken string) (*models.RefreshToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.UseRefreshToken")
+	defer span.Finish()
+
+	res, err := useRefreshTokenScript.Run(ctx, s.redisClient, []string{s.createRefreshTokenKey(hashRefreshToken(refreshToken))}).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "sessionRepo.UseRefreshToken.Run")
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 2 {
+		return nil, errors.Errorf("sessionRepo.UseRefreshToken: unexpected script result %v", res)
+	}
+	data, _ := values[0].(string)
+	used, _ := values[1].(int64)
+
+	token := &models.RefreshToken{}
+	if err := json.Unmarshal([]byte(data), token); err != nil {
+		return nil, errors.Wrap(err, "sessionRepo.UseRefreshToken.json.Unmarshal")
+	}
+	token.Reused = used > 1
+
+	return token, nil
+}
+
+// Delete every refresh token of the family
+func (s *sessionRepo) DeleteRefreshFamily(ctx context.Context, familyID string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.DeleteRefreshFamily")
+	defer span.Finish()
+
+	familyKey := s.createRefreshFamilyKey(familyID)
+	tokenHashes, err := s.redisClient.SMembers(ctx, familyKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteRefreshFamily.SMembers")
+	}
+
+	keys := []string{familyKey}
+	for _, tokenHash := range tokenHashes {
+		keys = append(keys, s.createRefreshTokenKey(tokenHash))
+	}
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteRefreshFamily.Del")
+	}
+	return nil
+}
+
+// Assign id and timestamps to new session and returns its data
+func (s *sessionRepo) initSession(sess *models.Session, expire int) ([]byte, error) {
+	sess.SessionID = uuid.New().String()
+	if sess.FamilyID == "" {
+		sess.FamilyID = sess.SessionID
+	}
+
+	now := time.Now()
+	if sess.AuthenticatedAt.IsZero() {
+		sess.AuthenticatedAt = now
+	}
+	sess.CreatedAt = now
+	sess.LastSeenAt = now
+	sess.ExpiresAt = now.Add(time.Second * time.Duration(expire))
+
+	return json.Marshal(sess)
+}
+
+// Store time before which every session of user is revoked, the key has no expiration
+// because sessions can be extended or refreshed indefinitely
+func (s *sessionRepo) SetSessionsValidAfter(ctx context.Context, userID uuid.UUID, validAfter time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.SetSessionsValidAfter")
+	defer span.Finish()
+
+	if err := s.redisClient.Set(ctx, s.createSessionsValidAfterKey(userID), validAfter.UnixNano(), 0).Err(); err != nil {
+		return errors.Wrap(err, "sessionRepo.SetSessionsValidAfter.Set")
+	}
+	return nil
+}
+
+// Get time before which every session of user is revoked, zero time if sessions of user were never revoked
+func (s *sessionRepo) GetSessionsValidAfter(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.GetSessionsValidAfter")
+	defer span.Finish()
+
+	validAfter, err := s.redisClient.Get(ctx, s.createSessionsValidAfterKey(userID)).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, errors.Wrap(err, "sessionRepo.GetSessionsValidAfter.Get")
+	}
+	return time.Unix(0, validAfter), nil
+}
+
 func (s *sessionRepo) createKey(sessionID string) string {
 	return fmt.Sprintf("%s: %s", s.basePrefix, sessionID)
 }
+
+// Ids of indexed sessions oldest first, ids of missing sessions come first as they have no creation time
+func (s *sessionRepo) getSessionIDsByCreation(ctx context.Context, userSessionIndexKey string) ([]string, error) {
+	sessionIDs, err := s.redisClient.ZRange(ctx, userSessionIndexKey, 0, -1).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "sessionRepo.getSessionIDsByCreation.ZRange")
+	}
+	if len(sessionIDs) == 0 {
+		return sessionIDs, nil
+	}
+
+	pipe := s.redisClient.Pipeline()
+	cmds := make([]*redis.StringCmd, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		cmds = append(cmds, pipe.Get(ctx, s.createKey(sessionID)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, errors.Wrap(err, "sessionRepo.getSessionIDsByCreation.Exec")
+	}
+
+	createdAt := make(map[string]time.Time, len(sessionIDs))
+	for i, cmd := range cmds {
+		sessBytes, err := cmd.Bytes()
+		if err != nil {
+			continue
+		}
+		sess := &models.Session{}
+		if err := json.Unmarshal(sessBytes, sess); err != nil {
+			return nil, errors.Wrap(err, "sessionRepo.getSessionIDsByCreation.json.Unmarshal")
+		}
+		createdAt[sessionIDs[i]] = sess.CreatedAt
+	}
+
+	sort.SliceStable(sessionIDs, func(i, j int) bool {
+		return createdAt[sessionIDs[i]].Before(createdAt[sessionIDs[j]])
+	})
+	return sessionIDs, nil
+}
+
+// Ids of not yet expired sessions of user, soonest expiring first
+func (s *sessionRepo) getIndexedSessionIDs(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	userSessionIndexKey := s.createUserSessionIndexKey(userID)
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, userSessionIndexKey, "-inf", strconv.FormatInt(time.Now().Unix(), 10))
+	sessionIDs := pipe.ZRange(ctx, userSessionIndexKey, 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errors.Wrap(err, "sessionRepo.getIndexedSessionIDs.Exec")
+	}
+	return sessionIDs.Val(), nil
+}
+
+func (s *sessionRepo) createUserSessionIndexKey(userID uuid.UUID) string {
+	return fmt.Sprintf("%s: %s", userSessionIndexPrefix, userID.String())
+}
+
+func (s *sessionRepo) createSessionsValidAfterKey(userID uuid.UUID) string {
+	return fmt.Sprintf("%s: %s", sessionsValidAfterPrefix, userID.String())
+}
+
+func (s *sessionRepo) createRefreshTokenKey(tokenHash string) string {
+	return fmt.Sprintf("%s: %s", refreshTokenPrefix, tokenHash)
+}
+
+func (s *sessionRepo) createRefreshFamilyKey(familyID string) string {
+	return fmt.Sprintf("%s: %s", refreshFamilyPrefix, familyID)
+}
+
+func (s *sessionRepo) createUserRefreshFamiliesKey(userID uuid.UUID) string {
+	return fmt.Sprintf("%s: %s", userRefreshFamiliesPrefix, userID.String())
+}
+
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
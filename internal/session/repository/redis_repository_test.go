@@ -2,16 +2,20 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 	"github.com/AleksK1NG/auth-microservice/internal/session"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
 )
 
 func SetupRedis() session.SessRepository {
@@ -44,6 +48,77 @@ func TestCreateSession(t *testing.T) {
 	})
 }
 
+func TestCreateSessionWithLimit(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	sessRepository := NewSessionRepository(client, nil)
+
+	t.Run("Reject", func(t *testing.T) {
+		userID := uuid.New()
+		ctx := context.Background()
+		limit := &models.SessionLimit{MaxSessions: 2}
+
+		for i := 0; i < 2; i++ {
+			_, evicted, err := sessRepository.CreateSessionWithLimit(ctx, &models.Session{UserID: userID}, 10, limit)
+			require.NoError(t, err)
+			require.Empty(t, evicted)
+		}
+
+		_, _, err := sessRepository.CreateSessionWithLimit(ctx, &models.Session{UserID: userID}, 10, limit)
+		require.True(t, errors.Is(err, grpc_errors.ErrSessionLimitReached))
+
+		sessions, err := sessRepository.GetSessionsByUserID(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+	})
+
+	t.Run("EvictOldest", func(t *testing.T) {
+		userID := uuid.New()
+		ctx := context.Background()
+		limit := &models.SessionLimit{MaxSessions: 2, EvictOldest: true}
+
+		// oldest session was extended by sliding expiration and expires last
+		oldest := &models.Session{UserID: userID}
+		oldestID, _, err := sessRepository.CreateSessionWithLimit(ctx, oldest, 30, limit)
+		require.NoError(t, err)
+		middleID, _, err := sessRepository.CreateSessionWithLimit(ctx, &models.Session{UserID: userID}, 5, limit)
+		require.NoError(t, err)
+
+		newestID, evicted, err := sessRepository.CreateSessionWithLimit(ctx, &models.Session{UserID: userID}, 10, limit)
+		require.NoError(t, err)
+		require.Len(t, evicted, 1)
+		require.Equal(t, oldestID, evicted[0].SessionID)
+		require.Equal(t, oldest.FamilyID, evicted[0].FamilyID)
+
+		_, err = sessRepository.GetSessionByID(ctx, oldestID)
+		require.True(t, errors.Is(err, redis.Nil))
+		sessions, err := sessRepository.GetSessionsByUserID(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		require.Equal(t, middleID, sessions[0].SessionID)
+		require.Equal(t, newestID, sessions[1].SessionID)
+	})
+
+	t.Run("Deleted sessions are not counted", func(t *testing.T) {
+		userID := uuid.New()
+		ctx := context.Background()
+		limit := &models.SessionLimit{MaxSessions: 1}
+
+		sessionID, _, err := sessRepository.CreateSessionWithLimit(ctx, &models.Session{UserID: userID}, 10, limit)
+		require.NoError(t, err)
+		// session key expired but index entry is still there
+		mr.Del(fmt.Sprintf("%s: %s", basePrefix, sessionID))
+
+		_, evicted, err := sessRepository.CreateSessionWithLimit(ctx, &models.Session{UserID: userID}, 10, limit)
+		require.NoError(t, err)
+		require.Empty(t, evicted)
+	})
+}
+
 func TestGetSessionByID(t *testing.T) {
 	t.Parallel()
 
@@ -65,6 +140,84 @@ func TestGetSessionByID(t *testing.T) {
 	})
 }
 
+func TestTouchSession(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("TouchSession", func(t *testing.T) {
+		sess := &models.Session{UserID: uuid.New(), IPAddress: "203.0.113.7", LoginMethod: "password"}
+		sessionID, err := sessRepository.CreateSession(context.Background(), sess, 10)
+		require.NoError(t, err)
+
+		sess.LastSeenAt = sess.LastSeenAt.Add(time.Minute)
+		err = sessRepository.TouchSession(context.Background(), sess)
+		require.NoError(t, err)
+
+		updated, err := sessRepository.GetSessionByID(context.Background(), sessionID)
+		require.NoError(t, err)
+		require.True(t, sess.LastSeenAt.Equal(updated.LastSeenAt))
+		require.True(t, sess.ExpiresAt.Equal(updated.ExpiresAt))
+		require.Equal(t, sess.IPAddress, updated.IPAddress)
+		require.Equal(t, sess.LoginMethod, updated.LoginMethod)
+	})
+
+	t.Run("Stale session does not overwrite stored fields", func(t *testing.T) {
+		sess := &models.Session{UserID: uuid.New(), IPAddress: "203.0.113.7", LoginMethod: "password"}
+		sessionID, err := sessRepository.CreateSession(context.Background(), sess, 10)
+		require.NoError(t, err)
+
+		stale := *sess
+		stale.IPAddress = "198.51.100.1"
+		stale.FamilyID = uuid.New().String()
+		stale.LastSeenAt = stale.LastSeenAt.Add(time.Minute)
+		require.NoError(t, sessRepository.TouchSession(context.Background(), &stale))
+
+		updated, err := sessRepository.GetSessionByID(context.Background(), sessionID)
+		require.NoError(t, err)
+		require.True(t, stale.LastSeenAt.Equal(updated.LastSeenAt))
+		require.Equal(t, sess.IPAddress, updated.IPAddress)
+		require.Equal(t, sess.FamilyID, updated.FamilyID)
+	})
+
+	t.Run("Deleted session is not recreated", func(t *testing.T) {
+		sess := &models.Session{UserID: uuid.New()}
+		sessionID, err := sessRepository.CreateSession(context.Background(), sess, 10)
+		require.NoError(t, err)
+		require.NoError(t, sessRepository.DeleteByID(context.Background(), sessionID))
+
+		err = sessRepository.TouchSession(context.Background(), sess)
+		require.NoError(t, err)
+
+		_, err = sessRepository.GetSessionByID(context.Background(), sessionID)
+		require.True(t, errors.Is(err, redis.Nil))
+	})
+}
+
+func TestSessionsValidAfter(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("Never revoked", func(t *testing.T) {
+		validAfter, err := sessRepository.GetSessionsValidAfter(context.Background(), uuid.New())
+		require.NoError(t, err)
+		require.True(t, validAfter.IsZero())
+	})
+
+	t.Run("SetSessionsValidAfter", func(t *testing.T) {
+		userID := uuid.New()
+		revokedAt := time.Now()
+
+		err := sessRepository.SetSessionsValidAfter(context.Background(), userID, revokedAt)
+		require.NoError(t, err)
+
+		validAfter, err := sessRepository.GetSessionsValidAfter(context.Background(), userID)
+		require.NoError(t, err)
+		require.True(t, revokedAt.Equal(validAfter))
+	})
+}
+
 func TestDeleteSession(t *testing.T) {
 	t.Parallel()
 
@@ -76,3 +229,227 @@ func TestDeleteSession(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("UseRefreshToken", func(t *testing.T) {
+		token := &models.RefreshToken{
+			FamilyID:  uuid.New().String(),
+			UserID:    uuid.New(),
+			SessionID: uuid.New().String(),
+		}
+		refreshToken, err := sessRepository.CreateRefreshToken(context.Background(), token, 10)
+		require.NoError(t, err)
+		require.NotEqual(t, refreshToken, "")
+
+		used, err := sessRepository.UseRefreshToken(context.Background(), refreshToken)
+		require.NoError(t, err)
+		require.Equal(t, token.FamilyID, used.FamilyID)
+		require.Equal(t, token.SessionID, used.SessionID)
+		require.False(t, used.Reused)
+
+		reused, err := sessRepository.UseRefreshToken(context.Background(), refreshToken)
+		require.NoError(t, err)
+		require.True(t, reused.Reused)
+	})
+
+	t.Run("DeleteRefreshFamily", func(t *testing.T) {
+		token := &models.RefreshToken{
+			FamilyID:  uuid.New().String(),
+			UserID:    uuid.New(),
+			SessionID: uuid.New().String(),
+		}
+		refreshToken, err := sessRepository.CreateRefreshToken(context.Background(), token, 10)
+		require.NoError(t, err)
+
+		err = sessRepository.DeleteRefreshFamily(context.Background(), token.FamilyID)
+		require.NoError(t, err)
+
+		_, err = sessRepository.UseRefreshToken(context.Background(), refreshToken)
+		require.True(t, errors.Is(err, redis.Nil))
+	})
+}
+
+func TestUserRefreshFamiliesTTL(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	sessRepository := NewSessionRepository(client, nil)
+
+	userID := uuid.New()
+	userFamiliesKey := fmt.Sprintf("%s: %s", userRefreshFamiliesPrefix, userID.String())
+
+	_, err = sessRepository.CreateRefreshToken(context.Background(), &models.RefreshToken{FamilyID: uuid.New().String(), UserID: userID}, 100)
+	require.NoError(t, err)
+	// token of an older family capped by session max age must not shorten the index of the newer one
+	_, err = sessRepository.CreateRefreshToken(context.Background(), &models.RefreshToken{FamilyID: uuid.New().String(), UserID: userID}, 10)
+	require.NoError(t, err)
+
+	require.Equal(t, 100*time.Second, mr.TTL(userFamiliesKey))
+}
+
+func TestDeleteByUserID(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("DeleteByUserID", func(t *testing.T) {
+		userID := uuid.New()
+		sess := &models.Session{UserID: userID}
+		sessID, err := sessRepository.CreateSession(context.Background(), sess, 10)
+		require.NoError(t, err)
+
+		refreshToken, err := sessRepository.CreateRefreshToken(context.Background(), &models.RefreshToken{
+			FamilyID:  sess.FamilyID,
+			UserID:    userID,
+			SessionID: sessID,
+		}, 10)
+		require.NoError(t, err)
+
+		err = sessRepository.DeleteByUserID(context.Background(), userID)
+		require.NoError(t, err)
+
+		_, err = sessRepository.GetSessionByID(context.Background(), sessID)
+		require.True(t, errors.Is(err, redis.Nil))
+
+		_, err = sessRepository.UseRefreshToken(context.Background(), refreshToken)
+		require.True(t, errors.Is(err, redis.Nil))
+	})
+}
+
+func TestDeleteOtherSessions(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("DeleteOtherSessions", func(t *testing.T) {
+		userID := uuid.New()
+		ctx := context.Background()
+
+		current := &models.Session{UserID: userID}
+		currentID, err := sessRepository.CreateSession(ctx, current, 10)
+		require.NoError(t, err)
+		currentRefresh, err := sessRepository.CreateRefreshToken(ctx, &models.RefreshToken{
+			FamilyID:  current.FamilyID,
+			UserID:    userID,
+			SessionID: currentID,
+		}, 10)
+		require.NoError(t, err)
+
+		other := &models.Session{UserID: userID}
+		otherID, err := sessRepository.CreateSession(ctx, other, 10)
+		require.NoError(t, err)
+		otherRefresh, err := sessRepository.CreateRefreshToken(ctx, &models.RefreshToken{
+			FamilyID:  other.FamilyID,
+			UserID:    userID,
+			SessionID: otherID,
+		}, 10)
+		require.NoError(t, err)
+
+		err = sessRepository.DeleteOtherSessions(ctx, userID, currentID)
+		require.NoError(t, err)
+
+		_, err = sessRepository.GetSessionByID(ctx, otherID)
+		require.True(t, errors.Is(err, redis.Nil))
+		_, err = sessRepository.UseRefreshToken(ctx, otherRefresh)
+		require.True(t, errors.Is(err, redis.Nil))
+
+		_, err = sessRepository.GetSessionByID(ctx, currentID)
+		require.NoError(t, err)
+		_, err = sessRepository.UseRefreshToken(ctx, currentRefresh)
+		require.NoError(t, err)
+	})
+}
+
+func TestGetSessionsByUserID(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("GetSessionsByUserID", func(t *testing.T) {
+		userID := uuid.New()
+		ctx := context.Background()
+
+		firstID, err := sessRepository.CreateSession(ctx, &models.Session{UserID: userID}, 10)
+		require.NoError(t, err)
+		secondID, err := sessRepository.CreateSession(ctx, &models.Session{UserID: userID}, 10)
+		require.NoError(t, err)
+		require.NoError(t, sessRepository.DeleteByID(ctx, secondID))
+
+		sessions, err := sessRepository.GetSessionsByUserID(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.Equal(t, firstID, sessions[0].SessionID)
+
+		sessions, err = sessRepository.GetSessionsByUserID(ctx, uuid.New())
+		require.NoError(t, err)
+		require.Empty(t, sessions)
+	})
+}
+
+func TestSessionIndex(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	sessRepository := NewSessionRepository(client, nil)
+
+	t.Run("DeleteByID", func(t *testing.T) {
+		userID := uuid.New()
+		ctx := context.Background()
+
+		sessionID, err := sessRepository.CreateSession(ctx, &models.Session{UserID: userID}, 10)
+		require.NoError(t, err)
+		require.NoError(t, sessRepository.DeleteByID(ctx, sessionID))
+
+		require.False(t, mr.Exists(fmt.Sprintf("%s: %s", userSessionIndexPrefix, userID.String())))
+	})
+
+	t.Run("ExpiredSessions", func(t *testing.T) {
+		userID := uuid.New()
+		ctx := context.Background()
+		indexKey := fmt.Sprintf("%s: %s", userSessionIndexPrefix, userID.String())
+
+		sessionID, err := sessRepository.CreateSession(ctx, &models.Session{UserID: userID}, 10)
+		require.NoError(t, err)
+		// session key expired but index entry is still there
+		mr.Del(fmt.Sprintf("%s: %s", basePrefix, sessionID))
+
+		sessions, err := sessRepository.GetSessionsByUserID(ctx, userID)
+		require.NoError(t, err)
+		require.Empty(t, sessions)
+
+		require.False(t, mr.Exists(indexKey))
+	})
+
+	t.Run("Extended session", func(t *testing.T) {
+		userID := uuid.New()
+		ctx := context.Background()
+		indexKey := fmt.Sprintf("%s: %s", userSessionIndexPrefix, userID.String())
+
+		sess := &models.Session{UserID: userID}
+		sessionID, err := sessRepository.CreateSession(ctx, sess, 10)
+		require.NoError(t, err)
+		// shorter session must not shorten index of the longer one
+		_, err = sessRepository.CreateSession(ctx, &models.Session{UserID: userID}, 5)
+		require.NoError(t, err)
+		require.Equal(t, 10*time.Second, mr.TTL(indexKey))
+
+		sess.ExpiresAt = sess.ExpiresAt.Add(time.Hour)
+		require.NoError(t, sessRepository.TouchSession(ctx, sess))
+
+		require.True(t, mr.TTL(fmt.Sprintf("%s: %s", basePrefix, sessionID)) > time.Hour)
+		require.True(t, mr.TTL(indexKey) > time.Hour)
+		score, err := mr.ZScore(indexKey, sessionID)
+		require.NoError(t, err)
+		require.Equal(t, float64(sess.ExpiresAt.Unix()), score)
+	})
+}
@@ -4,12 +4,22 @@ package session
 import (
 	"context"
 
+	"github.com/google/uuid"
+
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 )
 
 // Session UseCase
 type SessionUseCase interface {
-	CreateSession(ctx context.Context, session *models.Session, expire int) (string, error)
+	CreateSession(ctx context.Context, session *models.Session, roles []string, expire int) (string, error)
 	GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error)
+	GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error)
+	Touch(ctx context.Context, session *models.Session) error
 	DeleteByID(ctx context.Context, sessionID string) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteByPublicID(ctx context.Context, userID uuid.UUID, publicID string) error
+	DeleteOtherSessions(ctx context.Context, userID uuid.UUID, sessionID string) error
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	CreateRefreshToken(ctx context.Context, session *models.Session) (string, error)
+	RefreshSession(ctx context.Context, refreshToken string, client *models.ClientInfo) (*models.Session, string, error)
 }
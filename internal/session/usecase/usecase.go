@@ -2,14 +2,23 @@ package usecase
 
 import (
 	"context"
+	"time"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/auth-microservice/config"
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 	"github.com/AleksK1NG/auth-microservice/internal/session"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
 )
 
+// Last seen time and sliding expiration of session are written at most once per interval
+// to keep authenticated calls cheap
+const lastSeenUpdateInterval = time.Minute
+
 // Session use case
 type sessionUC struct {
 	sessionRepo session.SessRepository
@@ -21,26 +30,320 @@ func NewSessionUseCase(sessionRepo session.SessRepository, cfg *config.Config) s
 	return &sessionUC{sessionRepo: sessionRepo, cfg: cfg}
 }
 
-// Create new session
-func (u *sessionUC) CreateSession(ctx context.Context, session *models.Session, expire int) (string, error) {
+// Create new session of user having the roles, its expiration is capped by session max age
+// and number of simultaneous sessions by the concurrent session limit of the roles
+func (u *sessionUC) CreateSession(ctx context.Context, session *models.Session, roles []string, expire int) (string, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.CreateSession")
 	defer span.Finish()
 
-	return u.sessionRepo.CreateSession(ctx, session, expire)
+	expire, err := u.capExpire(session.AuthenticatedAt, expire)
+	if err != nil {
+		return "", err
+	}
+
+	limit := u.sessionLimit(roles)
+	if limit == nil {
+		return u.sessionRepo.CreateSession(ctx, session, expire)
+	}
+
+	sessionID, evicted, err := u.sessionRepo.CreateSessionWithLimit(ctx, session, expire, limit)
+	if err != nil {
+		return "", err
+	}
+	for _, sess := range evicted {
+		if err := u.sessionRepo.DeleteRefreshFamily(ctx, sess.FamilyID); err != nil {
+			return "", err
+		}
+	}
+
+	return sessionID, nil
+}
+
+// Get active sessions of user
+func (u *sessionUC) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.GetSessionsByUserID")
+	defer span.Finish()
+
+	sessions, err := u.sessionRepo.GetSessionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	validAfter, err := u.sessionRepo.GetSessionsValidAfter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]*models.Session, 0, len(sessions))
+	for _, sess := range sessions {
+		if isRevoked(sess.AuthenticatedAt, validAfter) {
+			continue
+		}
+		active = append(active, sess)
+	}
+	return active, nil
+}
+
+// Mark session as seen now and extend it with sliding expiration,
+// skipped when session was already marked within touch interval
+func (u *sessionUC) Touch(ctx context.Context, session *models.Session) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.Touch")
+	defer span.Finish()
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) < u.touchInterval() {
+		return nil
+	}
+
+	session.LastSeenAt = now
+	if u.cfg.Session.SlidingExpiration {
+		// session which reached its max age is not extended and expires at its cap
+		if expire, err := u.capExpire(session.AuthenticatedAt, u.cfg.Session.Expire); err == nil {
+			if expiresAt := now.Add(time.Second * time.Duration(expire)); expiresAt.After(session.ExpiresAt) {
+				session.ExpiresAt = expiresAt
+			}
+		}
+	}
+
+	return u.sessionRepo.TouchSession(ctx, session)
 }
 
-// Delete session by id
+// Delete session by id and revoke its refresh tokens
 func (u *sessionUC) DeleteByID(ctx context.Context, sessionID string) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.DeleteByID")
 	defer span.Finish()
 
+	sess, err := u.sessionRepo.GetSessionByID(ctx, sessionID)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if sess != nil && sess.FamilyID != "" {
+		if err := u.sessionRepo.DeleteRefreshFamily(ctx, sess.FamilyID); err != nil {
+			return err
+		}
+	}
+
 	return u.sessionRepo.DeleteByID(ctx, sessionID)
 }
 
+// Delete session of user by its public id and revoke its refresh tokens
+func (u *sessionUC) DeleteByPublicID(ctx context.Context, userID uuid.UUID, publicID string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.DeleteByPublicID")
+	defer span.Finish()
+
+	sessions, err := u.sessionRepo.GetSessionsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, sess := range sessions {
+		if sess.PublicID() != publicID {
+			continue
+		}
+		if err := u.sessionRepo.DeleteRefreshFamily(ctx, sess.FamilyID); err != nil {
+			return err
+		}
+		return u.sessionRepo.DeleteByID(ctx, sess.SessionID)
+	}
+
+	return grpc_errors.ErrSessionNotFound
+}
+
+// Delete all sessions and refresh tokens of the user
+func (u *sessionUC) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.DeleteByUserID")
+	defer span.Finish()
+
+	return u.sessionRepo.DeleteByUserID(ctx, userID)
+}
+
+// Revoke every session and refresh token of the user, sessions authenticated before now are rejected
+// even if they are missing from the user session index
+func (u *sessionUC) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.RevokeUserSessions")
+	defer span.Finish()
+
+	if err := u.sessionRepo.SetSessionsValidAfter(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+
+	return u.sessionRepo.DeleteByUserID(ctx, userID)
+}
+
+// Delete all sessions and refresh tokens of the user except the given session
+func (u *sessionUC) DeleteOtherSessions(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.DeleteOtherSessions")
+	defer span.Finish()
+
+	return u.sessionRepo.DeleteOtherSessions(ctx, userID, sessionID)
+}
+
+// Create refresh token bound to the session refresh family
+func (u *sessionUC) CreateRefreshToken(ctx context.Context, session *models.Session) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.CreateRefreshToken")
+	defer span.Finish()
+
+	expire, err := u.capExpire(session.AuthenticatedAt, u.cfg.Session.RefreshExpire)
+	if err != nil {
+		return "", err
+	}
+
+	return u.sessionRepo.CreateRefreshToken(ctx, &models.RefreshToken{
+		FamilyID:        session.FamilyID,
+		UserID:          session.UserID,
+		SessionID:       session.SessionID,
+		LoginMethod:     session.LoginMethod,
+		AuthenticatedAt: session.AuthenticatedAt,
+	}, expire)
+}
+
+// Exchange refresh token for a new session and refresh token of the same family,
+// presenting already used token revokes the family and every session of the user,
+// family which reached session max age or revoked by admin is revoked and user must login again
+func (u *sessionUC) RefreshSession(ctx context.Context, refreshToken string, client *models.ClientInfo) (*models.Session, string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.RefreshSession")
+	defer span.Finish()
+
+	token, err := u.sessionRepo.UseRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, "", errors.Wrap(grpc_errors.ErrInvalidToken, "sessionRepo.UseRefreshToken")
+		}
+		return nil, "", err
+	}
+
+	if token.Reused {
+		if err := u.sessionRepo.DeleteRefreshFamily(ctx, token.FamilyID); err != nil {
+			return nil, "", err
+		}
+		if err := u.sessionRepo.DeleteByUserID(ctx, token.UserID); err != nil {
+			return nil, "", err
+		}
+		return nil, "", grpc_errors.ErrRefreshTokenUsed
+	}
+
+	validAfter, err := u.sessionRepo.GetSessionsValidAfter(ctx, token.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	if isRevoked(token.AuthenticatedAt, validAfter) {
+		if err := u.sessionRepo.DeleteRefreshFamily(ctx, token.FamilyID); err != nil {
+			return nil, "", err
+		}
+		return nil, "", errors.Wrap(grpc_errors.ErrInvalidToken, "refresh token revoked")
+	}
+
+	expire, err := u.capExpire(token.AuthenticatedAt, u.cfg.Session.Expire)
+	if err != nil {
+		if err := u.sessionRepo.DeleteRefreshFamily(ctx, token.FamilyID); err != nil {
+			return nil, "", err
+		}
+		return nil, "", err
+	}
+
+	if err := u.sessionRepo.DeleteByID(ctx, token.SessionID); err != nil {
+		return nil, "", err
+	}
+
+	sess := &models.Session{
+		UserID:          token.UserID,
+		FamilyID:        token.FamilyID,
+		IPAddress:       client.IPAddress,
+		UserAgent:       client.UserAgent,
+		LoginMethod:     token.LoginMethod,
+		AuthenticatedAt: token.AuthenticatedAt,
+	}
+	if _, err := u.sessionRepo.CreateSession(ctx, sess, expire); err != nil {
+		return nil, "", err
+	}
+
+	newRefreshToken, err := u.CreateRefreshToken(ctx, sess)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return sess, newRefreshToken, nil
+}
+
 // get session by id
 func (u *sessionUC) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.GetSessionByID")
 	defer span.Finish()
 
-	return u.sessionRepo.GetSessionByID(ctx, sessionID)
+	sess, err := u.sessionRepo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	validAfter, err := u.sessionRepo.GetSessionsValidAfter(ctx, sess.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// revoked session is reported as missing, so callers treat it like an expired one
+	if isRevoked(sess.AuthenticatedAt, validAfter) {
+		return nil, errors.Wrap(redis.Nil, "sessionUC.GetSessionByID: session revoked")
+	}
+
+	return sess, nil
+}
+
+// Cap expiration in seconds by time left until session max age since login, zero login time means login is happening now
+func (u *sessionUC) capExpire(authenticatedAt time.Time, expire int) (int, error) {
+	maxAge := u.cfg.Session.MaxAge
+	if maxAge <= 0 {
+		return expire, nil
+	}
+
+	remaining := maxAge
+	if !authenticatedAt.IsZero() {
+		remaining = int(time.Until(authenticatedAt.Add(time.Second*time.Duration(maxAge))) / time.Second)
+	}
+	if remaining <= 0 {
+		return 0, grpc_errors.ErrSessionMaxAgeExceeded
+	}
+	if remaining < expire {
+		return remaining, nil
+	}
+	return expire, nil
+}
+
+// Concurrent session limit of user having the roles, the most restrictive limit of roles having one
+// overrides the default, nil means unlimited
+func (u *sessionUC) sessionLimit(roles []string) *models.SessionLimit {
+	maxSessions := u.cfg.Session.MaxConcurrent
+	roleLimited := false
+	for _, role := range roles {
+		roleMax, ok := u.cfg.Session.MaxConcurrentByRole[role]
+		if !ok {
+			continue
+		}
+		if !roleLimited || (roleMax > 0 && (maxSessions <= 0 || roleMax < maxSessions)) {
+			maxSessions = roleMax
+		}
+		roleLimited = true
+	}
+
+	if maxSessions <= 0 {
+		return nil
+	}
+	return &models.SessionLimit{
+		MaxSessions: maxSessions,
+		EvictOldest: u.cfg.Session.LimitPolicy == models.SessionLimitPolicyEvictOldest,
+	}
+}
+
+// Session authenticated at the given time is revoked if it was not authenticated after the revocation point
+func isRevoked(authenticatedAt time.Time, validAfter time.Time) bool {
+	return !validAfter.IsZero() && !authenticatedAt.After(validAfter)
+}
+
+// Sliding session is touched at least twice per idle timeout so active session never expires between touches
+func (u *sessionUC) touchInterval() time.Duration {
+	if !u.cfg.Session.SlidingExpiration {
+		return lastSeenUpdateInterval
+	}
+	if interval := time.Second * time.Duration(u.cfg.Session.Expire) / 2; interval < lastSeenUpdateInterval {
+		return interval
+	}
+	return lastSeenUpdateInterval
 }
@@ -3,12 +3,18 @@ package usecase
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	"github.com/AleksK1NG/auth-microservice/config"
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 	"github.com/AleksK1NG/auth-microservice/internal/session/mock"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
 )
 
 func TestSessionUC_CreateSession(t *testing.T) {
@@ -18,7 +24,7 @@ func TestSessionUC_CreateSession(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockSessRepo := mock.NewMockSessRepository(ctrl)
-	sessUC := NewSessionUseCase(mockSessRepo, nil)
+	sessUC := NewSessionUseCase(mockSessRepo, &config.Config{Session: config.Session{MaxAge: 100}})
 
 	ctx := context.Background()
 	sess := &models.Session{}
@@ -26,10 +32,79 @@ func TestSessionUC_CreateSession(t *testing.T) {
 
 	mockSessRepo.EXPECT().CreateSession(gomock.Any(), gomock.Eq(sess), 10).Return(sid, nil)
 
-	createdSess, err := sessUC.CreateSession(ctx, sess, 10)
+	createdSess, err := sessUC.CreateSession(ctx, sess, nil, 10)
 	require.NoError(t, err)
 	require.Nil(t, err)
 	require.NotEqual(t, createdSess, "")
+
+	t.Run("Capped by max age", func(t *testing.T) {
+		sess := &models.Session{AuthenticatedAt: time.Now().Add(-95 * time.Second)}
+
+		mockSessRepo.EXPECT().CreateSession(gomock.Any(), sess, 4).Return(sid, nil)
+
+		_, err := sessUC.CreateSession(ctx, sess, nil, 10)
+		require.NoError(t, err)
+	})
+
+	t.Run("Max age exceeded", func(t *testing.T) {
+		sess := &models.Session{AuthenticatedAt: time.Now().Add(-time.Hour)}
+
+		_, err := sessUC.CreateSession(ctx, sess, nil, 10)
+		require.True(t, errors.Is(err, grpc_errors.ErrSessionMaxAgeExceeded))
+	})
+}
+
+func TestSessionUC_CreateSessionWithLimit(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, &config.Config{Session: config.Session{
+		MaxConcurrent:       5,
+		MaxConcurrentByRole: map[string]int{models.RoleAdmin: 1},
+		LimitPolicy:         models.SessionLimitPolicyEvictOldest,
+	}})
+
+	ctx := context.Background()
+
+	t.Run("Default limit", func(t *testing.T) {
+		sess := &models.Session{UserID: uuid.New()}
+		limit := &models.SessionLimit{MaxSessions: 5, EvictOldest: true}
+
+		mockSessRepo.EXPECT().CreateSessionWithLimit(gomock.Any(), sess, 10, limit).Return("session id", nil, nil)
+
+		sessionID, err := sessUC.CreateSession(ctx, sess, []string{models.RoleUser}, 10)
+		require.NoError(t, err)
+		require.Equal(t, "session id", sessionID)
+	})
+
+	t.Run("Role limit evicts oldest", func(t *testing.T) {
+		sess := &models.Session{UserID: uuid.New()}
+		limit := &models.SessionLimit{MaxSessions: 1, EvictOldest: true}
+		evicted := &models.Session{SessionID: uuid.New().String(), FamilyID: uuid.New().String()}
+
+		mockSessRepo.EXPECT().CreateSessionWithLimit(gomock.Any(), sess, 10, limit).Return("session id", []*models.Session{evicted}, nil)
+		mockSessRepo.EXPECT().DeleteRefreshFamily(gomock.Any(), evicted.FamilyID).Return(nil)
+
+		_, err := sessUC.CreateSession(ctx, sess, []string{models.RoleUser, models.RoleAdmin}, 10)
+		require.NoError(t, err)
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		sessUC := NewSessionUseCase(mockSessRepo, &config.Config{Session: config.Session{
+			MaxConcurrent: 2,
+			LimitPolicy:   models.SessionLimitPolicyReject,
+		}})
+		sess := &models.Session{UserID: uuid.New()}
+		limit := &models.SessionLimit{MaxSessions: 2}
+
+		mockSessRepo.EXPECT().CreateSessionWithLimit(gomock.Any(), sess, 10, limit).Return("", nil, grpc_errors.ErrSessionLimitReached)
+
+		_, err := sessUC.CreateSession(ctx, sess, []string{models.RoleUser}, 10)
+		require.True(t, errors.Is(err, grpc_errors.ErrSessionLimitReached))
+	})
 }
 
 func TestSessionUC_GetSessionByID(t *testing.T) {
@@ -46,6 +121,7 @@ func TestSessionUC_GetSessionByID(t *testing.T) {
 	sid := "session id"
 
 	mockSessRepo.EXPECT().GetSessionByID(gomock.Any(), gomock.Eq(sid)).Return(sess, nil)
+	mockSessRepo.EXPECT().GetSessionsValidAfter(gomock.Any(), sess.UserID).Return(time.Time{}, nil)
 
 	session, err := sessUC.GetSessionByID(ctx, sid)
 	require.NoError(t, err)
@@ -65,9 +141,255 @@ func TestSessionUC_DeleteByID(t *testing.T) {
 	ctx := context.Background()
 	sid := "session id"
 
+	mockSessRepo.EXPECT().GetSessionByID(gomock.Any(), gomock.Eq(sid)).Return(&models.Session{SessionID: sid, FamilyID: sid}, nil)
+	mockSessRepo.EXPECT().DeleteRefreshFamily(gomock.Any(), gomock.Eq(sid)).Return(nil)
 	mockSessRepo.EXPECT().DeleteByID(gomock.Any(), gomock.Eq(sid)).Return(nil)
 
 	err := sessUC.DeleteByID(ctx, sid)
 	require.NoError(t, err)
 	require.Nil(t, err)
 }
+
+func TestSessionUC_RefreshSession(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	cfg := &config.Config{Session: config.Session{Expire: 10, RefreshExpire: 100}}
+	sessUC := NewSessionUseCase(mockSessRepo, cfg)
+
+	ctx := context.Background()
+
+	t.Run("Rotate", func(t *testing.T) {
+		token := &models.RefreshToken{
+			FamilyID:    uuid.New().String(),
+			UserID:      uuid.New(),
+			SessionID:   uuid.New().String(),
+			LoginMethod: "passkey",
+		}
+		client := &models.ClientInfo{IPAddress: "203.0.113.7", UserAgent: "grpc-go/1.34.0"}
+
+		mockSessRepo.EXPECT().UseRefreshToken(gomock.Any(), "refresh token").Return(token, nil)
+		mockSessRepo.EXPECT().GetSessionsValidAfter(gomock.Any(), token.UserID).Return(time.Time{}, nil)
+		mockSessRepo.EXPECT().DeleteByID(gomock.Any(), token.SessionID).Return(nil)
+		mockSessRepo.EXPECT().CreateSession(gomock.Any(), &models.Session{
+			UserID:      token.UserID,
+			FamilyID:    token.FamilyID,
+			IPAddress:   client.IPAddress,
+			UserAgent:   client.UserAgent,
+			LoginMethod: token.LoginMethod,
+		}, cfg.Session.Expire).Return("new session", nil)
+		mockSessRepo.EXPECT().CreateRefreshToken(gomock.Any(), &models.RefreshToken{
+			FamilyID:    token.FamilyID,
+			UserID:      token.UserID,
+			LoginMethod: token.LoginMethod,
+		}, cfg.Session.RefreshExpire).Return("new refresh token", nil)
+
+		sess, refreshToken, err := sessUC.RefreshSession(ctx, "refresh token", client)
+		require.NoError(t, err)
+		require.Equal(t, token.FamilyID, sess.FamilyID)
+		require.Equal(t, "new refresh token", refreshToken)
+	})
+
+	t.Run("Reused", func(t *testing.T) {
+		token := &models.RefreshToken{
+			FamilyID:  uuid.New().String(),
+			UserID:    uuid.New(),
+			SessionID: uuid.New().String(),
+			Reused:    true,
+		}
+
+		mockSessRepo.EXPECT().UseRefreshToken(gomock.Any(), "used refresh token").Return(token, nil)
+		mockSessRepo.EXPECT().DeleteRefreshFamily(gomock.Any(), token.FamilyID).Return(nil)
+		mockSessRepo.EXPECT().DeleteByUserID(gomock.Any(), token.UserID).Return(nil)
+
+		sess, refreshToken, err := sessUC.RefreshSession(ctx, "used refresh token", &models.ClientInfo{})
+		require.True(t, errors.Is(err, grpc_errors.ErrRefreshTokenUsed))
+		require.Nil(t, sess)
+		require.Equal(t, "", refreshToken)
+	})
+
+	t.Run("Max age exceeded", func(t *testing.T) {
+		sessUC := NewSessionUseCase(mockSessRepo, &config.Config{Session: config.Session{Expire: 10, MaxAge: 60}})
+		token := &models.RefreshToken{
+			FamilyID:        uuid.New().String(),
+			UserID:          uuid.New(),
+			SessionID:       uuid.New().String(),
+			AuthenticatedAt: time.Now().Add(-time.Hour),
+		}
+
+		mockSessRepo.EXPECT().UseRefreshToken(gomock.Any(), "old refresh token").Return(token, nil)
+		mockSessRepo.EXPECT().GetSessionsValidAfter(gomock.Any(), token.UserID).Return(time.Time{}, nil)
+		mockSessRepo.EXPECT().DeleteRefreshFamily(gomock.Any(), token.FamilyID).Return(nil)
+
+		sess, _, err := sessUC.RefreshSession(ctx, "old refresh token", &models.ClientInfo{})
+		require.True(t, errors.Is(err, grpc_errors.ErrSessionMaxAgeExceeded))
+		require.Nil(t, sess)
+	})
+}
+
+func TestSessionUC_Touch(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, &config.Config{Session: config.Session{Expire: 3600}})
+
+	ctx := context.Background()
+
+	t.Run("Stale", func(t *testing.T) {
+		lastSeenAt := time.Now().Add(-2 * lastSeenUpdateInterval)
+		sess := &models.Session{SessionID: uuid.New().String(), LastSeenAt: lastSeenAt}
+
+		mockSessRepo.EXPECT().TouchSession(gomock.Any(), sess).Return(nil)
+
+		err := sessUC.Touch(ctx, sess)
+		require.NoError(t, err)
+		require.True(t, sess.LastSeenAt.After(lastSeenAt))
+	})
+
+	t.Run("Recent", func(t *testing.T) {
+		sess := &models.Session{SessionID: uuid.New().String(), LastSeenAt: time.Now()}
+
+		err := sessUC.Touch(ctx, sess)
+		require.NoError(t, err)
+	})
+
+	t.Run("Sliding", func(t *testing.T) {
+		sessUC := NewSessionUseCase(mockSessRepo, &config.Config{Session: config.Session{
+			Expire:            3600,
+			SlidingExpiration: true,
+			MaxAge:            7200,
+		}})
+		expiresAt := time.Now().Add(time.Minute)
+		sess := &models.Session{
+			SessionID:       uuid.New().String(),
+			AuthenticatedAt: time.Now().Add(-time.Hour),
+			LastSeenAt:      time.Now().Add(-time.Hour),
+			ExpiresAt:       expiresAt,
+		}
+
+		mockSessRepo.EXPECT().TouchSession(gomock.Any(), sess).Return(nil)
+
+		err := sessUC.Touch(ctx, sess)
+		require.NoError(t, err)
+		require.True(t, sess.ExpiresAt.After(expiresAt.Add(50*time.Minute)))
+	})
+
+	t.Run("Sliding capped by max age", func(t *testing.T) {
+		sessUC := NewSessionUseCase(mockSessRepo, &config.Config{Session: config.Session{
+			Expire:            3600,
+			SlidingExpiration: true,
+			MaxAge:            7200,
+		}})
+		authenticatedAt := time.Now().Add(-110 * time.Minute)
+		sess := &models.Session{
+			SessionID:       uuid.New().String(),
+			AuthenticatedAt: authenticatedAt,
+			LastSeenAt:      time.Now().Add(-time.Hour),
+			ExpiresAt:       time.Now().Add(time.Minute),
+		}
+
+		mockSessRepo.EXPECT().TouchSession(gomock.Any(), sess).Return(nil)
+
+		err := sessUC.Touch(ctx, sess)
+		require.NoError(t, err)
+		require.False(t, sess.ExpiresAt.After(authenticatedAt.Add(7200*time.Second)))
+	})
+}
+
+func TestSessionUC_DeleteByPublicID(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, nil)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	sess := &models.Session{SessionID: uuid.New().String(), UserID: userID, FamilyID: uuid.New().String()}
+	other := &models.Session{SessionID: uuid.New().String(), UserID: userID, FamilyID: uuid.New().String()}
+
+	t.Run("DeleteByPublicID", func(t *testing.T) {
+		mockSessRepo.EXPECT().GetSessionsByUserID(gomock.Any(), userID).Return([]*models.Session{other, sess}, nil)
+		mockSessRepo.EXPECT().DeleteRefreshFamily(gomock.Any(), sess.FamilyID).Return(nil)
+		mockSessRepo.EXPECT().DeleteByID(gomock.Any(), sess.SessionID).Return(nil)
+
+		err := sessUC.DeleteByPublicID(ctx, userID, sess.PublicID())
+		require.NoError(t, err)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockSessRepo.EXPECT().GetSessionsByUserID(gomock.Any(), userID).Return([]*models.Session{other}, nil)
+
+		err := sessUC.DeleteByPublicID(ctx, userID, sess.SessionID)
+		require.True(t, errors.Is(err, grpc_errors.ErrSessionNotFound))
+	})
+}
+
+func TestSessionUC_RevokeUserSessions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, &config.Config{Session: config.Session{Expire: 10, RefreshExpire: 100}})
+
+	ctx := context.Background()
+	userID := uuid.New()
+	revokedAt := time.Now()
+
+	t.Run("RevokeUserSessions", func(t *testing.T) {
+		mockSessRepo.EXPECT().SetSessionsValidAfter(gomock.Any(), userID, gomock.Any()).Return(nil)
+		mockSessRepo.EXPECT().DeleteByUserID(gomock.Any(), userID).Return(nil)
+
+		err := sessUC.RevokeUserSessions(ctx, userID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Session missed by index", func(t *testing.T) {
+		sess := &models.Session{SessionID: uuid.New().String(), UserID: userID, AuthenticatedAt: revokedAt.Add(-time.Minute)}
+
+		mockSessRepo.EXPECT().GetSessionByID(gomock.Any(), sess.SessionID).Return(sess, nil)
+		mockSessRepo.EXPECT().GetSessionsValidAfter(gomock.Any(), userID).Return(revokedAt, nil)
+
+		session, err := sessUC.GetSessionByID(ctx, sess.SessionID)
+		require.True(t, errors.Is(err, redis.Nil))
+		require.Nil(t, session)
+	})
+
+	t.Run("Session after revocation", func(t *testing.T) {
+		sess := &models.Session{SessionID: uuid.New().String(), UserID: userID, AuthenticatedAt: revokedAt.Add(time.Minute)}
+
+		mockSessRepo.EXPECT().GetSessionByID(gomock.Any(), sess.SessionID).Return(sess, nil)
+		mockSessRepo.EXPECT().GetSessionsValidAfter(gomock.Any(), userID).Return(revokedAt, nil)
+
+		session, err := sessUC.GetSessionByID(ctx, sess.SessionID)
+		require.NoError(t, err)
+		require.Equal(t, sess, session)
+	})
+
+	t.Run("Refresh token issued before revocation", func(t *testing.T) {
+		token := &models.RefreshToken{
+			FamilyID:        uuid.New().String(),
+			UserID:          userID,
+			SessionID:       uuid.New().String(),
+			AuthenticatedAt: revokedAt.Add(-time.Minute),
+		}
+
+		mockSessRepo.EXPECT().UseRefreshToken(gomock.Any(), "revoked refresh token").Return(token, nil)
+		mockSessRepo.EXPECT().GetSessionsValidAfter(gomock.Any(), userID).Return(revokedAt, nil)
+		mockSessRepo.EXPECT().DeleteRefreshFamily(gomock.Any(), token.FamilyID).Return(nil)
+
+		sess, _, err := sessUC.RefreshSession(ctx, "revoked refresh token", &models.ClientInfo{})
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidToken))
+		require.Nil(t, sess)
+	})
+}
@@ -2,22 +2,54 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/AleksK1NG/auth-microservice/internal/interceptors"
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+	"github.com/AleksK1NG/auth-microservice/pkg/jwt"
 	"github.com/AleksK1NG/auth-microservice/pkg/utils"
 	userService "github.com/AleksK1NG/auth-microservice/proto"
 )
 
+const (
+	tokenTypeAccessToken = "access_token"
+	tokenTypeSessionID   = "session_id"
+
+	maxPermissionChecks = 100
+
+	updateMaskFirstName = "first_name"
+	updateMaskLastName  = "last_name"
+	updateMaskAvatar    = "avatar"
+	updateMaskRoles     = "roles"
+
+	loginMethodPassword     = "password"
+	loginMethodMFA          = "mfa"
+	loginMethodRecoveryCode = "recovery_code"
+	loginMethodPasskey      = "passkey"
+
+	exportChunkSize    = 64 * 1024
+	redactedAuditActor = "administrator"
+
+	maxSearchQueryLength = 64
+)
+
+// Audit metadata keys holding id of the user who performed the action
+var auditActorMetadataKeys = []string{"requested_by", "revoked_by", "deleted_by"}
+
 // Register new user
 func (u *usersService) Register(ctx context.Context, r *userService.RegisterRequest) (*userService.RegisterResponse, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "user.Create")
@@ -59,16 +91,63 @@ func (u *usersService) Login(ctx context.Context, r *userService.LoginRequest) (
 		u.logger.Errorf("userUC.Login: %v", err)
 		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "Login: %v", err)
 	}
+	// checked before the second factor, so unverified users don't get an MFA challenge
+	if err := u.checkEmailVerified(user); err != nil {
+		return nil, err
+	}
 
-	session, err := u.sessUC.CreateSession(ctx, &models.Session{
-		UserID: user.UserID,
-	}, u.cfg.Session.Expire)
+	mfaEnabled, err := u.mfaUC.IsEnabled(ctx, user.UserID)
 	if err != nil {
-		u.logger.Errorf("sessUC.CreateSession: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.CreateSession: %v", err)
+		u.logger.Errorf("mfaUC.IsEnabled: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "mfaUC.IsEnabled: %v", err)
+	}
+	if mfaEnabled {
+		challengeID, err := u.mfaUC.CreateChallenge(ctx, user.UserID)
+		if err != nil {
+			u.logger.Errorf("mfaUC.CreateChallenge: %v", err)
+			return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "mfaUC.CreateChallenge: %v", err)
+		}
+		return &userService.LoginResponse{MfaRequired: true, MfaChallengeId: challengeID}, nil
+	}
+
+	return u.loginResponse(ctx, user, loginMethodPassword)
+}
+
+// Exchange refresh token for a new session, access and refresh tokens
+func (u *usersService) RefreshSession(ctx context.Context, r *userService.RefreshSessionRequest) (*userService.RefreshSessionResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.RefreshSession")
+	defer span.Finish()
+
+	if r.GetRefreshToken() == "" {
+		u.logger.Errorf("RefreshSession: %v", grpc_errors.ErrInvalidToken)
+		return nil, status.Errorf(codes.InvalidArgument, "RefreshSession: %v", grpc_errors.ErrInvalidToken)
+	}
+
+	client := interceptors.ClientFromCtx(ctx, u.cfg.Server.TrustForwardedFor)
+	session, refreshToken, err := u.sessUC.RefreshSession(ctx, r.GetRefreshToken(), client)
+	if err != nil {
+		u.logger.Errorf("sessUC.RefreshSession: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.RefreshSession: %v", err)
+	}
+
+	user, err := u.userUC.FindById(ctx, session.UserID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	accessToken, expiresAt, err := u.generateAccessToken(user, session.SessionID)
+	if err != nil {
+		u.logger.Errorf("generateAccessToken: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "generateAccessToken: %v", err)
 	}
 
-	return &userService.LoginResponse{User: u.userModelToProto(user), SessionId: session}, err
+	return &userService.RefreshSessionResponse{
+		SessionId:            session.SessionID,
+		RefreshToken:         refreshToken,
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: expiresAt,
+	}, nil
 }
 
 // Find user by email address
@@ -111,33 +190,32 @@ func (u *usersService) FindByID(ctx context.Context, r *userService.FindByIDRequ
 	return &userService.FindByIDResponse{User: u.userModelToProto(user)}, nil
 }
 
-// Get session id from, ctx metadata, find user by uuid and returns it
+// Find authenticated user and returns it with the current session
 func (u *usersService) GetMe(ctx context.Context, r *userService.GetMeRequest) (*userService.GetMeResponse, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "user.Create")
 	defer span.Finish()
 
-	sessID, err := u.getSessionIDFromCtx(ctx)
+	principal, err := u.getPrincipalFromCtx(ctx)
 	if err != nil {
-		u.logger.Errorf("getSessionIDFromCtx: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.getSessionIDFromCtx: %v", err)
+		return nil, err
 	}
 
-	session, err := u.sessUC.GetSessionByID(ctx, sessID)
+	user, err := u.userUC.FindById(ctx, principal.UserID)
 	if err != nil {
-		u.logger.Errorf("sessUC.GetSessionByID: %v", err)
-		if errors.Is(err, redis.Nil) {
-			return nil, status.Errorf(codes.NotFound, "sessUC.GetSessionByID: %v", grpc_errors.ErrNotFound)
-		}
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.GetSessionByID: %v", err)
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
 	}
 
-	user, err := u.userUC.FindById(ctx, session.UserID)
+	session, err := u.sessUC.GetSessionByID(ctx, principal.SessionID)
 	if err != nil {
-		u.logger.Errorf("userUC.FindById: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+		u.logger.Errorf("sessUC.GetSessionByID: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.GetSessionByID: %v", err)
 	}
 
-	return &userService.GetMeResponse{User: u.userModelToProto(user)}, nil
+	return &userService.GetMeResponse{
+		User:    u.userModelToProto(user),
+		Session: u.sessionModelToProto(session, principal.SessionID),
+	}, nil
 }
 
 // Logout user, delete current session
@@ -145,63 +223,1492 @@ func (u *usersService) Logout(ctx context.Context, request *userService.LogoutRe
 	span, ctx := opentracing.StartSpanFromContext(ctx, "user.Create")
 	defer span.Finish()
 
-	sessID, err := u.getSessionIDFromCtx(ctx)
+	principal, err := u.getPrincipalFromCtx(ctx)
 	if err != nil {
-		u.logger.Errorf("getSessionIDFromCtx: %v", err)
 		return nil, err
 	}
 
-	if err := u.sessUC.DeleteByID(ctx, sessID); err != nil {
+	if err := u.sessUC.DeleteByID(ctx, principal.SessionID); err != nil {
 		u.logger.Errorf("sessUC.DeleteByID: %v", err)
 		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.DeleteByID: %v", err)
 	}
+	u.recordAuditEvent(ctx, principal.UserID, models.AuditEventLogout, nil)
 
 	return &userService.LogoutResponse{}, nil
 }
 
-func (u *usersService) registerReqToUserModel(r *userService.RegisterRequest) (*models.User, error) {
-	avatar := r.GetAvatar()
-	candidate := &models.User{
-		Email:     r.GetEmail(),
-		FirstName: r.GetFirstName(),
-		LastName:  r.GetLastName(),
-		Role:      r.GetRole(),
-		Avatar:    &avatar,
-		Password:  r.GetPassword(),
+// Generate new access tokens signing key, admin only
+func (u *usersService) RotateSigningKey(ctx context.Context, r *userService.RotateSigningKeyRequest) (*userService.RotateSigningKeyResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.RotateSigningKey")
+	defer span.Finish()
+
+	keyInfo, err := u.jwtManager.RotateSigningKey()
+	if err != nil {
+		u.logger.Errorf("jwtManager.RotateSigningKey: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "jwtManager.RotateSigningKey: %v", err)
 	}
 
-	if err := candidate.PrepareCreate(); err != nil {
+	return &userService.RotateSigningKeyResponse{Kid: keyInfo.Kid, Algorithm: keyInfo.Algorithm}, nil
+}
+
+// Introspect access token or session id, modelled after RFC 7662, inactive token is not an error
+func (u *usersService) IntrospectToken(ctx context.Context, r *userService.IntrospectTokenRequest) (*userService.IntrospectTokenResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.IntrospectToken")
+	defer span.Finish()
+
+	token := r.GetToken()
+	if token == "" {
+		u.logger.Errorf("IntrospectToken: %v", grpc_errors.ErrInvalidToken)
+		return nil, status.Errorf(codes.InvalidArgument, "IntrospectToken: %v", grpc_errors.ErrInvalidToken)
+	}
+
+	if r.GetTokenTypeHint() == tokenTypeSessionID || (r.GetTokenTypeHint() == "" && isSessionID(token)) {
+		session, user, err := u.findActiveSession(ctx, token)
+		if err != nil {
+			u.logger.Errorf("findActiveSession: %v", err)
+			return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "findActiveSession: %v", err)
+		}
+		if session == nil {
+			return &userService.IntrospectTokenResponse{Active: false}, nil
+		}
+
+		return &userService.IntrospectTokenResponse{
+			Active:    true,
+			TokenType: tokenTypeSessionID,
+			UserId:    user.UserID.String(),
+			Roles:     user.Roles,
+			SessionId: session.SessionID,
+			Scopes:    user.Permissions,
+			ExpiresAt: timestamppb.New(session.ExpiresAt),
+		}, nil
+	}
+
+	claims, err := u.jwtManager.VerifyAccessToken(token)
+	if err != nil {
+		u.logger.Debugf("jwtManager.VerifyAccessToken: %v", err)
+		return &userService.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	session, _, err := u.findActiveSession(ctx, claims.SessionID)
+	if err != nil {
+		u.logger.Errorf("findActiveSession: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "findActiveSession: %v", err)
+	}
+	if session == nil {
+		return &userService.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	return &userService.IntrospectTokenResponse{
+		Active:    true,
+		TokenType: tokenTypeAccessToken,
+		UserId:    claims.UserID,
+		Roles:     claims.Roles,
+		SessionId: claims.SessionID,
+		Scopes:    claims.Permissions,
+		IssuedAt:  timestamppb.New(claims.IssuedAt.Time),
+		ExpiresAt: timestamppb.New(claims.ExpiresAt.Time),
+	}, nil
+}
+
+// Check if session is active and returns its owner identity
+func (u *usersService) ValidateSession(ctx context.Context, r *userService.ValidateSessionRequest) (*userService.ValidateSessionResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.ValidateSession")
+	defer span.Finish()
+
+	if r.GetSessionId() == "" {
+		u.logger.Errorf("ValidateSession: %v", grpc_errors.ErrInvalidSessionId)
+		return nil, status.Errorf(codes.InvalidArgument, "ValidateSession: %v", grpc_errors.ErrInvalidSessionId)
+	}
+
+	session, user, err := u.findActiveSession(ctx, r.GetSessionId())
+	if err != nil {
+		u.logger.Errorf("findActiveSession: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "findActiveSession: %v", err)
+	}
+	if session == nil {
+		return &userService.ValidateSessionResponse{Active: false}, nil
+	}
+
+	return &userService.ValidateSessionResponse{
+		Active:    true,
+		UserId:    user.UserID.String(),
+		Roles:     user.Roles,
+		SessionId: session.SessionID,
+		Scopes:    user.Permissions,
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+	}, nil
+}
+
+// Create new role with initial permissions
+func (u *usersService) CreateRole(ctx context.Context, r *userService.CreateRoleRequest) (*userService.CreateRoleResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.CreateRole")
+	defer span.Finish()
+
+	candidate := &models.Role{Name: r.GetName(), Description: r.GetDescription(), Permissions: r.GetPermissions()}
+	candidate.PrepareCreate()
+	if err := utils.ValidateStruct(ctx, candidate); err != nil {
+		u.logger.Errorf("ValidateStruct: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "ValidateStruct: %v", err)
+	}
+
+	createdRole, err := u.roleUC.CreateRole(ctx, candidate)
+	if err != nil {
+		u.logger.Errorf("roleUC.CreateRole: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "roleUC.CreateRole: %v", err)
+	}
+
+	return &userService.CreateRoleResponse{Role: u.roleModelToProto(createdRole)}, nil
+}
+
+// Grant permission to role
+func (u *usersService) GrantPermission(ctx context.Context, r *userService.GrantPermissionRequest) (*userService.GrantPermissionResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.GrantPermission")
+	defer span.Finish()
+
+	roleName, permission := models.NormalizeAccessName(r.GetRole()), models.NormalizeAccessName(r.GetPermission())
+	if roleName == "" || permission == "" {
+		u.logger.Errorf("GrantPermission: empty role or permission")
+		return nil, status.Errorf(codes.InvalidArgument, "GrantPermission: empty role or permission")
+	}
+
+	updatedRole, err := u.roleUC.GrantPermission(ctx, roleName, permission)
+	if err != nil {
+		u.logger.Errorf("roleUC.GrantPermission: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "roleUC.GrantPermission: %v", err)
+	}
+
+	return &userService.GrantPermissionResponse{Role: u.roleModelToProto(updatedRole)}, nil
+}
+
+// Revoke permission from role
+func (u *usersService) RevokePermission(ctx context.Context, r *userService.RevokePermissionRequest) (*userService.RevokePermissionResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.RevokePermission")
+	defer span.Finish()
+
+	roleName, permission := models.NormalizeAccessName(r.GetRole()), models.NormalizeAccessName(r.GetPermission())
+	if roleName == "" || permission == "" {
+		u.logger.Errorf("RevokePermission: empty role or permission")
+		return nil, status.Errorf(codes.InvalidArgument, "RevokePermission: empty role or permission")
+	}
+
+	updatedRole, err := u.roleUC.RevokePermission(ctx, roleName, permission)
+	if err != nil {
+		u.logger.Errorf("roleUC.RevokePermission: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "roleUC.RevokePermission: %v", err)
+	}
+
+	return &userService.RevokePermissionResponse{Role: u.roleModelToProto(updatedRole)}, nil
+}
+
+// Assign role to user
+func (u *usersService) AssignRole(ctx context.Context, r *userService.AssignRoleRequest) (*userService.AssignRoleResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.AssignRole")
+	defer span.Finish()
+
+	userUUID, err := uuid.Parse(r.GetUserId())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "uuid.Parse: %v", err)
+	}
+
+	if _, err := u.userUC.FindById(ctx, userUUID); err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	if err := u.roleUC.AssignRole(ctx, userUUID, models.NormalizeAccessName(r.GetRole())); err != nil {
+		u.logger.Errorf("roleUC.AssignRole: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "roleUC.AssignRole: %v", err)
+	}
+
+	user, err := u.userUC.FindById(ctx, userUUID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	return &userService.AssignRoleResponse{User: u.userModelToProto(user)}, nil
+}
+
+// Unassign role from user
+func (u *usersService) UnassignRole(ctx context.Context, r *userService.UnassignRoleRequest) (*userService.UnassignRoleResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.UnassignRole")
+	defer span.Finish()
+
+	userUUID, err := uuid.Parse(r.GetUserId())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "uuid.Parse: %v", err)
+	}
+
+	if err := u.roleUC.UnassignRole(ctx, userUUID, models.NormalizeAccessName(r.GetRole())); err != nil {
+		u.logger.Errorf("roleUC.UnassignRole: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "roleUC.UnassignRole: %v", err)
+	}
+
+	user, err := u.userUC.FindById(ctx, userUUID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	return &userService.UnassignRoleResponse{User: u.userModelToProto(user)}, nil
+}
+
+// Check if user can perform action on resource
+func (u *usersService) CheckPermission(ctx context.Context, r *userService.CheckPermissionRequest) (*userService.CheckPermissionResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.CheckPermission")
+	defer span.Finish()
+
+	userUUID, err := uuid.Parse(r.GetUserId())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "uuid.Parse: %v", err)
+	}
+
+	permission, err := permissionFromCheck(r.GetResource(), r.GetAction())
+	if err != nil {
+		u.logger.Errorf("permissionFromCheck: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "permissionFromCheck: %v", err)
+	}
+
+	decision, err := u.roleUC.CheckPermission(ctx, userUUID, permission)
+	if err != nil {
+		u.logger.Errorf("roleUC.CheckPermission: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "roleUC.CheckPermission: %v", err)
+	}
+
+	return u.decisionModelToProto(decision), nil
+}
+
+// Check if user can perform every action on resource, decisions are returned in order of checks
+func (u *usersService) CheckPermissions(ctx context.Context, r *userService.CheckPermissionsRequest) (*userService.CheckPermissionsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.CheckPermissions")
+	defer span.Finish()
+
+	userUUID, err := uuid.Parse(r.GetUserId())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "uuid.Parse: %v", err)
+	}
+
+	if len(r.GetChecks()) == 0 || len(r.GetChecks()) > maxPermissionChecks {
+		u.logger.Errorf("CheckPermissions: invalid checks count: %d", len(r.GetChecks()))
+		return nil, status.Errorf(codes.InvalidArgument, "CheckPermissions: checks count must be from 1 to %d", maxPermissionChecks)
+	}
+
+	permissions := make([]string, 0, len(r.GetChecks()))
+	for _, check := range r.GetChecks() {
+		permission, err := permissionFromCheck(check.GetResource(), check.GetAction())
+		if err != nil {
+			u.logger.Errorf("permissionFromCheck: %v", err)
+			return nil, status.Errorf(codes.InvalidArgument, "permissionFromCheck: %v", err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	decisions, err := u.roleUC.CheckPermissions(ctx, userUUID, permissions)
+	if err != nil {
+		u.logger.Errorf("roleUC.CheckPermissions: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "roleUC.CheckPermissions: %v", err)
+	}
+
+	response := &userService.CheckPermissionsResponse{Allowed: true}
+	for _, decision := range decisions {
+		response.Decisions = append(response.Decisions, u.decisionModelToProto(decision))
+		response.Allowed = response.Allowed && decision.Allowed
+	}
+
+	return response, nil
+}
+
+// Start TOTP enrollment of current user, returns secret and otpauth uri for authenticator app
+func (u *usersService) EnableTOTP(ctx context.Context, r *userService.EnableTOTPRequest) (*userService.EnableTOTPResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.EnableTOTP")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	return candidate, nil
+	user, err := u.userUC.FindById(ctx, principal.UserID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	enrollment, err := u.mfaUC.EnableTOTP(ctx, user)
+	if err != nil {
+		u.logger.Errorf("mfaUC.EnableTOTP: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "mfaUC.EnableTOTP: %v", err)
+	}
+
+	return &userService.EnableTOTPResponse{Secret: enrollment.Secret, Uri: enrollment.URI}, nil
 }
 
-func (u *usersService) userModelToProto(user *models.User) *userService.User {
-	userProto := &userService.User{
-		Uuid:      user.UserID.String(),
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Password:  user.Password,
-		Email:     user.Email,
-		Role:      user.Role,
-		Avatar:    user.GetAvatar(),
-		CreatedAt: timestamppb.New(user.CreatedAt),
-		UpdatedAt: timestamppb.New(user.UpdatedAt),
+// Confirm TOTP enrollment of current user with code from authenticator app
+func (u *usersService) ConfirmTOTP(ctx context.Context, r *userService.ConfirmTOTPRequest) (*userService.ConfirmTOTPResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.ConfirmTOTP")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return userProto
+
+	if err := u.mfaUC.ConfirmTOTP(ctx, principal.UserID, r.GetCode()); err != nil {
+		u.logger.Errorf("mfaUC.ConfirmTOTP: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "mfaUC.ConfirmTOTP: %v", err)
+	}
+	u.recordAuditEvent(ctx, principal.UserID, models.AuditEventMFAEnabled, nil)
+
+	return &userService.ConfirmTOTPResponse{}, nil
 }
 
-func (u *usersService) getSessionIDFromCtx(ctx context.Context) (string, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return "", status.Errorf(codes.Unauthenticated, "metadata.FromIncomingContext: %v", grpc_errors.ErrNoCtxMetaData)
+// Disable TOTP of current user, requires current code
+func (u *usersService) DisableTOTP(ctx context.Context, r *userService.DisableTOTPRequest) (*userService.DisableTOTPResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.DisableTOTP")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.mfaUC.DisableTOTP(ctx, principal.UserID, r.GetCode()); err != nil {
+		u.logger.Errorf("mfaUC.DisableTOTP: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "mfaUC.DisableTOTP: %v", err)
+	}
+	u.recordAuditEvent(ctx, principal.UserID, models.AuditEventMFADisabled, nil)
+
+	return &userService.DisableTOTPResponse{}, nil
+}
+
+// Complete login of user with TOTP enabled, exchanges login challenge and code for session
+func (u *usersService) VerifyMFA(ctx context.Context, r *userService.VerifyMFARequest) (*userService.LoginResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.VerifyMFA")
+	defer span.Finish()
+
+	if r.GetChallengeId() == "" || r.GetCode() == "" {
+		u.logger.Errorf("VerifyMFA: %v", grpc_errors.ErrInvalidMFAChallenge)
+		return nil, status.Errorf(codes.InvalidArgument, "VerifyMFA: challenge_id and code are required")
+	}
+
+	userID, err := u.mfaUC.VerifyChallenge(ctx, r.GetChallengeId(), r.GetCode())
+	if err != nil {
+		u.logger.Errorf("mfaUC.VerifyChallenge: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "mfaUC.VerifyChallenge: %v", err)
+	}
+
+	user, err := u.userUC.FindById(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	return u.loginResponse(ctx, user, loginMethodMFA)
+}
+
+// Generate new recovery codes of current user, previous codes stop working
+func (u *usersService) GenerateRecoveryCodes(ctx context.Context, r *userService.GenerateRecoveryCodesRequest) (*userService.GenerateRecoveryCodesResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.GenerateRecoveryCodes")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := u.mfaUC.GenerateRecoveryCodes(ctx, principal.UserID)
+	if err != nil {
+		u.logger.Errorf("mfaUC.GenerateRecoveryCodes: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "mfaUC.GenerateRecoveryCodes: %v", err)
+	}
+
+	return &userService.GenerateRecoveryCodesResponse{Codes: codes}, nil
+}
+
+// Login with single-use recovery code instead of password and second factor
+func (u *usersService) LoginWithRecoveryCode(ctx context.Context, r *userService.LoginWithRecoveryCodeRequest) (*userService.LoginWithRecoveryCodeResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.LoginWithRecoveryCode")
+	defer span.Finish()
+
+	email := r.GetEmail()
+	if !utils.ValidateEmail(email) {
+		u.logger.Errorf("ValidateEmail: %v", email)
+		return nil, status.Errorf(codes.InvalidArgument, "ValidateEmail: %v", email)
+	}
+
+	user, err := u.userUC.FindByEmail(ctx, email)
+	if err != nil {
+		u.logger.Errorf("userUC.FindByEmail: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = grpc_errors.ErrInvalidRecoveryCode
+		}
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "LoginWithRecoveryCode: %v", err)
+	}
+	remaining, err := u.mfaUC.UseRecoveryCode(ctx, user.UserID, r.GetCode())
+	if err != nil {
+		u.logger.Errorf("mfaUC.UseRecoveryCode: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "LoginWithRecoveryCode: %v", err)
 	}
 
-	sessionID := md.Get("session_id")
-	if sessionID[0] == "" {
-		return "", status.Errorf(codes.PermissionDenied, "md.Get sessionId: %v", grpc_errors.ErrInvalidSessionId)
+	login, err := u.loginResponse(ctx, user, loginMethodRecoveryCode)
+	if err != nil {
+		return nil, err
 	}
 
-	return sessionID[0], nil
+	return &userService.LoginWithRecoveryCodeResponse{Login: login, RemainingCodes: int32(remaining)}, nil
+}
+
+// Start passkey registration of current user, returns options for navigator.credentials.create
+func (u *usersService) BeginPasskeyRegistration(ctx context.Context, r *userService.BeginPasskeyRegistrationRequest) (*userService.BeginPasskeyRegistrationResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.BeginPasskeyRegistration")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userUC.FindById(ctx, principal.UserID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	ceremony, err := u.passkeyUC.BeginRegistration(ctx, user)
+	if err != nil {
+		u.logger.Errorf("passkeyUC.BeginRegistration: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "passkeyUC.BeginRegistration: %v", err)
+	}
+
+	return &userService.BeginPasskeyRegistrationResponse{
+		ChallengeId:      ceremony.ChallengeID,
+		PublicKeyOptions: ceremony.Options,
+	}, nil
+}
+
+// Finish passkey registration of current user with credential created by authenticator
+func (u *usersService) FinishPasskeyRegistration(ctx context.Context, r *userService.FinishPasskeyRegistrationRequest) (*userService.FinishPasskeyRegistrationResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.FinishPasskeyRegistration")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.GetChallengeId() == "" || len(r.GetCredential()) == 0 {
+		u.logger.Errorf("FinishPasskeyRegistration: %v", grpc_errors.ErrInvalidPasskey)
+		return nil, status.Errorf(codes.InvalidArgument, "FinishPasskeyRegistration: challenge_id and credential are required")
+	}
+	if len(r.GetName()) > models.MaxPasskeyNameLength {
+		u.logger.Errorf("FinishPasskeyRegistration: name is too long")
+		return nil, status.Errorf(codes.InvalidArgument, "FinishPasskeyRegistration: name must be at most %d characters", models.MaxPasskeyNameLength)
+	}
+
+	user, err := u.userUC.FindById(ctx, principal.UserID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	createdPasskey, err := u.passkeyUC.FinishRegistration(ctx, user, r.GetChallengeId(), r.GetName(), r.GetCredential())
+	if err != nil {
+		u.logger.Errorf("passkeyUC.FinishRegistration: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "passkeyUC.FinishRegistration: %v", err)
+	}
+	u.recordAuditEvent(ctx, principal.UserID, models.AuditEventPasskeyRegistered, models.AuditMetadata{"name": createdPasskey.Name})
+
+	return &userService.FinishPasskeyRegistrationResponse{Passkey: u.passkeyModelToProto(createdPasskey)}, nil
+}
+
+// Start passkey login, returns options for navigator.credentials.get
+func (u *usersService) BeginPasskeyLogin(ctx context.Context, r *userService.BeginPasskeyLoginRequest) (*userService.BeginPasskeyLoginResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.BeginPasskeyLogin")
+	defer span.Finish()
+
+	email := r.GetEmail()
+	if !utils.ValidateEmail(email) {
+		u.logger.Errorf("ValidateEmail: %v", email)
+		return nil, status.Errorf(codes.InvalidArgument, "ValidateEmail: %v", email)
+	}
+
+	user, err := u.userUC.FindByEmail(ctx, email)
+	if err != nil {
+		u.logger.Errorf("userUC.FindByEmail: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = grpc_errors.ErrNoPasskeys
+		}
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "BeginPasskeyLogin: %v", err)
+	}
+
+	ceremony, err := u.passkeyUC.BeginLogin(ctx, user)
+	if err != nil {
+		u.logger.Errorf("passkeyUC.BeginLogin: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "BeginPasskeyLogin: %v", err)
+	}
+
+	return &userService.BeginPasskeyLoginResponse{
+		ChallengeId:      ceremony.ChallengeID,
+		PublicKeyOptions: ceremony.Options,
+	}, nil
+}
+
+// Finish passkey login with assertion signed by authenticator, passkey replaces both password and second factor
+func (u *usersService) FinishPasskeyLogin(ctx context.Context, r *userService.FinishPasskeyLoginRequest) (*userService.LoginResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.FinishPasskeyLogin")
+	defer span.Finish()
+
+	if r.GetChallengeId() == "" || len(r.GetCredential()) == 0 {
+		u.logger.Errorf("FinishPasskeyLogin: %v", grpc_errors.ErrInvalidPasskey)
+		return nil, status.Errorf(codes.InvalidArgument, "FinishPasskeyLogin: challenge_id and credential are required")
+	}
+
+	userID, err := u.passkeyUC.FinishLogin(ctx, r.GetChallengeId(), r.GetCredential())
+	if err != nil {
+		u.logger.Errorf("passkeyUC.FinishLogin: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "passkeyUC.FinishLogin: %v", err)
+	}
+
+	user, err := u.userUC.FindById(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	return u.loginResponse(ctx, user, loginMethodPasskey)
+}
+
+// Verify email address with token from verification email
+func (u *usersService) VerifyEmail(ctx context.Context, r *userService.VerifyEmailRequest) (*userService.VerifyEmailResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.VerifyEmail")
+	defer span.Finish()
+
+	if r.GetToken() == "" {
+		u.logger.Errorf("VerifyEmail: %v", grpc_errors.ErrInvalidToken)
+		return nil, status.Errorf(codes.InvalidArgument, "VerifyEmail: %v", grpc_errors.ErrInvalidToken)
+	}
+
+	user, err := u.userUC.VerifyEmail(ctx, r.GetToken())
+	if err != nil {
+		u.logger.Errorf("userUC.VerifyEmail: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.VerifyEmail: %v", err)
+	}
+
+	return &userService.VerifyEmailResponse{User: u.userModelToProto(user)}, nil
+}
+
+// Send new verification email, responds the same way for unknown and verified emails
+func (u *usersService) ResendVerification(ctx context.Context, r *userService.ResendVerificationRequest) (*userService.ResendVerificationResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.ResendVerification")
+	defer span.Finish()
+
+	email := r.GetEmail()
+	if !utils.ValidateEmail(email) {
+		u.logger.Errorf("ValidateEmail: %v", email)
+		return nil, status.Errorf(codes.InvalidArgument, "ValidateEmail: %v", email)
+	}
+
+	if err := u.userUC.ResendVerification(ctx, email); err != nil {
+		u.logger.Errorf("userUC.ResendVerification: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.ResendVerification: %v", err)
+	}
+
+	return &userService.ResendVerificationResponse{}, nil
+}
+
+// Send password reset email, responds the same way for unknown emails
+func (u *usersService) RequestPasswordReset(ctx context.Context, r *userService.RequestPasswordResetRequest) (*userService.RequestPasswordResetResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.RequestPasswordReset")
+	defer span.Finish()
+
+	email := r.GetEmail()
+	if !utils.ValidateEmail(email) {
+		u.logger.Errorf("ValidateEmail: %v", email)
+		return nil, status.Errorf(codes.InvalidArgument, "ValidateEmail: %v", email)
+	}
+
+	if err := u.userUC.RequestPasswordReset(ctx, email); err != nil {
+		u.logger.Errorf("userUC.RequestPasswordReset: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.RequestPasswordReset: %v", err)
+	}
+
+	return &userService.RequestPasswordResetResponse{}, nil
+}
+
+// Set new password with token from password reset email and revoke all sessions of the user
+func (u *usersService) ResetPassword(ctx context.Context, r *userService.ResetPasswordRequest) (*userService.ResetPasswordResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.ResetPassword")
+	defer span.Finish()
+
+	if r.GetToken() == "" {
+		u.logger.Errorf("ResetPassword: %v", grpc_errors.ErrInvalidToken)
+		return nil, status.Errorf(codes.InvalidArgument, "ResetPassword: %v", grpc_errors.ErrInvalidToken)
+	}
+	if err := utils.ValidatePassword(r.GetNewPassword()); err != nil {
+		u.logger.Errorf("ValidatePassword: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "ValidatePassword: %v", err)
+	}
+
+	user, err := u.userUC.ResetPassword(ctx, r.GetToken(), r.GetNewPassword())
+	if err != nil {
+		u.logger.Errorf("userUC.ResetPassword: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.ResetPassword: %v", err)
+	}
+	u.recordAuditEvent(ctx, user.UserID, models.AuditEventPasswordReset, nil)
+
+	if err := u.sessUC.DeleteByUserID(ctx, user.UserID); err != nil {
+		u.logger.Errorf("sessUC.DeleteByUserID: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.DeleteByUserID: %v", err)
+	}
+
+	return &userService.ResetPasswordResponse{}, nil
+}
+
+// Change password of current user, other sessions of the user are optionally revoked
+func (u *usersService) ChangePassword(ctx context.Context, r *userService.ChangePasswordRequest) (*userService.ChangePasswordResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.ChangePassword")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.ValidatePassword(r.GetNewPassword()); err != nil {
+		u.logger.Errorf("ValidatePassword: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "ValidatePassword: %v", err)
+	}
+
+	if err := u.userUC.ChangePassword(ctx, principal.UserID, r.GetOldPassword(), r.GetNewPassword()); err != nil {
+		u.logger.Errorf("userUC.ChangePassword: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.ChangePassword: %v", err)
+	}
+	u.recordAuditEvent(ctx, principal.UserID, models.AuditEventPasswordChanged, nil)
+
+	if r.GetRevokeOtherSessions() {
+		if err := u.sessUC.DeleteOtherSessions(ctx, principal.UserID, principal.SessionID); err != nil {
+			u.logger.Errorf("sessUC.DeleteOtherSessions: %v", err)
+			return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.DeleteOtherSessions: %v", err)
+		}
+	}
+
+	return &userService.ChangePasswordResponse{}, nil
+}
+
+// Update fields of user listed in update mask
+func (u *usersService) UpdateUser(ctx context.Context, r *userService.UpdateUserRequest) (*userService.UpdateUserResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.UpdateUser")
+	defer span.Finish()
+
+	userUUID, err := uuid.Parse(r.GetUuid())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "uuid.Parse: %v", err)
+	}
+
+	paths := r.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		u.logger.Errorf("UpdateUser: empty update mask")
+		return nil, status.Errorf(codes.InvalidArgument, "UpdateUser: empty update mask")
+	}
+
+	user, err := u.userUC.FindById(ctx, userUUID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	var updateProfile, updateRoles bool
+	for _, path := range paths {
+		switch path {
+		case updateMaskFirstName:
+			user.FirstName = strings.TrimSpace(r.GetFirstName())
+		case updateMaskLastName:
+			user.LastName = strings.TrimSpace(r.GetLastName())
+		case updateMaskAvatar:
+			avatar := r.GetAvatar()
+			user.Avatar = &avatar
+		case updateMaskRoles:
+			updateRoles = true
+			continue
+		default:
+			u.logger.Errorf("UpdateUser: unsupported update mask path: %s", path)
+			return nil, status.Errorf(codes.InvalidArgument, "UpdateUser: unsupported update mask path: %s", path)
+		}
+		updateProfile = true
+	}
+
+	if updateProfile {
+		if err := utils.ValidateStruct(ctx, user); err != nil {
+			u.logger.Errorf("ValidateStruct: %v", err)
+			return nil, status.Errorf(codes.InvalidArgument, "ValidateStruct: %v", err)
+		}
+		if _, err := u.userUC.Update(ctx, user); err != nil {
+			u.logger.Errorf("userUC.Update: %v", err)
+			return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.Update: %v", err)
+		}
+	}
+
+	if updateRoles {
+		if err := u.setUserRoles(ctx, user, r.GetRoles()); err != nil {
+			return nil, err
+		}
+	}
+
+	updatedUser, err := u.userUC.FindById(ctx, userUUID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindById: %v", err)
+	}
+
+	return &userService.UpdateUserResponse{User: u.userModelToProto(updatedUser)}, nil
+}
+
+// Send confirmation of email change to the new address of current user
+func (u *usersService) RequestEmailChange(ctx context.Context, r *userService.RequestEmailChangeRequest) (*userService.RequestEmailChangeResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.RequestEmailChange")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	email := r.GetNewEmail()
+	if !utils.ValidateEmail(email) {
+		u.logger.Errorf("ValidateEmail: %v", email)
+		return nil, status.Errorf(codes.InvalidArgument, "ValidateEmail: %v", email)
+	}
+
+	if err := u.userUC.RequestEmailChange(ctx, principal.UserID, email); err != nil {
+		u.logger.Errorf("userUC.RequestEmailChange: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.RequestEmailChange: %v", err)
+	}
+
+	return &userService.RequestEmailChangeResponse{}, nil
+}
+
+// Change email of user with token sent to the new address
+func (u *usersService) ConfirmEmailChange(ctx context.Context, r *userService.ConfirmEmailChangeRequest) (*userService.ConfirmEmailChangeResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.ConfirmEmailChange")
+	defer span.Finish()
+
+	if r.GetToken() == "" {
+		u.logger.Errorf("ConfirmEmailChange: %v", grpc_errors.ErrInvalidToken)
+		return nil, status.Errorf(codes.InvalidArgument, "ConfirmEmailChange: %v", grpc_errors.ErrInvalidToken)
+	}
+
+	user, err := u.userUC.ConfirmEmailChange(ctx, r.GetToken())
+	if err != nil {
+		u.logger.Errorf("userUC.ConfirmEmailChange: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.ConfirmEmailChange: %v", err)
+	}
+	u.recordAuditEvent(ctx, user.UserID, models.AuditEventEmailChanged, nil)
+
+	return &userService.ConfirmEmailChangeResponse{User: u.userModelToProto(user)}, nil
+}
+
+// Delete account of current user after password confirmation and revoke all its sessions
+func (u *usersService) DeleteAccount(ctx context.Context, r *userService.DeleteAccountRequest) (*userService.DeleteAccountResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.DeleteAccount")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userUC.DeleteAccount(ctx, principal.UserID, r.GetPassword()); err != nil {
+		u.logger.Errorf("userUC.DeleteAccount: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.DeleteAccount: %v", err)
+	}
+	u.recordAuditEvent(ctx, principal.UserID, models.AuditEventAccountDeleted, nil)
+	u.roleUC.InvalidateUser(ctx, principal.UserID)
+
+	if err := u.sessUC.DeleteByUserID(ctx, principal.UserID); err != nil {
+		u.logger.Errorf("sessUC.DeleteByUserID: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.DeleteByUserID: %v", err)
+	}
+
+	return &userService.DeleteAccountResponse{}, nil
+}
+
+// Delete user account and revoke all its sessions, own account must be deleted with DeleteAccount
+func (u *usersService) DeleteUser(ctx context.Context, r *userService.DeleteUserRequest) (*userService.DeleteUserResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.DeleteUser")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userUUID, err := uuid.Parse(r.GetUuid())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "uuid.Parse: %v", err)
+	}
+	if userUUID == principal.UserID {
+		u.logger.Errorf("DeleteUser: own account %s", userUUID)
+		return nil, status.Errorf(codes.InvalidArgument, "DeleteUser: use DeleteAccount to delete own account")
+	}
+
+	if err := u.userUC.Delete(ctx, userUUID); err != nil {
+		u.logger.Errorf("userUC.Delete: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.Delete: %v", err)
+	}
+	u.recordAuditEvent(ctx, userUUID, models.AuditEventAccountDeleted, models.AuditMetadata{
+		"deleted_by": principal.UserID.String(),
+	})
+	u.roleUC.InvalidateUser(ctx, userUUID)
+
+	if err := u.sessUC.DeleteByUserID(ctx, userUUID); err != nil {
+		u.logger.Errorf("sessUC.DeleteByUserID: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.DeleteByUserID: %v", err)
+	}
+
+	return &userService.DeleteUserResponse{}, nil
+}
+
+// Restore deleted user account during grace period
+func (u *usersService) RestoreUser(ctx context.Context, r *userService.RestoreUserRequest) (*userService.RestoreUserResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.RestoreUser")
+	defer span.Finish()
+
+	userUUID, err := uuid.Parse(r.GetUuid())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "uuid.Parse: %v", err)
+	}
+
+	user, err := u.userUC.Restore(ctx, userUUID)
+	if err != nil {
+		u.logger.Errorf("userUC.Restore: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.Restore: %v", err)
+	}
+	u.roleUC.InvalidateUser(ctx, userUUID)
+
+	return &userService.RestoreUserResponse{User: u.userModelToProto(user)}, nil
+}
+
+// List users page by page, filtered by role, status, creation time and email or name prefix
+func (u *usersService) ListUsers(ctx context.Context, r *userService.ListUsersRequest) (*userService.ListUsersResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.ListUsers")
+	defer span.Finish()
+
+	filter, err := listUsersReqToFilter(r)
+	if err != nil {
+		u.logger.Errorf("listUsersReqToFilter: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "listUsersReqToFilter: %v", err)
+	}
+
+	list, err := u.userUC.List(ctx, filter)
+	if err != nil {
+		u.logger.Errorf("userUC.List: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.List: %v", err)
+	}
+
+	response := &userService.ListUsersResponse{Users: make([]*userService.User, 0, len(list.Users))}
+	for _, user := range list.Users {
+		response.Users = append(response.Users, u.userModelToProto(user))
+	}
+	if list.NextCursor != nil {
+		response.NextPageToken = list.NextCursor.Encode()
+	}
+
+	return response, nil
+}
+
+// Search users by names and email, results are ranked by relevance
+func (u *usersService) SearchUsers(ctx context.Context, r *userService.SearchUsersRequest) (*userService.SearchUsersResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.SearchUsers")
+	defer span.Finish()
+
+	query := strings.TrimSpace(r.GetQuery())
+	if query == "" || len(query) > maxSearchQueryLength {
+		u.logger.Errorf("SearchUsers: invalid query: %s", query)
+		return nil, status.Errorf(codes.InvalidArgument, "SearchUsers: query must be 1 to %d characters", maxSearchQueryLength)
+	}
+	if r.GetPageSize() < 0 {
+		u.logger.Errorf("SearchUsers: negative page_size: %d", r.GetPageSize())
+		return nil, status.Errorf(codes.InvalidArgument, "SearchUsers: page_size must not be negative")
+	}
+
+	offset, err := decodeOffsetPageToken(r.GetPageToken())
+	if err != nil {
+		u.logger.Errorf("decodeOffsetPageToken: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "decodeOffsetPageToken: %v", err)
+	}
+
+	filter := &models.UserSearchFilter{Query: query, Limit: int(r.GetPageSize()), Offset: offset}
+	page, err := u.userUC.Search(ctx, filter)
+	if err != nil {
+		u.logger.Errorf("userUC.Search: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.Search: %v", err)
+	}
+
+	response := &userService.SearchUsersResponse{Results: make([]*userService.SearchUsersResult, 0, len(page.Results))}
+	for _, result := range page.Results {
+		response.Results = append(response.Results, &userService.SearchUsersResult{
+			User: u.userModelToProto(&result.User),
+			Rank: result.Rank,
+		})
+	}
+	if page.HasMore {
+		response.NextPageToken = encodeOffsetPageToken(filter.Offset + len(page.Results))
+	}
+
+	return response, nil
+}
+
+// List active sessions of current user, session ids are public ids which cannot be used as tokens
+func (u *usersService) ListMySessions(ctx context.Context, r *userService.ListMySessionsRequest) (*userService.ListMySessionsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.ListMySessions")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := u.sessUC.GetSessionsByUserID(ctx, principal.UserID)
+	if err != nil {
+		u.logger.Errorf("sessUC.GetSessionsByUserID: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.GetSessionsByUserID: %v", err)
+	}
+
+	response := &userService.ListMySessionsResponse{Sessions: make([]*userService.ActiveSession, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, u.sessionModelToProto(session, principal.SessionID))
+	}
+
+	return response, nil
+}
+
+// Revoke session of current user by public id from ListMySessions
+func (u *usersService) RevokeSession(ctx context.Context, r *userService.RevokeSessionRequest) (*userService.RevokeSessionResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.RevokeSession")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.GetSessionId() == "" {
+		u.logger.Errorf("RevokeSession: %v", grpc_errors.ErrSessionNotFound)
+		return nil, status.Errorf(codes.InvalidArgument, "RevokeSession: session_id is required")
+	}
+
+	if err := u.sessUC.DeleteByPublicID(ctx, principal.UserID, r.GetSessionId()); err != nil {
+		u.logger.Errorf("sessUC.DeleteByPublicID: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.DeleteByPublicID: %v", err)
+	}
+	u.recordAuditEvent(ctx, principal.UserID, models.AuditEventSessionRevoked, models.AuditMetadata{"session_id": r.GetSessionId()})
+
+	return &userService.RevokeSessionResponse{}, nil
+}
+
+// Revoke every session of current user except the one making the request
+func (u *usersService) RevokeAllOtherSessions(ctx context.Context, r *userService.RevokeAllOtherSessionsRequest) (*userService.RevokeAllOtherSessionsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.RevokeAllOtherSessions")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.sessUC.DeleteOtherSessions(ctx, principal.UserID, principal.SessionID); err != nil {
+		u.logger.Errorf("sessUC.DeleteOtherSessions: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.DeleteOtherSessions: %v", err)
+	}
+	u.recordAuditEvent(ctx, principal.UserID, models.AuditEventSessionsRevoked, nil)
+
+	return &userService.RevokeAllOtherSessionsResponse{}, nil
+}
+
+// Log user out of every device, sessions and tokens issued before the call are rejected even if they were missed by the index
+func (u *usersService) RevokeUserSessions(ctx context.Context, r *userService.RevokeUserSessionsRequest) (*userService.RevokeUserSessionsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "user.RevokeUserSessions")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userUUID, err := uuid.Parse(r.GetUserId())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "uuid.Parse: %v", err)
+	}
+
+	if err := u.sessUC.RevokeUserSessions(ctx, userUUID); err != nil {
+		u.logger.Errorf("sessUC.RevokeUserSessions: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.RevokeUserSessions: %v", err)
+	}
+	u.recordAuditEvent(ctx, userUUID, models.AuditEventAllSessionsRevoked, models.AuditMetadata{
+		"revoked_by": principal.UserID.String(),
+	})
+
+	return &userService.RevokeUserSessionsResponse{}, nil
+}
+
+// Stream JSON archive of everything stored about current user
+func (u *usersService) ExportMyData(r *userService.ExportMyDataRequest, stream userService.UserService_ExportMyDataServer) error {
+	span, ctx := opentracing.StartSpanFromContext(stream.Context(), "user.ExportMyData")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+
+	return u.exportUserData(ctx, principal, principal.UserID, false, stream)
+}
+
+// Stream JSON archive of everything stored about user, admin only
+func (u *usersService) ExportUserData(r *userService.ExportUserDataRequest, stream userService.UserService_ExportUserDataServer) error {
+	span, ctx := opentracing.StartSpanFromContext(stream.Context(), "user.ExportUserData")
+	defer span.Finish()
+
+	principal, err := u.getPrincipalFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+
+	userUUID, err := uuid.Parse(r.GetUuid())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return status.Errorf(codes.InvalidArgument, "uuid.Parse: %v", err)
+	}
+
+	// accounts deleted during grace period can still be exported
+	return u.exportUserData(ctx, principal, userUUID, true, stream)
+}
+
+// Collect data export of user and send it to stream in chunks, export is recorded to audit log of the user
+func (u *usersService) exportUserData(
+	ctx context.Context,
+	principal *models.Principal,
+	userID uuid.UUID,
+	includeDeleted bool,
+	stream interface {
+		Send(*userService.ExportDataChunk) error
+	},
+) error {
+	export, err := u.buildDataExport(ctx, userID, includeDeleted)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		u.logger.Errorf("json.Marshal: %v", err)
+		return status.Errorf(codes.Internal, "json.Marshal: %v", err)
+	}
+
+	for start := 0; start < len(data); start += exportChunkSize {
+		end := start + exportChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := stream.Send(&userService.ExportDataChunk{Data: data[start:end]}); err != nil {
+			u.logger.Errorf("stream.Send: %v", err)
+			return err
+		}
+	}
+
+	u.recordAuditEvent(ctx, userID, models.AuditEventDataExported, models.AuditMetadata{"requested_by": principal.UserID.String()})
+
+	return nil
+}
+
+// Collect profile, active sessions, MFA enrollment, passkeys and audit log of user
+func (u *usersService) buildDataExport(ctx context.Context, userID uuid.UUID, includeDeleted bool) (*models.UserDataExport, error) {
+	findUser := u.userUC.FindById
+	if includeDeleted {
+		findUser = u.userUC.FindByIdUnscoped
+	}
+	user, err := findUser(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("findUser: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "findUser: %v", err)
+	}
+	user.SanitizePassword()
+
+	sessions, err := u.sessUC.GetSessionsByUserID(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("sessUC.GetSessionsByUserID: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.GetSessionsByUserID: %v", err)
+	}
+
+	mfaStatus, err := u.mfaUC.GetStatus(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("mfaUC.GetStatus: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "mfaUC.GetStatus: %v", err)
+	}
+
+	passkeys, err := u.passkeyUC.List(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("passkeyUC.List: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "passkeyUC.List: %v", err)
+	}
+
+	events, err := u.auditUC.FindByUserID(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("auditUC.FindByUserID: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "auditUC.FindByUserID: %v", err)
+	}
+
+	export := &models.UserDataExport{
+		ExportedAt:   time.Now().UTC(),
+		Profile:      user,
+		Sessions:     make([]*models.ExportSession, 0, len(sessions)),
+		MFA:          mfaStatus,
+		Passkeys:     passkeys,
+		LoginHistory: make([]*models.AuditEvent, 0),
+		AuditEvents:  make([]*models.AuditEvent, 0, len(events)),
+	}
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, &models.ExportSession{
+			IPAddress:   session.IPAddress,
+			UserAgent:   session.UserAgent,
+			LoginMethod: session.LoginMethod,
+			CreatedAt:   session.CreatedAt,
+			LastSeenAt:  session.LastSeenAt,
+			ExpiresAt:   session.ExpiresAt,
+		})
+	}
+	for _, event := range events {
+		redactAuditActors(event, userID)
+		if event.Type == models.AuditEventLogin {
+			export.LoginHistory = append(export.LoginHistory, event)
+		} else {
+			export.AuditEvents = append(export.AuditEvents, event)
+		}
+	}
+
+	return export, nil
+}
+
+// Replace ids of other users who acted on the user, e.g. administrators, in audit event metadata
+func redactAuditActors(event *models.AuditEvent, userID uuid.UUID) {
+	for _, key := range auditActorMetadataKeys {
+		if actorID, ok := event.Metadata[key]; ok && actorID != userID.String() {
+			event.Metadata[key] = redactedAuditActor
+		}
+	}
+}
+
+// Assign and unassign roles so user has exactly the given ones
+func (u *usersService) setUserRoles(ctx context.Context, user *models.User, roles []string) error {
+	wanted := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		wanted[models.NormalizeAccessName(role)] = true
+	}
+	current := make(map[string]bool, len(user.Roles))
+	for _, role := range user.Roles {
+		current[role] = true
+	}
+
+	for role := range wanted {
+		if current[role] {
+			continue
+		}
+		if err := u.roleUC.AssignRole(ctx, user.UserID, role); err != nil {
+			u.logger.Errorf("roleUC.AssignRole: %v", err)
+			return status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "roleUC.AssignRole: %v", err)
+		}
+	}
+	for role := range current {
+		if wanted[role] {
+			continue
+		}
+		if err := u.roleUC.UnassignRole(ctx, user.UserID, role); err != nil {
+			u.logger.Errorf("roleUC.UnassignRole: %v", err)
+			return status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "roleUC.UnassignRole: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (u *usersService) registerReqToUserModel(r *userService.RegisterRequest) (*models.User, error) {
+	avatar := r.GetAvatar()
+	candidate := &models.User{
+		Email:     r.GetEmail(),
+		FirstName: r.GetFirstName(),
+		LastName:  r.GetLastName(),
+		Avatar:    &avatar,
+		Password:  r.GetPassword(),
+	}
+
+	if err := candidate.PrepareCreate(); err != nil {
+		return nil, err
+	}
+
+	return candidate, nil
+}
+
+func (u *usersService) userModelToProto(user *models.User) *userService.User {
+	userProto := &userService.User{
+		Uuid:          user.UserID.String(),
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Email:         user.Email,
+		Avatar:        user.GetAvatar(),
+		CreatedAt:     timestamppb.New(user.CreatedAt),
+		UpdatedAt:     timestamppb.New(user.UpdatedAt),
+		Roles:         user.Roles,
+		Permissions:   user.Permissions,
+		EmailVerified: user.EmailVerified,
+	}
+	return userProto
+}
+
+func (u *usersService) roleModelToProto(role *models.Role) *userService.Role {
+	return &userService.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		CreatedAt:   timestamppb.New(role.CreatedAt),
+	}
+}
+
+func (u *usersService) passkeyModelToProto(passkey *models.Passkey) *userService.Passkey {
+	return &userService.Passkey{
+		CredentialId: passkey.CredentialID,
+		Name:         passkey.Name,
+		CreatedAt:    timestamppb.New(passkey.CreatedAt),
+	}
+}
+
+func (u *usersService) sessionModelToProto(session *models.Session, currentSessionID string) *userService.ActiveSession {
+	return &userService.ActiveSession{
+		SessionId:   session.PublicID(),
+		CreatedAt:   timestamppb.New(session.CreatedAt),
+		ExpiresAt:   timestamppb.New(session.ExpiresAt),
+		Current:     session.SessionID == currentSessionID,
+		IpAddress:   session.IPAddress,
+		UserAgent:   session.UserAgent,
+		LoginMethod: session.LoginMethod,
+		LastSeenAt:  timestamppb.New(session.LastSeenAt),
+	}
+}
+
+func (u *usersService) decisionModelToProto(decision *models.PermissionDecision) *userService.CheckPermissionResponse {
+	return &userService.CheckPermissionResponse{
+		Allowed:    decision.Allowed,
+		Reason:     decision.Reason,
+		Permission: decision.Permission,
+	}
+}
+
+// Create session, refresh and access tokens of authenticated user and record login with its method and client.
+// Users with unverified email are rejected here, so the check covers every login method.
+func (u *usersService) loginResponse(ctx context.Context, user *models.User, method string) (*userService.LoginResponse, error) {
+	if err := u.checkEmailVerified(user); err != nil {
+		return nil, err
+	}
+
+	client := interceptors.ClientFromCtx(ctx, u.cfg.Server.TrustForwardedFor)
+	sess := &models.Session{
+		UserID:      user.UserID,
+		IPAddress:   client.IPAddress,
+		UserAgent:   client.UserAgent,
+		LoginMethod: method,
+	}
+	session, err := u.sessUC.CreateSession(ctx, sess, user.Roles, u.cfg.Session.Expire)
+	if err != nil {
+		u.logger.Errorf("sessUC.CreateSession: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.CreateSession: %v", err)
+	}
+
+	refreshToken, err := u.sessUC.CreateRefreshToken(ctx, sess)
+	if err != nil {
+		u.logger.Errorf("sessUC.CreateRefreshToken: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.CreateRefreshToken: %v", err)
+	}
+
+	accessToken, expiresAt, err := u.generateAccessToken(user, session)
+	if err != nil {
+		u.logger.Errorf("generateAccessToken: %v", err)
+		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "generateAccessToken: %v", err)
+	}
+
+	loginMetadata := models.AuditMetadata{"method": method}
+	if client.IPAddress != "" {
+		loginMetadata["ip_address"] = client.IPAddress
+	}
+	if client.UserAgent != "" {
+		loginMetadata["user_agent"] = client.UserAgent
+	}
+	u.recordAuditEvent(ctx, user.UserID, models.AuditEventLogin, loginMetadata)
+
+	return &userService.LoginResponse{
+		User:                 u.userModelToProto(user),
+		SessionId:            session,
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: expiresAt,
+		RefreshToken:         refreshToken,
+	}, nil
+}
+
+// Reject login of user with unverified email if EmailVerification.Required, every login method must check it
+func (u *usersService) checkEmailVerified(user *models.User) error {
+	if u.cfg.EmailVerification.Required && !user.EmailVerified {
+		u.logger.Errorf("checkEmailVerified: %v", grpc_errors.ErrEmailNotVerified)
+		return status.Errorf(grpc_errors.ParseGRPCErrStatusCode(grpc_errors.ErrEmailNotVerified), "checkEmailVerified: %v", grpc_errors.ErrEmailNotVerified)
+	}
+	return nil
+}
+
+// Sign access token for the user session if access tokens are enabled
+func (u *usersService) generateAccessToken(user *models.User, sessionID string) (string, *timestamppb.Timestamp, error) {
+	if !u.cfg.Jwt.AccessTokenEnabled {
+		return "", nil, nil
+	}
+
+	claims := &jwt.Claims{
+		UserID:      user.UserID.String(),
+		Roles:       user.Roles,
+		Permissions: user.Permissions,
+		SessionID:   sessionID,
+	}
+	accessToken, err := u.jwtManager.GenerateAccessToken(claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return accessToken, timestamppb.New(claims.ExpiresAt.Time), nil
+}
+
+// Find session and its user, returns nil session if it does not exist or expired
+func (u *usersService) findActiveSession(ctx context.Context, sessionID string) (*models.Session, *models.User, error) {
+	session, err := u.sessUC.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	user, err := u.userUC.FindById(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	return session, user, nil
+}
+
+// Record audit event of user, failure is logged and does not fail the request
+func (u *usersService) recordAuditEvent(ctx context.Context, userID uuid.UUID, eventType string, metadata models.AuditMetadata) {
+	if err := u.auditUC.Record(ctx, userID, eventType, metadata); err != nil {
+		u.logger.Errorf("auditUC.Record: %s: %v", eventType, err)
+	}
+}
+
+// Get authenticated caller put to ctx by auth interceptor
+func (u *usersService) getPrincipalFromCtx(ctx context.Context) (*models.Principal, error) {
+	principal, ok := interceptors.PrincipalFromCtx(ctx)
+	if !ok {
+		u.logger.Errorf("PrincipalFromCtx: %v", grpc_errors.ErrUnauthenticated)
+		return nil, status.Errorf(codes.Unauthenticated, "PrincipalFromCtx: %v", grpc_errors.ErrUnauthenticated)
+	}
+	return principal, nil
+}
+
+// Validate list users request and convert it to filter
+func listUsersReqToFilter(r *userService.ListUsersRequest) (*models.UserListFilter, error) {
+	if r.GetPageSize() < 0 {
+		return nil, errors.New("page_size must not be negative")
+	}
+
+	filter := &models.UserListFilter{
+		Role:   models.NormalizeAccessName(r.GetRole()),
+		Prefix: strings.TrimSpace(r.GetPrefix()),
+		Limit:  int(r.GetPageSize()),
+	}
+
+	switch r.GetStatus() {
+	case "", models.UserStatusActive, models.UserStatusUnverified, models.UserStatusDeleted:
+		filter.Status = r.GetStatus()
+	default:
+		return nil, errors.Errorf("unsupported status: %s", r.GetStatus())
+	}
+
+	switch r.GetSortOrder() {
+	case "", models.SortOrderAsc, models.SortOrderDesc:
+		filter.SortOrder = r.GetSortOrder()
+	default:
+		return nil, errors.Errorf("unsupported sort_order: %s", r.GetSortOrder())
+	}
+
+	if r.GetCreatedAfter() != nil {
+		createdAfter := r.GetCreatedAfter().AsTime()
+		filter.CreatedAfter = &createdAfter
+	}
+	if r.GetCreatedBefore() != nil {
+		createdBefore := r.GetCreatedBefore().AsTime()
+		filter.CreatedBefore = &createdBefore
+	}
+
+	if r.GetPageToken() != "" {
+		cursor, err := models.DecodeUserListCursor(r.GetPageToken())
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid page_token")
+		}
+		filter.Cursor = cursor
+	}
+
+	return filter, nil
+}
+
+// Encode offset of next page to opaque page token
+func encodeOffsetPageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// Decode page token created by encodeOffsetPageToken, empty token is the first page
+func decodeOffsetPageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid page_token")
+	}
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid page_token")
+	}
+
+	return offset, nil
+}
+
+// Build permission name from checked resource and action
+func permissionFromCheck(resource string, action string) (string, error) {
+	resource, action = models.NormalizeAccessName(resource), models.NormalizeAccessName(action)
+	if resource == "" || action == "" {
+		return "", errors.New("resource and action are required")
+	}
+	if strings.Contains(resource, ":") {
+		return "", errors.Errorf("invalid resource: %s", resource)
+	}
+	return models.PermissionName(resource, action), nil
+}
+
+func isSessionID(token string) bool {
+	_, err := uuid.Parse(token)
+	return err == nil
 }
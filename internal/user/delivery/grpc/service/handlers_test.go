@@ -1,17 +1,36 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	"github.com/AleksK1NG/auth-microservice/config"
+	mockAuditUC "github.com/AleksK1NG/auth-microservice/internal/audit/mock"
+	"github.com/AleksK1NG/auth-microservice/internal/interceptors"
+	mockMfaUC "github.com/AleksK1NG/auth-microservice/internal/mfa/mock"
 	"github.com/AleksK1NG/auth-microservice/internal/models"
+	mockPasskeyUC "github.com/AleksK1NG/auth-microservice/internal/passkey/mock"
+	mockRoleUC "github.com/AleksK1NG/auth-microservice/internal/role/mock"
 	mockSessUC "github.com/AleksK1NG/auth-microservice/internal/session/mock"
 	"github.com/AleksK1NG/auth-microservice/internal/user/mock"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+	"github.com/AleksK1NG/auth-microservice/pkg/jwt"
 	"github.com/AleksK1NG/auth-microservice/pkg/logger"
 	userService "github.com/AleksK1NG/auth-microservice/proto"
 )
@@ -24,14 +43,13 @@ func TestUsersService_Register(t *testing.T) {
 	userUC := mock.NewMockUserUseCase(ctrl)
 	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
 	apiLogger := logger.NewAPILogger(nil)
-	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC, nil, nil, nil, nil, nil)
 
 	reqValue := &userService.RegisterRequest{
 		Email:     "email@gmail.com",
 		FirstName: "FirstName",
 		LastName:  "LastName",
 		Password:  "Password",
-		Role:      "user",
 		Avatar:    "",
 	}
 
@@ -44,7 +62,7 @@ func TestUsersService_Register(t *testing.T) {
 			FirstName: reqValue.FirstName,
 			LastName:  reqValue.LastName,
 			Password:  reqValue.Password,
-			Role:      reqValue.Role,
+			Roles:     []string{models.RoleUser},
 			Avatar:    nil,
 		}
 
@@ -64,11 +82,15 @@ func TestUsersService_Login(t *testing.T) {
 	defer ctrl.Finish()
 	userUC := mock.NewMockUserUseCase(ctrl)
 	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
-	apiLogger := logger.NewAPILogger(nil)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	mfaUC := mockMfaUC.NewMockMfaUseCase(ctrl)
 	cfg := &config.Config{Session: config.Session{
 		Expire: 10,
 	}}
-	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC)
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	auditUC.EXPECT().Record(gomock.Any(), gomock.Any(), models.AuditEventLogin, gomock.Any()).Return(nil).AnyTimes()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC, nil, mfaUC, nil, auditUC, nil)
 
 	reqValue := &userService.LoginRequest{
 		Email:    "email@gmail.com",
@@ -85,19 +107,64 @@ func TestUsersService_Login(t *testing.T) {
 			FirstName: "FirstName",
 			LastName:  "LastName",
 			Password:  "Password",
-			Role:      "user",
+			Roles:     []string{models.RoleUser},
 			Avatar:    nil,
 		}
 
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51234}})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("user-agent", "grpc-go/1.34.0"))
+		expectedSession := &models.Session{
+			UserID:      user.UserID,
+			IPAddress:   "203.0.113.7",
+			UserAgent:   "grpc-go/1.34.0",
+			LoginMethod: loginMethodPassword,
+		}
+
 		userUC.EXPECT().Login(gomock.Any(), reqValue.Email, reqValue.Password).Return(user, nil)
-		sessUC.EXPECT().CreateSession(gomock.Any(), &models.Session{
-			UserID: user.UserID,
-		}, cfg.Session.Expire).Return(session, nil)
+		mfaUC.EXPECT().IsEnabled(gomock.Any(), userID).Return(false, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), expectedSession, user.Roles, cfg.Session.Expire).Return(session, nil)
+		sessUC.EXPECT().CreateRefreshToken(gomock.Any(), expectedSession).Return("refresh token", nil)
 
-		response, err := authServerGRPC.Login(context.Background(), reqValue)
+		response, err := authServerGRPC.Login(ctx, reqValue)
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Equal(t, reqValue.Email, response.User.Email)
+		require.Empty(t, response.User.Password)
+		require.Equal(t, "refresh token", response.RefreshToken)
+		require.False(t, response.MfaRequired)
+	})
+
+	t.Run("MFA required", func(t *testing.T) {
+		t.Parallel()
+		userID := uuid.New()
+		user := &models.User{UserID: userID, Email: "mfa@gmail.com", Roles: []string{models.RoleUser}}
+		req := &userService.LoginRequest{Email: user.Email, Password: "Password"}
+
+		userUC.EXPECT().Login(gomock.Any(), req.Email, req.Password).Return(user, nil)
+		mfaUC.EXPECT().IsEnabled(gomock.Any(), userID).Return(true, nil)
+		mfaUC.EXPECT().CreateChallenge(gomock.Any(), userID).Return("challenge", nil)
+
+		response, err := authServerGRPC.Login(context.Background(), req)
+		require.NoError(t, err)
+		require.True(t, response.MfaRequired)
+		require.Equal(t, "challenge", response.MfaChallengeId)
+		require.Empty(t, response.SessionId)
+		require.Nil(t, response.User)
+	})
+
+	t.Run("Session limit reached", func(t *testing.T) {
+		t.Parallel()
+		userID := uuid.New()
+		user := &models.User{UserID: userID, Email: "limited@gmail.com", Roles: []string{models.RoleAdmin}}
+		req := &userService.LoginRequest{Email: user.Email, Password: "Password"}
+
+		userUC.EXPECT().Login(gomock.Any(), req.Email, req.Password).Return(user, nil)
+		mfaUC.EXPECT().IsEnabled(gomock.Any(), userID).Return(false, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), gomock.Any(), user.Roles, cfg.Session.Expire).Return("", grpc_errors.ErrSessionLimitReached)
+
+		response, err := authServerGRPC.Login(context.Background(), req)
+		require.Nil(t, response)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
 	})
 }
 
@@ -109,10 +176,11 @@ func TestUsersService_FindByEmail(t *testing.T) {
 	userUC := mock.NewMockUserUseCase(ctrl)
 	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
 	apiLogger := logger.NewAPILogger(nil)
+	mfaUC := mockMfaUC.NewMockMfaUseCase(ctrl)
 	cfg := &config.Config{Session: config.Session{
 		Expire: 10,
 	}}
-	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC, nil, mfaUC, nil, nil, nil)
 
 	reqValue := &userService.FindByEmailRequest{
 		Email: "email@gmail.com",
@@ -127,7 +195,7 @@ func TestUsersService_FindByEmail(t *testing.T) {
 			FirstName: "FirstName",
 			LastName:  "LastName",
 			Password:  "Password",
-			Role:      "user",
+			Roles:     []string{models.RoleUser},
 			Avatar:    nil,
 		}
 
@@ -139,3 +207,1269 @@ func TestUsersService_FindByEmail(t *testing.T) {
 		require.Equal(t, reqValue.Email, response.User.Email)
 	})
 }
+
+func TestUsersService_LoginAccessToken(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	cfg := &config.Config{
+		Session: config.Session{Expire: 10},
+		Jwt: config.Jwt{
+			AccessTokenEnabled: true,
+			AccessTokenExpire:  60,
+			Issuer:             "auth",
+			SigningAlgorithm:   jwt.AlgorithmES256,
+			KeysDir:            t.TempDir(),
+		},
+	}
+	jwtManager, err := jwt.NewJwtManager(apiLogger, cfg)
+	require.NoError(t, err)
+	mfaUC := mockMfaUC.NewMockMfaUseCase(ctrl)
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	auditUC.EXPECT().Record(gomock.Any(), gomock.Any(), models.AuditEventLogin, gomock.Any()).Return(nil).AnyTimes()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC, nil, mfaUC, nil, auditUC, jwtManager)
+
+	reqValue := &userService.LoginRequest{
+		Email:    "email@gmail.com",
+		Password: "Password",
+	}
+
+	t.Run("LoginAccessToken", func(t *testing.T) {
+		t.Parallel()
+		userID := uuid.New()
+		session := "session"
+		user := &models.User{
+			UserID:    userID,
+			Email:     "email@gmail.com",
+			FirstName: "FirstName",
+			LastName:  "LastName",
+			Password:  "Password",
+			Roles:     []string{models.RoleUser},
+			Avatar:    nil,
+		}
+
+		userUC.EXPECT().Login(gomock.Any(), reqValue.Email, reqValue.Password).Return(user, nil)
+		mfaUC.EXPECT().IsEnabled(gomock.Any(), userID).Return(false, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), &models.Session{
+			UserID:      user.UserID,
+			LoginMethod: loginMethodPassword,
+		}, user.Roles, cfg.Session.Expire).Return(session, nil)
+		sessUC.EXPECT().CreateRefreshToken(gomock.Any(), &models.Session{
+			UserID:      user.UserID,
+			LoginMethod: loginMethodPassword,
+		}).Return("refresh token", nil)
+
+		response, err := authServerGRPC.Login(context.Background(), reqValue)
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		require.NotEqual(t, response.AccessToken, "")
+
+		claims, err := jwtManager.VerifyAccessToken(response.AccessToken)
+		require.NoError(t, err)
+		require.Equal(t, userID.String(), claims.UserID)
+		require.Equal(t, user.Roles, claims.Roles)
+		require.Equal(t, session, claims.SessionID)
+	})
+}
+
+func TestUsersService_RefreshSession(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	mfaUC := mockMfaUC.NewMockMfaUseCase(ctrl)
+	cfg := &config.Config{Session: config.Session{
+		Expire: 10,
+	}}
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC, nil, mfaUC, nil, nil, nil)
+
+	reqValue := &userService.RefreshSessionRequest{
+		RefreshToken: "refresh token",
+	}
+
+	t.Run("RefreshSession", func(t *testing.T) {
+		t.Parallel()
+		userID := uuid.New()
+		session := &models.Session{
+			SessionID: uuid.New().String(),
+			UserID:    userID,
+			FamilyID:  uuid.New().String(),
+		}
+		user := &models.User{
+			UserID: userID,
+			Email:  "email@gmail.com",
+			Roles:  []string{models.RoleUser},
+		}
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user-agent", "grpc-go/1.34.0"))
+		client := &models.ClientInfo{UserAgent: "grpc-go/1.34.0"}
+
+		sessUC.EXPECT().RefreshSession(gomock.Any(), reqValue.RefreshToken, client).Return(session, "new refresh token", nil)
+		userUC.EXPECT().FindById(gomock.Any(), userID).Return(user, nil)
+
+		response, err := authServerGRPC.RefreshSession(ctx, reqValue)
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		require.Equal(t, session.SessionID, response.SessionId)
+		require.Equal(t, "new refresh token", response.RefreshToken)
+	})
+}
+
+func TestUsersService_RotateSigningKey(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	cfg := &config.Config{Jwt: config.Jwt{
+		AccessTokenExpire: 60,
+		Issuer:            "auth",
+		SigningAlgorithm:  jwt.AlgorithmES256,
+		KeysDir:           t.TempDir(),
+	}}
+	apiLogger := logger.NewAPILogger(cfg)
+	apiLogger.InitLogger()
+	jwtManager, err := jwt.NewJwtManager(apiLogger, cfg)
+	require.NoError(t, err)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC, nil, nil, nil, nil, jwtManager)
+
+	t.Run("Admin", func(t *testing.T) {
+		ctx := interceptors.ContextWithPrincipal(context.Background(), &models.Principal{
+			UserID:      uuid.New(),
+			Roles:       []string{models.RoleAdmin},
+			Permissions: []string{models.PermissionKeysRotate},
+			SessionID:   uuid.New().String(),
+		})
+
+		response, err := authServerGRPC.RotateSigningKey(ctx, &userService.RotateSigningKeyRequest{})
+		require.NoError(t, err)
+		require.Equal(t, jwt.AlgorithmES256, response.Algorithm)
+		require.Len(t, jwtManager.JWKS().Keys, 2)
+	})
+
+}
+
+func TestUsersService_IntrospectToken(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	cfg := &config.Config{Jwt: config.Jwt{
+		AccessTokenExpire: 60,
+		Issuer:            "auth",
+		SigningAlgorithm:  jwt.AlgorithmES256,
+		KeysDir:           t.TempDir(),
+	}}
+	apiLogger := logger.NewAPILogger(cfg)
+	apiLogger.InitLogger()
+	jwtManager, err := jwt.NewJwtManager(apiLogger, cfg)
+	require.NoError(t, err)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC, nil, nil, nil, nil, jwtManager)
+
+	user := &models.User{
+		UserID:      uuid.New(),
+		Roles:       []string{models.RoleUser},
+		Permissions: []string{models.PermissionUsersRead},
+	}
+
+	t.Run("AccessToken", func(t *testing.T) {
+		session := &models.Session{SessionID: uuid.New().String(), UserID: user.UserID}
+		accessToken, err := jwtManager.GenerateAccessToken(&jwt.Claims{
+			UserID:    user.UserID.String(),
+			Roles:     user.Roles,
+			SessionID: session.SessionID,
+		})
+		require.NoError(t, err)
+
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), session.SessionID).Return(session, nil)
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+
+		response, err := authServerGRPC.IntrospectToken(context.Background(), &userService.IntrospectTokenRequest{Token: accessToken})
+		require.NoError(t, err)
+		require.True(t, response.Active)
+		require.Equal(t, "access_token", response.TokenType)
+		require.Equal(t, user.UserID.String(), response.UserId)
+		require.Equal(t, session.SessionID, response.SessionId)
+		require.Equal(t, user.Roles, response.Roles)
+	})
+
+	t.Run("Revoked session", func(t *testing.T) {
+		sessionID := uuid.New().String()
+		accessToken, err := jwtManager.GenerateAccessToken(&jwt.Claims{
+			UserID:    user.UserID.String(),
+			Roles:     user.Roles,
+			SessionID: sessionID,
+		})
+		require.NoError(t, err)
+
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), sessionID).Return(nil, redis.Nil)
+
+		response, err := authServerGRPC.IntrospectToken(context.Background(), &userService.IntrospectTokenRequest{Token: accessToken})
+		require.NoError(t, err)
+		require.False(t, response.Active)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		response, err := authServerGRPC.IntrospectToken(context.Background(), &userService.IntrospectTokenRequest{Token: "invalid"})
+		require.NoError(t, err)
+		require.False(t, response.Active)
+	})
+
+	t.Run("Session id", func(t *testing.T) {
+		session := &models.Session{SessionID: uuid.New().String(), UserID: user.UserID}
+
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), session.SessionID).Return(session, nil)
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+
+		response, err := authServerGRPC.IntrospectToken(context.Background(), &userService.IntrospectTokenRequest{Token: session.SessionID})
+		require.NoError(t, err)
+		require.True(t, response.Active)
+		require.Equal(t, "session_id", response.TokenType)
+		require.Equal(t, user.Roles, response.Roles)
+		require.Equal(t, user.Permissions, response.Scopes)
+	})
+}
+
+func TestUsersService_ValidateSession(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC, nil, nil, nil, nil, nil)
+
+	t.Run("Active", func(t *testing.T) {
+		user := &models.User{
+			UserID:      uuid.New(),
+			Roles:       []string{models.RoleAdmin},
+			Permissions: []string{models.PermissionRolesManage},
+		}
+		session := &models.Session{SessionID: uuid.New().String(), UserID: user.UserID}
+
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), session.SessionID).Return(session, nil)
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+
+		response, err := authServerGRPC.ValidateSession(context.Background(), &userService.ValidateSessionRequest{SessionId: session.SessionID})
+		require.NoError(t, err)
+		require.True(t, response.Active)
+		require.Equal(t, user.UserID.String(), response.UserId)
+		require.Equal(t, []string{models.RoleAdmin}, response.Roles)
+		require.Equal(t, []string{models.PermissionRolesManage}, response.Scopes)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		sessionID := uuid.New().String()
+
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), sessionID).Return(nil, redis.Nil)
+
+		response, err := authServerGRPC.ValidateSession(context.Background(), &userService.ValidateSessionRequest{SessionId: sessionID})
+		require.NoError(t, err)
+		require.False(t, response.Active)
+	})
+}
+
+func TestUsersService_GetMe(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC, nil, nil, nil, nil, nil)
+
+	t.Run("GetMe", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), Email: "email@gmail.com", Roles: []string{models.RoleUser}}
+		session := &models.Session{
+			SessionID:   uuid.New().String(),
+			UserID:      user.UserID,
+			IPAddress:   "203.0.113.7",
+			UserAgent:   "grpc-go/1.34.0",
+			LoginMethod: "password",
+			CreatedAt:   time.Now().Add(-time.Hour),
+			LastSeenAt:  time.Now(),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+		ctx := interceptors.ContextWithPrincipal(context.Background(), &models.Principal{
+			UserID:    user.UserID,
+			Roles:     user.Roles,
+			SessionID: session.SessionID,
+		})
+
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), session.SessionID).Return(session, nil)
+
+		response, err := authServerGRPC.GetMe(ctx, &userService.GetMeRequest{})
+		require.NoError(t, err)
+		require.Equal(t, user.Email, response.User.Email)
+		require.Equal(t, session.PublicID(), response.Session.SessionId)
+		require.Equal(t, session.IPAddress, response.Session.IpAddress)
+		require.Equal(t, session.UserAgent, response.Session.UserAgent)
+		require.Equal(t, session.LoginMethod, response.Session.LoginMethod)
+		require.True(t, response.Session.Current)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		response, err := authServerGRPC.GetMe(context.Background(), &userService.GetMeRequest{})
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestUsersService_MethodPolicy(t *testing.T) {
+	t.Parallel()
+
+	authServerGRPC := NewAuthServerGRPC(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	admin := &models.Principal{
+		UserID:      uuid.New(),
+		Roles:       []string{models.RoleAdmin},
+		Permissions: []string{models.PermissionUsersRead, models.PermissionRolesManage, models.PermissionKeysRotate},
+	}
+	user := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleUser}}
+
+	t.Run("FindByEmail", func(t *testing.T) {
+		policy := authServerGRPC.MethodPolicy("/userService.UserService/FindByEmail")
+		req := &userService.FindByEmailRequest{Email: "email@gmail.com"}
+		require.Equal(t, interceptors.AuthRequired, policy.Auth)
+		require.True(t, policy.Access(admin, req))
+		require.False(t, policy.Access(user, req))
+	})
+
+	t.Run("FindByID", func(t *testing.T) {
+		policy := authServerGRPC.MethodPolicy("/userService.UserService/FindByID")
+		require.Equal(t, interceptors.AuthRequired, policy.Auth)
+		require.True(t, policy.Access(admin, &userService.FindByIDRequest{Uuid: user.UserID.String()}))
+		require.True(t, policy.Access(user, &userService.FindByIDRequest{Uuid: user.UserID.String()}))
+		require.False(t, policy.Access(user, &userService.FindByIDRequest{Uuid: admin.UserID.String()}))
+	})
+
+	t.Run("IntrospectToken", func(t *testing.T) {
+		introspector := &models.Principal{UserID: uuid.New(), Permissions: []string{models.PermissionTokensIntrospect}}
+		for _, method := range []string{"/userService.UserService/IntrospectToken", "/userService.UserService/ValidateSession"} {
+			policy := authServerGRPC.MethodPolicy(method)
+			require.Equal(t, interceptors.AuthRequired, policy.Auth)
+			require.True(t, policy.Access(introspector, nil))
+			require.False(t, policy.Access(user, nil))
+		}
+	})
+
+	t.Run("RotateSigningKey", func(t *testing.T) {
+		policy := authServerGRPC.MethodPolicy("/userService.UserService/RotateSigningKey")
+		require.True(t, policy.Access(admin, &userService.RotateSigningKeyRequest{}))
+		require.False(t, policy.Access(user, &userService.RotateSigningKeyRequest{}))
+	})
+
+	t.Run("CheckPermission", func(t *testing.T) {
+		policy := authServerGRPC.MethodPolicy("/userService.UserService/CheckPermission")
+		require.True(t, policy.Access(user, &userService.CheckPermissionRequest{UserId: user.UserID.String()}))
+		require.False(t, policy.Access(user, &userService.CheckPermissionRequest{UserId: admin.UserID.String()}))
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		policy := authServerGRPC.MethodPolicy("/userService.UserService/UpdateUser")
+		profile := &userService.UpdateUserRequest{
+			Uuid:       user.UserID.String(),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"first_name"}},
+		}
+		roles := &userService.UpdateUserRequest{
+			Uuid:       user.UserID.String(),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"roles"}},
+		}
+		require.True(t, policy.Access(user, profile))
+		require.False(t, policy.Access(user, roles))
+		require.True(t, policy.Access(admin, roles))
+		require.False(t, policy.Access(&models.Principal{UserID: uuid.New()}, profile))
+	})
+
+	t.Run("ListUsers", func(t *testing.T) {
+		policy := authServerGRPC.MethodPolicy("/userService.UserService/ListUsers")
+		require.True(t, policy.Access(admin, &userService.ListUsersRequest{}))
+		require.False(t, policy.Access(user, &userService.ListUsersRequest{}))
+	})
+
+	t.Run("ExportUserData", func(t *testing.T) {
+		policy := authServerGRPC.MethodPolicy("/userService.UserService/ExportUserData")
+		require.True(t, policy.Access(admin, nil))
+		require.False(t, policy.Access(user, nil))
+	})
+
+	t.Run("Undeclared", func(t *testing.T) {
+		policy := authServerGRPC.MethodPolicy("/userService.UserService/Unknown")
+		require.Equal(t, interceptors.AuthRequired, policy.Auth)
+		require.Nil(t, policy.Access)
+	})
+}
+
+func TestUsersService_CreateRole(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	roleUC := mockRoleUC.NewMockRoleUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, nil, nil, roleUC, nil, nil, nil, nil)
+
+	t.Run("CreateRole", func(t *testing.T) {
+		role := &models.Role{Name: "editor", Description: "Editor", Permissions: []string{models.PermissionUsersRead}}
+
+		roleUC.EXPECT().CreateRole(gomock.Any(), role).Return(role, nil)
+
+		response, err := authServerGRPC.CreateRole(context.Background(), &userService.CreateRoleRequest{
+			Name:        " Editor ",
+			Description: "Editor",
+			Permissions: []string{"Users:Read"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, role.Name, response.Role.Name)
+		require.Equal(t, role.Permissions, response.Role.Permissions)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		response, err := authServerGRPC.CreateRole(context.Background(), &userService.CreateRoleRequest{Name: " "})
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsersService_AssignRole(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	roleUC := mockRoleUC.NewMockRoleUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, nil, roleUC, nil, nil, nil, nil)
+
+	t.Run("AssignRole", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), Roles: []string{models.RoleUser}}
+		assigned := &models.User{UserID: user.UserID, Roles: []string{models.RoleAdmin, models.RoleUser}}
+
+		gomock.InOrder(
+			userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil),
+			roleUC.EXPECT().AssignRole(gomock.Any(), user.UserID, models.RoleAdmin).Return(nil),
+			userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(assigned, nil),
+		)
+
+		response, err := authServerGRPC.AssignRole(context.Background(), &userService.AssignRoleRequest{
+			UserId: user.UserID.String(),
+			Role:   models.RoleAdmin,
+		})
+		require.NoError(t, err)
+		require.Equal(t, assigned.Roles, response.User.Roles)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		userID := uuid.New()
+
+		userUC.EXPECT().FindById(gomock.Any(), userID).Return(nil, sql.ErrNoRows)
+
+		response, err := authServerGRPC.AssignRole(context.Background(), &userService.AssignRoleRequest{
+			UserId: userID.String(),
+			Role:   models.RoleAdmin,
+		})
+		require.Nil(t, response)
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestUsersService_CheckPermissions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	roleUC := mockRoleUC.NewMockRoleUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, nil, nil, roleUC, nil, nil, nil, nil)
+
+	t.Run("CheckPermission", func(t *testing.T) {
+		userID := uuid.New()
+		decision := &models.PermissionDecision{Permission: "users:read", Allowed: true, Reason: "granted by users:*"}
+
+		roleUC.EXPECT().CheckPermission(gomock.Any(), userID, "users:read").Return(decision, nil)
+
+		response, err := authServerGRPC.CheckPermission(context.Background(), &userService.CheckPermissionRequest{
+			UserId:   userID.String(),
+			Resource: "Users",
+			Action:   "read",
+		})
+		require.NoError(t, err)
+		require.True(t, response.Allowed)
+		require.Equal(t, decision.Reason, response.Reason)
+	})
+
+	t.Run("CheckPermissions", func(t *testing.T) {
+		userID := uuid.New()
+		decisions := []*models.PermissionDecision{
+			{Permission: "users:read", Allowed: true, Reason: "granted by users:read"},
+			{Permission: "users:delete", Reason: "no role grants users:delete"},
+		}
+
+		roleUC.EXPECT().CheckPermissions(gomock.Any(), userID, []string{"users:read", "users:delete"}).Return(decisions, nil)
+
+		response, err := authServerGRPC.CheckPermissions(context.Background(), &userService.CheckPermissionsRequest{
+			UserId: userID.String(),
+			Checks: []*userService.PermissionCheck{
+				{Resource: "users", Action: "read"},
+				{Resource: "users", Action: "delete"},
+			},
+		})
+		require.NoError(t, err)
+		require.False(t, response.Allowed)
+		require.Len(t, response.Decisions, 2)
+		require.Equal(t, "users:delete", response.Decisions[1].Permission)
+	})
+
+	t.Run("Invalid resource", func(t *testing.T) {
+		response, err := authServerGRPC.CheckPermission(context.Background(), &userService.CheckPermissionRequest{
+			UserId:   uuid.New().String(),
+			Resource: "users:read",
+			Action:   "read",
+		})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsersService_VerifyMFA(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	mfaUC := mockMfaUC.NewMockMfaUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	cfg := &config.Config{Session: config.Session{Expire: 10}}
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	auditUC.EXPECT().Record(gomock.Any(), gomock.Any(), models.AuditEventLogin, gomock.Any()).Return(nil).AnyTimes()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC, nil, mfaUC, nil, auditUC, nil)
+
+	t.Run("VerifyMFA", func(t *testing.T) {
+		t.Parallel()
+		userID := uuid.New()
+		user := &models.User{UserID: userID, Email: "email@gmail.com", Roles: []string{models.RoleUser}}
+
+		mfaUC.EXPECT().VerifyChallenge(gomock.Any(), "challenge", "123456").Return(userID, nil)
+		userUC.EXPECT().FindById(gomock.Any(), userID).Return(user, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), &models.Session{UserID: userID, LoginMethod: loginMethodMFA}, user.Roles, cfg.Session.Expire).Return("session", nil)
+		sessUC.EXPECT().CreateRefreshToken(gomock.Any(), &models.Session{UserID: userID, LoginMethod: loginMethodMFA}).Return("refresh token", nil)
+
+		response, err := authServerGRPC.VerifyMFA(context.Background(), &userService.VerifyMFARequest{
+			ChallengeId: "challenge",
+			Code:        "123456",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "session", response.SessionId)
+		require.Equal(t, "refresh token", response.RefreshToken)
+		require.Equal(t, user.Email, response.User.Email)
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		t.Parallel()
+		mfaUC.EXPECT().VerifyChallenge(gomock.Any(), "other", "000000").Return(uuid.Nil, grpc_errors.ErrInvalidMFACode)
+
+		response, err := authServerGRPC.VerifyMFA(context.Background(), &userService.VerifyMFARequest{
+			ChallengeId: "other",
+			Code:        "000000",
+		})
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Missing code", func(t *testing.T) {
+		t.Parallel()
+		_, err := authServerGRPC.VerifyMFA(context.Background(), &userService.VerifyMFARequest{ChallengeId: "challenge"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsersService_LoginWithRecoveryCode(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	mfaUC := mockMfaUC.NewMockMfaUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	cfg := &config.Config{Session: config.Session{Expire: 10}}
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	auditUC.EXPECT().Record(gomock.Any(), gomock.Any(), models.AuditEventLogin, gomock.Any()).Return(nil).AnyTimes()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC, nil, mfaUC, nil, auditUC, nil)
+
+	t.Run("LoginWithRecoveryCode", func(t *testing.T) {
+		t.Parallel()
+		user := &models.User{UserID: uuid.New(), Email: "email@gmail.com", Roles: []string{models.RoleUser}}
+
+		userUC.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+		mfaUC.EXPECT().UseRecoveryCode(gomock.Any(), user.UserID, "abcd-efgh").Return(9, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), &models.Session{UserID: user.UserID, LoginMethod: loginMethodRecoveryCode}, user.Roles, cfg.Session.Expire).Return("session", nil)
+		sessUC.EXPECT().CreateRefreshToken(gomock.Any(), &models.Session{UserID: user.UserID, LoginMethod: loginMethodRecoveryCode}).Return("refresh token", nil)
+
+		response, err := authServerGRPC.LoginWithRecoveryCode(context.Background(), &userService.LoginWithRecoveryCodeRequest{
+			Email: user.Email,
+			Code:  "abcd-efgh",
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(9), response.RemainingCodes)
+		require.Equal(t, "session", response.Login.SessionId)
+	})
+
+	t.Run("Email not verified", func(t *testing.T) {
+		t.Parallel()
+		verificationCfg := &config.Config{Session: config.Session{Expire: 10}, EmailVerification: config.EmailVerification{Required: true}}
+		verificationServer := NewAuthServerGRPC(apiLogger, verificationCfg, userUC, sessUC, nil, mfaUC, nil, auditUC, nil)
+		user := &models.User{UserID: uuid.New(), Email: "unverified@gmail.com", Roles: []string{models.RoleUser}}
+
+		// verification is checked only once the code is valid, so it can't be probed without one
+		userUC.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+		mfaUC.EXPECT().UseRecoveryCode(gomock.Any(), user.UserID, "abcd-efgh").Return(9, nil)
+
+		_, err := verificationServer.LoginWithRecoveryCode(context.Background(), &userService.LoginWithRecoveryCodeRequest{
+			Email: user.Email,
+			Code:  "abcd-efgh",
+		})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		t.Parallel()
+		userUC.EXPECT().FindByEmail(gomock.Any(), "unknown@gmail.com").Return(nil, sql.ErrNoRows)
+
+		_, err := authServerGRPC.LoginWithRecoveryCode(context.Background(), &userService.LoginWithRecoveryCodeRequest{
+			Email: "unknown@gmail.com",
+			Code:  "abcd-efgh",
+		})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestUsersService_FinishPasskeyLogin(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	passkeyUC := mockPasskeyUC.NewMockPasskeyUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	cfg := &config.Config{Session: config.Session{Expire: 10}}
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	auditUC.EXPECT().Record(gomock.Any(), gomock.Any(), models.AuditEventLogin, gomock.Any()).Return(nil).AnyTimes()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC, nil, nil, passkeyUC, auditUC, nil)
+
+	t.Run("FinishPasskeyLogin", func(t *testing.T) {
+		t.Parallel()
+		user := &models.User{UserID: uuid.New(), Email: "email@gmail.com", Roles: []string{models.RoleUser}}
+
+		passkeyUC.EXPECT().FinishLogin(gomock.Any(), "challenge", []byte("credential")).Return(user.UserID, nil)
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), &models.Session{UserID: user.UserID, LoginMethod: loginMethodPasskey}, user.Roles, cfg.Session.Expire).Return("session", nil)
+		sessUC.EXPECT().CreateRefreshToken(gomock.Any(), &models.Session{UserID: user.UserID, LoginMethod: loginMethodPasskey}).Return("refresh token", nil)
+
+		response, err := authServerGRPC.FinishPasskeyLogin(context.Background(), &userService.FinishPasskeyLoginRequest{
+			ChallengeId: "challenge",
+			Credential:  []byte("credential"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "session", response.SessionId)
+	})
+
+	t.Run("Email not verified", func(t *testing.T) {
+		t.Parallel()
+		verificationCfg := &config.Config{Session: config.Session{Expire: 10}, EmailVerification: config.EmailVerification{Required: true}}
+		verificationServer := NewAuthServerGRPC(apiLogger, verificationCfg, userUC, sessUC, nil, nil, passkeyUC, auditUC, nil)
+		user := &models.User{UserID: uuid.New(), Email: "unverified@gmail.com", Roles: []string{models.RoleUser}}
+
+		passkeyUC.EXPECT().FinishLogin(gomock.Any(), "unverified", []byte("credential")).Return(user.UserID, nil)
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+
+		_, err := verificationServer.FinishPasskeyLogin(context.Background(), &userService.FinishPasskeyLoginRequest{
+			ChallengeId: "unverified",
+			Credential:  []byte("credential"),
+		})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("Invalid passkey", func(t *testing.T) {
+		t.Parallel()
+		passkeyUC.EXPECT().FinishLogin(gomock.Any(), "other", []byte("credential")).Return(uuid.Nil, grpc_errors.ErrInvalidPasskey)
+
+		_, err := authServerGRPC.FinishPasskeyLogin(context.Background(), &userService.FinishPasskeyLoginRequest{
+			ChallengeId: "other",
+			Credential:  []byte("credential"),
+		})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestUsersService_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, nil, nil, nil, nil, nil, nil)
+
+	t.Run("VerifyEmail", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), Email: "email@gmail.com", EmailVerified: true}
+
+		userUC.EXPECT().VerifyEmail(gomock.Any(), "token").Return(user, nil)
+
+		response, err := authServerGRPC.VerifyEmail(context.Background(), &userService.VerifyEmailRequest{Token: "token"})
+		require.NoError(t, err)
+		require.True(t, response.User.EmailVerified)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		userUC.EXPECT().VerifyEmail(gomock.Any(), "expired").Return(nil, grpc_errors.ErrInvalidToken)
+
+		response, err := authServerGRPC.VerifyEmail(context.Background(), &userService.VerifyEmailRequest{Token: "expired"})
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestUsersService_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	auditUC.EXPECT().Record(gomock.Any(), gomock.Any(), models.AuditEventPasswordReset, gomock.Any()).Return(nil).AnyTimes()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC, nil, nil, nil, auditUC, nil)
+
+	t.Run("ResetPassword", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+
+		userUC.EXPECT().ResetPassword(gomock.Any(), "token", "new password 1").Return(user, nil)
+		sessUC.EXPECT().DeleteByUserID(gomock.Any(), user.UserID).Return(nil)
+
+		response, err := authServerGRPC.ResetPassword(context.Background(), &userService.ResetPasswordRequest{
+			Token:       "token",
+			NewPassword: "new password 1",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, response)
+	})
+
+	t.Run("WeakPassword", func(t *testing.T) {
+		response, err := authServerGRPC.ResetPassword(context.Background(), &userService.ResetPasswordRequest{
+			Token:       "token",
+			NewPassword: "password",
+		})
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsersService_ChangePassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	auditUC.EXPECT().Record(gomock.Any(), gomock.Any(), models.AuditEventPasswordChanged, gomock.Any()).Return(nil).AnyTimes()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC, nil, nil, nil, auditUC, nil)
+
+	principal := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleUser}, SessionID: uuid.New().String()}
+	ctx := interceptors.ContextWithPrincipal(context.Background(), principal)
+
+	t.Run("RevokeOtherSessions", func(t *testing.T) {
+		userUC.EXPECT().ChangePassword(gomock.Any(), principal.UserID, "old password 1", "new password 1").Return(nil)
+		sessUC.EXPECT().DeleteOtherSessions(gomock.Any(), principal.UserID, principal.SessionID).Return(nil)
+
+		response, err := authServerGRPC.ChangePassword(ctx, &userService.ChangePasswordRequest{
+			OldPassword:         "old password 1",
+			NewPassword:         "new password 1",
+			RevokeOtherSessions: true,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, response)
+	})
+
+	t.Run("InvalidPassword", func(t *testing.T) {
+		userUC.EXPECT().ChangePassword(gomock.Any(), principal.UserID, "wrong", "new password 1").Return(grpc_errors.ErrInvalidPassword)
+
+		response, err := authServerGRPC.ChangePassword(ctx, &userService.ChangePasswordRequest{
+			OldPassword: "wrong",
+			NewPassword: "new password 1",
+		})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("WeakPassword", func(t *testing.T) {
+		response, err := authServerGRPC.ChangePassword(ctx, &userService.ChangePasswordRequest{
+			OldPassword: "old password 1",
+			NewPassword: "12345678",
+		})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsersService_UpdateUser(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	roleUC := mockRoleUC.NewMockRoleUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, nil, roleUC, nil, nil, nil, nil)
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), FirstName: "FirstName", LastName: "LastName", Roles: []string{models.RoleUser}}
+		updated := &models.User{UserID: user.UserID, FirstName: "NewName", LastName: "LastName", Roles: []string{models.RoleAdmin}}
+
+		gomock.InOrder(
+			userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil),
+			userUC.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, u *models.User) (*models.User, error) {
+				require.Equal(t, "NewName", u.FirstName)
+				require.Equal(t, "LastName", u.LastName)
+				return u, nil
+			}),
+			roleUC.EXPECT().AssignRole(gomock.Any(), user.UserID, models.RoleAdmin).Return(nil),
+			roleUC.EXPECT().UnassignRole(gomock.Any(), user.UserID, models.RoleUser).Return(nil),
+			userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(updated, nil),
+		)
+
+		response, err := authServerGRPC.UpdateUser(context.Background(), &userService.UpdateUserRequest{
+			Uuid:       user.UserID.String(),
+			FirstName:  "NewName",
+			LastName:   "ignored",
+			Roles:      []string{"Admin"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"first_name", "roles"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "NewName", response.User.FirstName)
+		require.Equal(t, updated.Roles, response.User.Roles)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), FirstName: "FirstName", LastName: "LastName"}
+
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+
+		response, err := authServerGRPC.UpdateUser(context.Background(), &userService.UpdateUserRequest{
+			Uuid:       user.UserID.String(),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"last_name"}},
+		})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("UnsupportedPath", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), FirstName: "FirstName", LastName: "LastName"}
+
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+
+		response, err := authServerGRPC.UpdateUser(context.Background(), &userService.UpdateUserRequest{
+			Uuid:       user.UserID.String(),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+		})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsersService_DeleteAccount(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	auditUC.EXPECT().Record(gomock.Any(), gomock.Any(), models.AuditEventAccountDeleted, gomock.Any()).Return(nil).AnyTimes()
+	roleUC := mockRoleUC.NewMockRoleUseCase(ctrl)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC, roleUC, nil, nil, auditUC, nil)
+
+	principal := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleUser}, SessionID: uuid.New().String()}
+	ctx := interceptors.ContextWithPrincipal(context.Background(), principal)
+
+	t.Run("DeleteAccount", func(t *testing.T) {
+		userUC.EXPECT().DeleteAccount(gomock.Any(), principal.UserID, "password 1").Return(nil)
+		roleUC.EXPECT().InvalidateUser(gomock.Any(), principal.UserID)
+		sessUC.EXPECT().DeleteByUserID(gomock.Any(), principal.UserID).Return(nil)
+
+		response, err := authServerGRPC.DeleteAccount(ctx, &userService.DeleteAccountRequest{Password: "password 1"})
+		require.NoError(t, err)
+		require.NotNil(t, response)
+	})
+
+	t.Run("InvalidPassword", func(t *testing.T) {
+		userUC.EXPECT().DeleteAccount(gomock.Any(), principal.UserID, "wrong").Return(grpc_errors.ErrInvalidPassword)
+
+		response, err := authServerGRPC.DeleteAccount(ctx, &userService.DeleteAccountRequest{Password: "wrong"})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsersService_DeleteUser(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	roleUC := mockRoleUC.NewMockRoleUseCase(ctrl)
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC, roleUC, nil, nil, auditUC, nil)
+
+	admin := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleAdmin}, SessionID: uuid.New().String()}
+	ctx := interceptors.ContextWithPrincipal(context.Background(), admin)
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		userID := uuid.New()
+		userUC.EXPECT().Delete(gomock.Any(), userID).Return(nil)
+		auditUC.EXPECT().Record(gomock.Any(), userID, models.AuditEventAccountDeleted, models.AuditMetadata{
+			"deleted_by": admin.UserID.String(),
+		}).Return(nil)
+		roleUC.EXPECT().InvalidateUser(gomock.Any(), userID)
+		sessUC.EXPECT().DeleteByUserID(gomock.Any(), userID).Return(nil)
+
+		response, err := authServerGRPC.DeleteUser(ctx, &userService.DeleteUserRequest{Uuid: userID.String()})
+		require.NoError(t, err)
+		require.NotNil(t, response)
+	})
+
+	t.Run("Own account", func(t *testing.T) {
+		response, err := authServerGRPC.DeleteUser(ctx, &userService.DeleteUserRequest{Uuid: admin.UserID.String()})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+type exportDataStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	chunks [][]byte
+}
+
+func (s *exportDataStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *exportDataStream) Send(chunk *userService.ExportDataChunk) error {
+	s.chunks = append(s.chunks, chunk.GetData())
+	return nil
+}
+
+func TestUsersService_ExportMyData(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	mfaUC := mockMfaUC.NewMockMfaUseCase(ctrl)
+	passkeyUC := mockPasskeyUC.NewMockPasskeyUseCase(ctrl)
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC, nil, mfaUC, passkeyUC, auditUC, nil)
+
+	principal := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleUser}, SessionID: uuid.New().String()}
+	ctx := interceptors.ContextWithPrincipal(context.Background(), principal)
+
+	t.Run("ExportMyData", func(t *testing.T) {
+		user := &models.User{UserID: principal.UserID, Email: "email@gmail.com", Password: "hash"}
+		sessions := []*models.Session{{SessionID: principal.SessionID, UserID: principal.UserID, ExpiresAt: time.Now().Add(time.Hour)}}
+		events := []*models.AuditEvent{
+			{UserID: principal.UserID, Type: models.AuditEventLogin, Metadata: models.AuditMetadata{"method": "password"}},
+			{UserID: principal.UserID, Type: models.AuditEventPasswordChanged, Metadata: models.AuditMetadata{}},
+		}
+
+		userUC.EXPECT().FindById(gomock.Any(), principal.UserID).Return(user, nil)
+		sessUC.EXPECT().GetSessionsByUserID(gomock.Any(), principal.UserID).Return(sessions, nil)
+		mfaUC.EXPECT().GetStatus(gomock.Any(), principal.UserID).Return(&models.MFAStatus{RecoveryCodesRemaining: 3}, nil)
+		passkeyUC.EXPECT().List(gomock.Any(), principal.UserID).Return([]*models.Passkey{{Name: "Laptop", PublicKey: []byte("key")}}, nil)
+		auditUC.EXPECT().FindByUserID(gomock.Any(), principal.UserID).Return(events, nil)
+		auditUC.EXPECT().Record(gomock.Any(), principal.UserID, models.AuditEventDataExported, gomock.Any()).Return(nil)
+
+		stream := &exportDataStream{ctx: ctx}
+		err := authServerGRPC.ExportMyData(&userService.ExportMyDataRequest{}, stream)
+		require.NoError(t, err)
+		require.NotEmpty(t, stream.chunks)
+
+		data := bytes.Join(stream.chunks, nil)
+		require.NotContains(t, string(data), "hash")
+		require.NotContains(t, string(data), principal.SessionID)
+		require.NotContains(t, string(data), "public_key")
+
+		export := &models.UserDataExport{}
+		require.NoError(t, json.Unmarshal(data, export))
+		require.Equal(t, user.Email, export.Profile.Email)
+		require.Len(t, export.Sessions, 1)
+		require.Equal(t, 3, export.MFA.RecoveryCodesRemaining)
+		require.Len(t, export.Passkeys, 1)
+		require.Len(t, export.LoginHistory, 1)
+		require.Len(t, export.AuditEvents, 1)
+	})
+
+	t.Run("ExportUserData", func(t *testing.T) {
+		admin := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleAdmin}, SessionID: uuid.New().String()}
+		adminCtx := interceptors.ContextWithPrincipal(context.Background(), admin)
+		deletedUser := &models.User{UserID: uuid.New(), Email: "deleted@gmail.com"}
+		events := []*models.AuditEvent{
+			{UserID: deletedUser.UserID, Type: models.AuditEventDataExported, Metadata: models.AuditMetadata{"requested_by": deletedUser.UserID.String()}},
+			{UserID: deletedUser.UserID, Type: models.AuditEventAccountDeleted, Metadata: models.AuditMetadata{"deleted_by": admin.UserID.String()}},
+		}
+
+		// account deleted during grace period is found by the unscoped lookup
+		userUC.EXPECT().FindByIdUnscoped(gomock.Any(), deletedUser.UserID).Return(deletedUser, nil)
+		sessUC.EXPECT().GetSessionsByUserID(gomock.Any(), deletedUser.UserID).Return(nil, nil)
+		mfaUC.EXPECT().GetStatus(gomock.Any(), deletedUser.UserID).Return(&models.MFAStatus{}, nil)
+		passkeyUC.EXPECT().List(gomock.Any(), deletedUser.UserID).Return(nil, nil)
+		auditUC.EXPECT().FindByUserID(gomock.Any(), deletedUser.UserID).Return(events, nil)
+		auditUC.EXPECT().Record(gomock.Any(), deletedUser.UserID, models.AuditEventDataExported, models.AuditMetadata{
+			"requested_by": admin.UserID.String(),
+		}).Return(nil)
+
+		stream := &exportDataStream{ctx: adminCtx}
+		err := authServerGRPC.ExportUserData(&userService.ExportUserDataRequest{Uuid: deletedUser.UserID.String()}, stream)
+		require.NoError(t, err)
+
+		data := bytes.Join(stream.chunks, nil)
+		require.NotContains(t, string(data), admin.UserID.String())
+
+		export := &models.UserDataExport{}
+		require.NoError(t, json.Unmarshal(data, export))
+		require.Equal(t, deletedUser.Email, export.Profile.Email)
+		require.Len(t, export.AuditEvents, 2)
+		require.Equal(t, deletedUser.UserID.String(), export.AuditEvents[0].Metadata["requested_by"])
+		require.Equal(t, redactedAuditActor, export.AuditEvents[1].Metadata["deleted_by"])
+	})
+
+	t.Run("ExportUserDataInvalidUUID", func(t *testing.T) {
+		stream := &exportDataStream{ctx: ctx}
+		err := authServerGRPC.ExportUserData(&userService.ExportUserDataRequest{Uuid: "invalid"}, stream)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.Empty(t, stream.chunks)
+	})
+}
+
+func TestUsersService_ListUsers(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, nil, nil, nil, nil, nil, nil)
+
+	t.Run("ListUsers", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), Email: "email@gmail.com", CreatedAt: time.Now()}
+		nextCursor := &models.UserListCursor{CreatedAt: user.CreatedAt, UserID: user.UserID}
+		cursor := &models.UserListCursor{CreatedAt: time.Now().Add(time.Hour).UTC(), UserID: uuid.New()}
+
+		userUC.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, filter *models.UserListFilter) (*models.UserList, error) {
+				require.Equal(t, models.RoleAdmin, filter.Role)
+				require.Equal(t, models.UserStatusActive, filter.Status)
+				require.Equal(t, 10, filter.Limit)
+				require.Equal(t, cursor.UserID, filter.Cursor.UserID)
+				require.True(t, cursor.CreatedAt.Equal(filter.Cursor.CreatedAt))
+				return &models.UserList{Users: []*models.User{user}, NextCursor: nextCursor}, nil
+			},
+		)
+
+		response, err := authServerGRPC.ListUsers(context.Background(), &userService.ListUsersRequest{
+			PageSize:  10,
+			PageToken: cursor.Encode(),
+			Role:      "Admin",
+			Status:    models.UserStatusActive,
+		})
+		require.NoError(t, err)
+		require.Len(t, response.GetUsers(), 1)
+		require.Equal(t, nextCursor.Encode(), response.GetNextPageToken())
+	})
+
+	t.Run("InvalidPageToken", func(t *testing.T) {
+		response, err := authServerGRPC.ListUsers(context.Background(), &userService.ListUsersRequest{PageToken: "invalid"})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		response, err := authServerGRPC.ListUsers(context.Background(), &userService.ListUsersRequest{Status: "banned"})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsersService_SearchUsers(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, nil, nil, nil, nil, nil, nil)
+
+	t.Run("SearchUsers", func(t *testing.T) {
+		result := &models.UserSearchResult{User: models.User{UserID: uuid.New(), Email: "john@gmail.com"}, Rank: 0.8}
+
+		userUC.EXPECT().Search(gomock.Any(), &models.UserSearchFilter{Query: "john", Limit: 1, Offset: 5}).
+			Return(&models.UserSearchPage{Results: []*models.UserSearchResult{result}, HasMore: true}, nil)
+
+		response, err := authServerGRPC.SearchUsers(context.Background(), &userService.SearchUsersRequest{
+			Query:     " john ",
+			PageSize:  1,
+			PageToken: encodeOffsetPageToken(5),
+		})
+		require.NoError(t, err)
+		require.Len(t, response.GetResults(), 1)
+		require.Equal(t, result.Email, response.GetResults()[0].GetUser().GetEmail())
+		require.Equal(t, 0.8, response.GetResults()[0].GetRank())
+
+		offset, err := decodeOffsetPageToken(response.GetNextPageToken())
+		require.NoError(t, err)
+		require.Equal(t, 6, offset)
+	})
+
+	t.Run("EmptyQuery", func(t *testing.T) {
+		response, err := authServerGRPC.SearchUsers(context.Background(), &userService.SearchUsersRequest{Query: "  "})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("InvalidPageToken", func(t *testing.T) {
+		response, err := authServerGRPC.SearchUsers(context.Background(), &userService.SearchUsersRequest{Query: "john", PageToken: "-1"})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUsersService_MySessions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, nil, sessUC, nil, nil, nil, auditUC, nil)
+
+	principal := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleUser}, SessionID: uuid.New().String()}
+	ctx := interceptors.ContextWithPrincipal(context.Background(), principal)
+	current := &models.Session{SessionID: principal.SessionID, UserID: principal.UserID, ExpiresAt: time.Now().Add(time.Hour)}
+	other := &models.Session{SessionID: uuid.New().String(), UserID: principal.UserID, ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("ListMySessions", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionsByUserID(gomock.Any(), principal.UserID).Return([]*models.Session{current, other}, nil)
+
+		response, err := authServerGRPC.ListMySessions(ctx, &userService.ListMySessionsRequest{})
+		require.NoError(t, err)
+		require.Len(t, response.GetSessions(), 2)
+		require.True(t, response.GetSessions()[0].GetCurrent())
+		require.False(t, response.GetSessions()[1].GetCurrent())
+		require.Equal(t, other.PublicID(), response.GetSessions()[1].GetSessionId())
+		require.NotEqual(t, other.SessionID, response.GetSessions()[1].GetSessionId())
+	})
+
+	t.Run("RevokeSession", func(t *testing.T) {
+		sessUC.EXPECT().DeleteByPublicID(gomock.Any(), principal.UserID, other.PublicID()).Return(nil)
+		auditUC.EXPECT().Record(gomock.Any(), principal.UserID, models.AuditEventSessionRevoked, gomock.Any()).Return(nil)
+
+		response, err := authServerGRPC.RevokeSession(ctx, &userService.RevokeSessionRequest{SessionId: other.PublicID()})
+		require.NoError(t, err)
+		require.NotNil(t, response)
+	})
+
+	t.Run("RevokeUnknownSession", func(t *testing.T) {
+		sessUC.EXPECT().DeleteByPublicID(gomock.Any(), principal.UserID, "unknown").Return(grpc_errors.ErrSessionNotFound)
+
+		response, err := authServerGRPC.RevokeSession(ctx, &userService.RevokeSessionRequest{SessionId: "unknown"})
+		require.Nil(t, response)
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("RevokeAllOtherSessions", func(t *testing.T) {
+		sessUC.EXPECT().DeleteOtherSessions(gomock.Any(), principal.UserID, principal.SessionID).Return(nil)
+		auditUC.EXPECT().Record(gomock.Any(), principal.UserID, models.AuditEventSessionsRevoked, gomock.Any()).Return(nil)
+
+		response, err := authServerGRPC.RevokeAllOtherSessions(ctx, &userService.RevokeAllOtherSessionsRequest{})
+		require.NoError(t, err)
+		require.NotNil(t, response)
+	})
+}
+
+func TestUsersService_RevokeUserSessions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sessUC := mockSessUC.NewMockSessionUseCase(ctrl)
+	auditUC := mockAuditUC.NewMockAuditUseCase(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, nil, sessUC, nil, nil, nil, auditUC, nil)
+
+	admin := &models.Principal{UserID: uuid.New(), Roles: []string{models.RoleAdmin}, SessionID: uuid.New().String()}
+	ctx := interceptors.ContextWithPrincipal(context.Background(), admin)
+
+	t.Run("RevokeUserSessions", func(t *testing.T) {
+		userID := uuid.New()
+		sessUC.EXPECT().RevokeUserSessions(gomock.Any(), userID).Return(nil)
+		auditUC.EXPECT().Record(gomock.Any(), userID, models.AuditEventAllSessionsRevoked, models.AuditMetadata{
+			"revoked_by": admin.UserID.String(),
+		}).Return(nil)
+
+		response, err := authServerGRPC.RevokeUserSessions(ctx, &userService.RevokeUserSessionsRequest{UserId: userID.String()})
+		require.NoError(t, err)
+		require.NotNil(t, response)
+	})
+
+	t.Run("Invalid user id", func(t *testing.T) {
+		response, err := authServerGRPC.RevokeUserSessions(ctx, &userService.RevokeUserSessionsRequest{UserId: "invalid"})
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -2,19 +2,204 @@ package service
 
 import (
 	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/internal/audit"
+	"github.com/AleksK1NG/auth-microservice/internal/interceptors"
+	"github.com/AleksK1NG/auth-microservice/internal/mfa"
+	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/internal/passkey"
+	"github.com/AleksK1NG/auth-microservice/internal/role"
 	"github.com/AleksK1NG/auth-microservice/internal/session"
 	"github.com/AleksK1NG/auth-microservice/internal/user"
+	"github.com/AleksK1NG/auth-microservice/pkg/jwt"
 	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+	userService "github.com/AleksK1NG/auth-microservice/proto"
 )
 
 type usersService struct {
-	logger logger.Logger
-	cfg    *config.Config
-	userUC user.UserUseCase
-	sessUC session.SessionUseCase
+	logger     logger.Logger
+	cfg        *config.Config
+	userUC     user.UserUseCase
+	sessUC     session.SessionUseCase
+	roleUC     role.RoleUseCase
+	mfaUC      mfa.MfaUseCase
+	passkeyUC  passkey.PasskeyUseCase
+	auditUC    audit.AuditUseCase
+	jwtManager jwt.Manager
 }
 
 // Auth service constructor
-func NewAuthServerGRPC(logger logger.Logger, cfg *config.Config, userUC user.UserUseCase, sessUC session.SessionUseCase) *usersService {
-	return &usersService{logger: logger, cfg: cfg, userUC: userUC, sessUC: sessUC}
+func NewAuthServerGRPC(
+	logger logger.Logger,
+	cfg *config.Config,
+	userUC user.UserUseCase,
+	sessUC session.SessionUseCase,
+	roleUC role.RoleUseCase,
+	mfaUC mfa.MfaUseCase,
+	passkeyUC passkey.PasskeyUseCase,
+	auditUC audit.AuditUseCase,
+	jwtManager jwt.Manager,
+) *usersService {
+	return &usersService{
+		logger:     logger,
+		cfg:        cfg,
+		userUC:     userUC,
+		sessUC:     sessUC,
+		roleUC:     roleUC,
+		mfaUC:      mfaUC,
+		passkeyUC:  passkeyUC,
+		auditUC:    auditUC,
+		jwtManager: jwtManager,
+	}
+}
+
+// Authentication and authorization policy of UserService methods
+var methodPolicies = map[string]interceptors.MethodPolicy{
+	"/userService.UserService/Register": {Auth: interceptors.AuthForbidden},
+	"/userService.UserService/Login":    {Auth: interceptors.AuthForbidden},
+	"/userService.UserService/FindByEmail": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionUsersRead),
+	},
+	"/userService.UserService/FindByID": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.SelfOrPermission(requestOwner, models.PermissionUsersRead),
+	},
+	"/userService.UserService/GetMe":          {Auth: interceptors.AuthRequired},
+	"/userService.UserService/Logout":         {Auth: interceptors.AuthRequired},
+	"/userService.UserService/RefreshSession": {Auth: interceptors.AuthOptional},
+	"/userService.UserService/RotateSigningKey": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionKeysRotate),
+	},
+	"/userService.UserService/IntrospectToken": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionTokensIntrospect),
+	},
+	"/userService.UserService/ValidateSession": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionTokensIntrospect),
+	},
+	"/userService.UserService/CreateRole": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionRolesManage),
+	},
+	"/userService.UserService/GrantPermission": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionRolesManage),
+	},
+	"/userService.UserService/RevokePermission": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionRolesManage),
+	},
+	"/userService.UserService/AssignRole": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionRolesManage),
+	},
+	"/userService.UserService/UnassignRole": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionRolesManage),
+	},
+	"/userService.UserService/CheckPermission": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.SelfOrPermission(requestOwner, models.PermissionPermissionsCheck),
+	},
+	"/userService.UserService/CheckPermissions": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.SelfOrPermission(requestOwner, models.PermissionPermissionsCheck),
+	},
+	"/userService.UserService/EnableTOTP":                {Auth: interceptors.AuthRequired},
+	"/userService.UserService/ConfirmTOTP":               {Auth: interceptors.AuthRequired},
+	"/userService.UserService/DisableTOTP":               {Auth: interceptors.AuthRequired},
+	"/userService.UserService/VerifyMFA":                 {Auth: interceptors.AuthForbidden},
+	"/userService.UserService/GenerateRecoveryCodes":     {Auth: interceptors.AuthRequired},
+	"/userService.UserService/LoginWithRecoveryCode":     {Auth: interceptors.AuthForbidden},
+	"/userService.UserService/BeginPasskeyRegistration":  {Auth: interceptors.AuthRequired},
+	"/userService.UserService/FinishPasskeyRegistration": {Auth: interceptors.AuthRequired},
+	"/userService.UserService/BeginPasskeyLogin":         {Auth: interceptors.AuthForbidden},
+	"/userService.UserService/FinishPasskeyLogin":        {Auth: interceptors.AuthForbidden},
+	"/userService.UserService/VerifyEmail":               {Auth: interceptors.AuthOptional},
+	"/userService.UserService/ResendVerification":        {Auth: interceptors.AuthOptional},
+	"/userService.UserService/RequestPasswordReset":      {Auth: interceptors.AuthOptional},
+	"/userService.UserService/ResetPassword":             {Auth: interceptors.AuthOptional},
+	"/userService.UserService/ChangePassword":            {Auth: interceptors.AuthRequired},
+	"/userService.UserService/RequestEmailChange":        {Auth: interceptors.AuthRequired},
+	"/userService.UserService/ConfirmEmailChange":        {Auth: interceptors.AuthOptional},
+	"/userService.UserService/UpdateUser": {
+		Auth:   interceptors.AuthRequired,
+		Access: updateUserAccess,
+	},
+	"/userService.UserService/DeleteAccount": {Auth: interceptors.AuthRequired},
+	"/userService.UserService/DeleteUser": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionUsersWrite),
+	},
+	"/userService.UserService/RestoreUser": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionUsersWrite),
+	},
+	"/userService.UserService/ListUsers": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionUsersRead),
+	},
+	"/userService.UserService/SearchUsers": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionUsersRead),
+	},
+	"/userService.UserService/ExportMyData":           {Auth: interceptors.AuthRequired},
+	"/userService.UserService/ListMySessions":         {Auth: interceptors.AuthRequired},
+	"/userService.UserService/RevokeSession":          {Auth: interceptors.AuthRequired},
+	"/userService.UserService/RevokeAllOtherSessions": {Auth: interceptors.AuthRequired},
+	"/userService.UserService/ExportUserData": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionUsersRead),
+	},
+	"/userService.UserService/RevokeUserSessions": {
+		Auth:   interceptors.AuthRequired,
+		Access: interceptors.RequirePermission(models.PermissionUsersWrite),
+	},
+}
+
+// Get policy of method, undeclared methods require authentication
+func (u *usersService) MethodPolicy(fullMethod string) interceptors.MethodPolicy {
+	if policy, ok := methodPolicies[fullMethod]; ok {
+		return policy
+	}
+	return interceptors.MethodPolicy{Auth: interceptors.AuthRequired}
+}
+
+// Get id of user the request is about
+func requestOwner(req interface{}) string {
+	switch r := req.(type) {
+	case *userService.FindByIDRequest:
+		return r.GetUuid()
+	case *userService.CheckPermissionRequest:
+		return r.GetUserId()
+	case *userService.CheckPermissionsRequest:
+		return r.GetUserId()
+	case *userService.UpdateUserRequest:
+		return r.GetUuid()
+	}
+	return ""
+}
+
+// Profile fields can be updated by the user or with users:write permission, roles only with roles:manage
+func updateUserAccess(principal *models.Principal, req interface{}) bool {
+	r, ok := req.(*userService.UpdateUserRequest)
+	if !ok || principal == nil {
+		return false
+	}
+
+	var updateProfile, updateRoles bool
+	for _, path := range r.GetUpdateMask().GetPaths() {
+		if path == updateMaskRoles {
+			updateRoles = true
+		} else {
+			updateProfile = true
+		}
+	}
+
+	if updateRoles && !interceptors.RequirePermission(models.PermissionRolesManage)(principal, req) {
+		return false
+	}
+	return !updateProfile || interceptors.SelfOrPermission(requestOwner, models.PermissionUsersWrite)(principal, req)
 }
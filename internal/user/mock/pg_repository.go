@@ -10,6 +10,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	reflect "reflect"
+	time "time"
 )
 
 // MockUserPGRepository is a mock of UserPGRepository interface
@@ -79,3 +80,166 @@ func (mr *MockUserPGRepositoryMockRecorder) FindById(ctx, userID interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockUserPGRepository)(nil).FindById), ctx, userID)
 }
+
+// FindByIdUnscoped mocks base method
+func (m *MockUserPGRepository) FindByIdUnscoped(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdUnscoped", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdUnscoped indicates an expected call of FindByIdUnscoped
+func (mr *MockUserPGRepositoryMockRecorder) FindByIdUnscoped(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdUnscoped", reflect.TypeOf((*MockUserPGRepository)(nil).FindByIdUnscoped), ctx, userID)
+}
+
+// Update mocks base method
+func (m *MockUserPGRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, user)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockUserPGRepositoryMockRecorder) Update(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserPGRepository)(nil).Update), ctx, user)
+}
+
+// List mocks base method
+func (m *MockUserPGRepository) List(ctx context.Context, filter *models.UserListFilter) (*models.UserList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].(*models.UserList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockUserPGRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserPGRepository)(nil).List), ctx, filter)
+}
+
+// Search mocks base method
+func (m *MockUserPGRepository) Search(ctx context.Context, filter *models.UserSearchFilter) (*models.UserSearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, filter)
+	ret0, _ := ret[0].(*models.UserSearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search
+func (mr *MockUserPGRepositoryMockRecorder) Search(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserPGRepository)(nil).Search), ctx, filter)
+}
+
+// SetEmailVerified mocks base method
+func (m *MockUserPGRepository) SetEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailVerified", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailVerified indicates an expected call of SetEmailVerified
+func (mr *MockUserPGRepositoryMockRecorder) SetEmailVerified(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailVerified", reflect.TypeOf((*MockUserPGRepository)(nil).SetEmailVerified), ctx, userID)
+}
+
+// UpdateEmail mocks base method
+func (m *MockUserPGRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, oldEmail, newEmail string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, userID, oldEmail, newEmail)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail
+func (mr *MockUserPGRepositoryMockRecorder) UpdateEmail(ctx, userID, oldEmail, newEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockUserPGRepository)(nil).UpdateEmail), ctx, userID, oldEmail, newEmail)
+}
+
+// FindPasswordHash mocks base method
+func (m *MockUserPGRepository) FindPasswordHash(ctx context.Context, userID uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPasswordHash", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPasswordHash indicates an expected call of FindPasswordHash
+func (mr *MockUserPGRepositoryMockRecorder) FindPasswordHash(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPasswordHash", reflect.TypeOf((*MockUserPGRepository)(nil).FindPasswordHash), ctx, userID)
+}
+
+// SoftDelete mocks base method
+func (m *MockUserPGRepository) SoftDelete(ctx context.Context, userID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDelete indicates an expected call of SoftDelete
+func (mr *MockUserPGRepositoryMockRecorder) SoftDelete(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockUserPGRepository)(nil).SoftDelete), ctx, userID)
+}
+
+// Restore mocks base method
+func (m *MockUserPGRepository) Restore(ctx context.Context, userID uuid.UUID, deletedAfter time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, userID, deletedAfter)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockUserPGRepositoryMockRecorder) Restore(ctx, userID, deletedAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUserPGRepository)(nil).Restore), ctx, userID, deletedAfter)
+}
+
+// PurgeDeleted mocks base method
+func (m *MockUserPGRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted
+func (mr *MockUserPGRepositoryMockRecorder) PurgeDeleted(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockUserPGRepository)(nil).PurgeDeleted), ctx, deletedBefore)
+}
+
+// UpdatePassword mocks base method
+func (m *MockUserPGRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword
+func (mr *MockUserPGRepositoryMockRecorder) UpdatePassword(ctx, userID, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserPGRepository)(nil).UpdatePassword), ctx, userID, passwordHash)
+}
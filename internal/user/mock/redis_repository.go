@@ -76,3 +76,33 @@ func (mr *MockUserRedisRepositoryMockRecorder) DeleteUserCtx(ctx, key interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserCtx", reflect.TypeOf((*MockUserRedisRepository)(nil).DeleteUserCtx), ctx, key)
 }
+
+// CreateTokenCtx mocks base method
+func (m *MockUserRedisRepository) CreateTokenCtx(ctx context.Context, token *models.UserToken, seconds int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTokenCtx", ctx, token, seconds)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTokenCtx indicates an expected call of CreateTokenCtx
+func (mr *MockUserRedisRepositoryMockRecorder) CreateTokenCtx(ctx, token, seconds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTokenCtx", reflect.TypeOf((*MockUserRedisRepository)(nil).CreateTokenCtx), ctx, token, seconds)
+}
+
+// TakeTokenCtx mocks base method
+func (m *MockUserRedisRepository) TakeTokenCtx(ctx context.Context, purpose, token string) (*models.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeTokenCtx", ctx, purpose, token)
+	ret0, _ := ret[0].(*models.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeTokenCtx indicates an expected call of TakeTokenCtx
+func (mr *MockUserRedisRepositoryMockRecorder) TakeTokenCtx(ctx, purpose, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeTokenCtx", reflect.TypeOf((*MockUserRedisRepository)(nil).TakeTokenCtx), ctx, purpose, token)
+}
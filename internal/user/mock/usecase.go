@@ -94,3 +94,222 @@ func (mr *MockUserUseCaseMockRecorder) FindById(ctx, userID interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockUserUseCase)(nil).FindById), ctx, userID)
 }
+
+// FindByIdUnscoped mocks base method
+func (m *MockUserUseCase) FindByIdUnscoped(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdUnscoped", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdUnscoped indicates an expected call of FindByIdUnscoped
+func (mr *MockUserUseCaseMockRecorder) FindByIdUnscoped(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdUnscoped", reflect.TypeOf((*MockUserUseCase)(nil).FindByIdUnscoped), ctx, userID)
+}
+
+// Update mocks base method
+func (m *MockUserUseCase) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, user)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockUserUseCaseMockRecorder) Update(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserUseCase)(nil).Update), ctx, user)
+}
+
+// List mocks base method
+func (m *MockUserUseCase) List(ctx context.Context, filter *models.UserListFilter) (*models.UserList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].(*models.UserList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockUserUseCaseMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserUseCase)(nil).List), ctx, filter)
+}
+
+// Search mocks base method
+func (m *MockUserUseCase) Search(ctx context.Context, filter *models.UserSearchFilter) (*models.UserSearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, filter)
+	ret0, _ := ret[0].(*models.UserSearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search
+func (mr *MockUserUseCaseMockRecorder) Search(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserUseCase)(nil).Search), ctx, filter)
+}
+
+// VerifyEmail mocks base method
+func (m *MockUserUseCase) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail
+func (mr *MockUserUseCaseMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserUseCase)(nil).VerifyEmail), ctx, token)
+}
+
+// ResendVerification mocks base method
+func (m *MockUserUseCase) ResendVerification(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendVerification", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendVerification indicates an expected call of ResendVerification
+func (mr *MockUserUseCaseMockRecorder) ResendVerification(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerification", reflect.TypeOf((*MockUserUseCase)(nil).ResendVerification), ctx, email)
+}
+
+// RequestPasswordReset mocks base method
+func (m *MockUserUseCase) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset
+func (mr *MockUserUseCaseMockRecorder) RequestPasswordReset(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockUserUseCase)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResetPassword mocks base method
+func (m *MockUserUseCase) ResetPassword(ctx context.Context, token, newPassword string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, newPassword)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (mr *MockUserUseCaseMockRecorder) ResetPassword(ctx, token, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserUseCase)(nil).ResetPassword), ctx, token, newPassword)
+}
+
+// ChangePassword mocks base method
+func (m *MockUserUseCase) ChangePassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, oldPassword, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword
+func (mr *MockUserUseCaseMockRecorder) ChangePassword(ctx, userID, oldPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserUseCase)(nil).ChangePassword), ctx, userID, oldPassword, newPassword)
+}
+
+// RequestEmailChange mocks base method
+func (m *MockUserUseCase) RequestEmailChange(ctx context.Context, userID uuid.UUID, newEmail string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmailChange", ctx, userID, newEmail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestEmailChange indicates an expected call of RequestEmailChange
+func (mr *MockUserUseCaseMockRecorder) RequestEmailChange(ctx, userID, newEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmailChange", reflect.TypeOf((*MockUserUseCase)(nil).RequestEmailChange), ctx, userID, newEmail)
+}
+
+// ConfirmEmailChange mocks base method
+func (m *MockUserUseCase) ConfirmEmailChange(ctx context.Context, token string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", ctx, token)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange
+func (mr *MockUserUseCaseMockRecorder) ConfirmEmailChange(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockUserUseCase)(nil).ConfirmEmailChange), ctx, token)
+}
+
+// DeleteAccount mocks base method
+func (m *MockUserUseCase) DeleteAccount(ctx context.Context, userID uuid.UUID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount
+func (mr *MockUserUseCaseMockRecorder) DeleteAccount(ctx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockUserUseCase)(nil).DeleteAccount), ctx, userID, password)
+}
+
+// Delete mocks base method
+func (m *MockUserUseCase) Delete(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockUserUseCaseMockRecorder) Delete(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserUseCase)(nil).Delete), ctx, userID)
+}
+
+// Restore mocks base method
+func (m *MockUserUseCase) Restore(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockUserUseCaseMockRecorder) Restore(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUserUseCase)(nil).Restore), ctx, userID)
+}
+
+// PurgeDeleted mocks base method
+func (m *MockUserUseCase) PurgeDeleted(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted
+func (mr *MockUserUseCaseMockRecorder) PurgeDeleted(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockUserUseCase)(nil).PurgeDeleted), ctx)
+}
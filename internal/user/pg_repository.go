@@ -3,6 +3,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -14,4 +15,15 @@ type UserPGRepository interface {
 	Create(ctx context.Context, user *models.User) (*models.User, error)
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	FindById(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	FindByIdUnscoped(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	Update(ctx context.Context, user *models.User) (*models.User, error)
+	List(ctx context.Context, filter *models.UserListFilter) (*models.UserList, error)
+	Search(ctx context.Context, filter *models.UserSearchFilter) (*models.UserSearchPage, error)
+	SetEmailVerified(ctx context.Context, userID uuid.UUID) error
+	UpdateEmail(ctx context.Context, userID uuid.UUID, oldEmail string, newEmail string) (bool, error)
+	FindPasswordHash(ctx context.Context, userID uuid.UUID) (string, error)
+	SoftDelete(ctx context.Context, userID uuid.UUID) (bool, error)
+	Restore(ctx context.Context, userID uuid.UUID, deletedAfter time.Time) (bool, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
 }
@@ -12,4 +12,6 @@ type UserRedisRepository interface {
 	GetByIDCtx(ctx context.Context, key string) (*models.User, error)
 	SetUserCtx(ctx context.Context, key string, seconds int, user *models.User) error
 	DeleteUserCtx(ctx context.Context, key string) error
+	CreateTokenCtx(ctx context.Context, token *models.UserToken, seconds int) (string, error)
+	TakeTokenCtx(ctx context.Context, purpose string, token string) (*models.UserToken, error)
 }
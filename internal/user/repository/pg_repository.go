@@ -2,13 +2,20 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx"
 	"github.com/jmoiron/sqlx"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+)
+
+const (
+	uniqueViolationCode = "23505"
 )
 
 // User repository
@@ -21,25 +28,46 @@ func NewUserPGRepository(db *sqlx.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// Create new user
+// Create new user with its roles
 func (r *UserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.Create")
 	defer span.Finish()
 
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Create.BeginTxx")
+	}
+	defer tx.Rollback()
+
 	createdUser := &models.User{}
-	if err := r.db.QueryRowxContext(
+	if err := tx.QueryRowxContext(
 		ctx,
 		createUserQuery,
 		user.FirstName,
 		user.LastName,
 		user.Email,
 		user.Password,
-		user.Role,
 		user.Avatar,
 	).StructScan(createdUser); err != nil {
+		// email may still be held by a deleted account waiting for purge
+		var pgErr pgx.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return nil, errors.Wrap(grpc_errors.ErrEmailExists, "Create.QueryRowxContext")
+		}
 		return nil, errors.Wrap(err, "Create.QueryRowxContext")
 	}
 
+	for _, role := range user.Roles {
+		if _, err := tx.ExecContext(ctx, assignUserRoleQuery, createdUser.UserID, role); err != nil {
+			return nil, errors.Wrap(err, "Create.ExecContext")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "Create.Commit")
+	}
+	createdUser.Roles = user.Roles
+
 	return createdUser, nil
 }
 
@@ -52,6 +80,9 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models
 	if err := r.db.GetContext(ctx, user, findByEmailQuery, email); err != nil {
 		return nil, errors.Wrap(err, "FindByEmail.GetContext")
 	}
+	if err := r.loadRoles(ctx, user); err != nil {
+		return nil, errors.Wrap(err, "FindByEmail.loadRoles")
+	}
 
 	return user, nil
 }
@@ -65,6 +96,313 @@ func (r *UserRepository) FindById(ctx context.Context, userID uuid.UUID) (*model
 	if err := r.db.GetContext(ctx, user, findByIDQuery, userID); err != nil {
 		return nil, errors.Wrap(err, "FindById.GetContext")
 	}
+	if err := r.loadRoles(ctx, user); err != nil {
+		return nil, errors.Wrap(err, "FindById.loadRoles")
+	}
+
+	return user, nil
+}
+
+// Find user by uuid including users deleted during grace period
+func (r *UserRepository) FindByIdUnscoped(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.FindByIdUnscoped")
+	defer span.Finish()
+
+	user := &models.User{}
+	if err := r.db.GetContext(ctx, user, findByIDUnscopedQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "FindByIdUnscoped.GetContext")
+	}
+	if err := r.loadRoles(ctx, user); err != nil {
+		return nil, errors.Wrap(err, "FindByIdUnscoped.loadRoles")
+	}
 
 	return user, nil
 }
+
+// List page of users matching filter ordered by creation time, users have their roles and permissions loaded
+func (r *UserRepository) List(ctx context.Context, filter *models.UserListFilter) (*models.UserList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.List")
+	defer span.Finish()
+
+	query := listUsersAscQuery
+	if filter.SortOrder == models.SortOrderDesc {
+		query = listUsersDescQuery
+	}
+
+	var prefix string
+	if filter.Prefix != "" {
+		prefix = models.EscapeLikePattern(filter.Prefix) + "%"
+	}
+	var cursorCreatedAt *time.Time
+	cursorUserID := uuid.Nil
+	if filter.Cursor != nil {
+		cursorCreatedAt, cursorUserID = &filter.Cursor.CreatedAt, filter.Cursor.UserID
+	}
+
+	// one extra row tells if there is a next page
+	users := make([]*models.User, 0, filter.Limit+1)
+	if err := r.db.SelectContext(
+		ctx,
+		&users,
+		query,
+		filter.Role,
+		filter.Status,
+		filter.CreatedAfter,
+		filter.CreatedBefore,
+		prefix,
+		cursorCreatedAt,
+		cursorUserID,
+		filter.Limit+1,
+	); err != nil {
+		return nil, errors.Wrap(err, "List.SelectContext")
+	}
+
+	list := &models.UserList{Users: users}
+	if len(users) > filter.Limit {
+		list.Users = users[:filter.Limit]
+		last := list.Users[len(list.Users)-1]
+		list.NextCursor = &models.UserListCursor{CreatedAt: last.CreatedAt, UserID: last.UserID}
+	}
+
+	if err := r.loadUsersRoles(ctx, list.Users); err != nil {
+		return nil, errors.Wrap(err, "List.loadUsersRoles")
+	}
+
+	return list, nil
+}
+
+// Search users by trigram similarity of names and email to query, substring matches are always included
+func (r *UserRepository) Search(ctx context.Context, filter *models.UserSearchFilter) (*models.UserSearchPage, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.Search")
+	defer span.Finish()
+
+	pattern := "%" + models.EscapeLikePattern(filter.Query) + "%"
+
+	// one extra row tells if there is a next page
+	results := make([]*models.UserSearchResult, 0, filter.Limit+1)
+	if err := r.db.SelectContext(ctx, &results, searchUsersQuery, filter.Query, pattern, filter.Limit+1, filter.Offset); err != nil {
+		return nil, errors.Wrap(err, "Search.SelectContext")
+	}
+
+	page := &models.UserSearchPage{Results: results}
+	if len(results) > filter.Limit {
+		page.Results = results[:filter.Limit]
+		page.HasMore = true
+	}
+
+	users := make([]*models.User, 0, len(page.Results))
+	for _, result := range page.Results {
+		users = append(users, &result.User)
+	}
+	if err := r.loadUsersRoles(ctx, users); err != nil {
+		return nil, errors.Wrap(err, "Search.loadUsersRoles")
+	}
+
+	return page, nil
+}
+
+// Value of user owned row, like role or permission
+type userValue struct {
+	UserID uuid.UUID `db:"user_id"`
+	Value  string    `db:"value"`
+}
+
+// Load roles and permissions of several users with one query each
+func (r *UserRepository) loadUsersRoles(ctx context.Context, users []*models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(users))
+	byID := make(map[uuid.UUID]*models.User, len(users))
+	for _, user := range users {
+		user.Roles = make([]string, 0)
+		user.Permissions = make([]string, 0)
+		userIDs = append(userIDs, user.UserID)
+		byID[user.UserID] = user
+	}
+
+	roles, err := r.selectUserValues(ctx, findUsersRolesQuery, userIDs)
+	if err != nil {
+		return errors.Wrap(err, "findUsersRolesQuery")
+	}
+	for _, role := range roles {
+		byID[role.UserID].Roles = append(byID[role.UserID].Roles, role.Value)
+	}
+
+	permissions, err := r.selectUserValues(ctx, findUsersPermissionsQuery, userIDs)
+	if err != nil {
+		return errors.Wrap(err, "findUsersPermissionsQuery")
+	}
+	for _, permission := range permissions {
+		byID[permission.UserID].Permissions = append(byID[permission.UserID].Permissions, permission.Value)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) selectUserValues(ctx context.Context, query string, userIDs []uuid.UUID) ([]*userValue, error) {
+	query, args, err := sqlx.In(query, userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlx.In")
+	}
+
+	values := make([]*userValue, 0)
+	if err := r.db.SelectContext(ctx, &values, r.db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrap(err, "SelectContext")
+	}
+
+	return values, nil
+}
+
+// Load user roles and permissions granted by them
+func (r *UserRepository) loadRoles(ctx context.Context, user *models.User) error {
+	user.Roles = make([]string, 0)
+	if err := r.db.SelectContext(ctx, &user.Roles, findUserRolesQuery, user.UserID); err != nil {
+		return errors.Wrap(err, "findUserRolesQuery")
+	}
+
+	user.Permissions = make([]string, 0)
+	if err := r.db.SelectContext(ctx, &user.Permissions, findUserPermissionsQuery, user.UserID); err != nil {
+		return errors.Wrap(err, "findUserPermissionsQuery")
+	}
+
+	return nil
+}
+
+// Update user profile fields
+func (r *UserRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.Update")
+	defer span.Finish()
+
+	updatedUser := &models.User{}
+	if err := r.db.GetContext(
+		ctx,
+		updatedUser,
+		updateUserQuery,
+		user.UserID,
+		user.FirstName,
+		user.LastName,
+		user.Avatar,
+	); err != nil {
+		return nil, errors.Wrap(err, "Update.GetContext")
+	}
+	if err := r.loadRoles(ctx, updatedUser); err != nil {
+		return nil, errors.Wrap(err, "Update.loadRoles")
+	}
+
+	return updatedUser, nil
+}
+
+// Mark user as deleted, returns false if user does not exist or is already deleted
+func (r *UserRepository) SoftDelete(ctx context.Context, userID uuid.UUID) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.SoftDelete")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, softDeleteUserQuery, userID)
+	if err != nil {
+		return false, errors.Wrap(err, "SoftDelete.ExecContext")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "SoftDelete.RowsAffected")
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// Restore user deleted after given time, returns false if there is no such user
+func (r *UserRepository) Restore(ctx context.Context, userID uuid.UUID, deletedAfter time.Time) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.Restore")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, restoreUserQuery, userID, deletedAfter)
+	if err != nil {
+		return false, errors.Wrap(err, "Restore.ExecContext")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "Restore.RowsAffected")
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// Permanently delete users deleted before given time with all their data, returns number of purged users
+func (r *UserRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.PurgeDeleted")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, purgeDeletedUsersQuery, deletedBefore)
+	if err != nil {
+		return 0, errors.Wrap(err, "PurgeDeleted.ExecContext")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "PurgeDeleted.RowsAffected")
+	}
+
+	return rowsAffected, nil
+}
+
+// Mark email of user as verified
+func (r *UserRepository) SetEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.SetEmailVerified")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(ctx, setEmailVerifiedQuery, userID); err != nil {
+		return errors.Wrap(err, "SetEmailVerified.ExecContext")
+	}
+
+	return nil
+}
+
+// Replace verified email of user if it is still the old one, returns false if email was changed meanwhile
+func (r *UserRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, oldEmail string, newEmail string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.UpdateEmail")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, updateEmailQuery, userID, oldEmail, newEmail)
+	if err != nil {
+		var pgErr pgx.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return false, errors.Wrap(grpc_errors.ErrEmailExists, "UpdateEmail.ExecContext")
+		}
+		return false, errors.Wrap(err, "UpdateEmail.ExecContext")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "UpdateEmail.RowsAffected")
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// Find user password hash
+func (r *UserRepository) FindPasswordHash(ctx context.Context, userID uuid.UUID) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.FindPasswordHash")
+	defer span.Finish()
+
+	var passwordHash string
+	if err := r.db.GetContext(ctx, &passwordHash, findPasswordByIDQuery, userID); err != nil {
+		return "", errors.Wrap(err, "FindPasswordHash.GetContext")
+	}
+
+	return passwordHash, nil
+}
+
+// Replace user password hash
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.UpdatePassword")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(ctx, updatePasswordQuery, userID, passwordHash); err != nil {
+		return errors.Wrap(err, "UpdatePassword.ExecContext")
+	}
+
+	return nil
+}
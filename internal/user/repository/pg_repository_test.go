@@ -7,10 +7,13 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx"
 	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
 	"github.com/AleksK1NG/auth-microservice/internal/models"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
 )
 
 func TestUserRepository_Create(t *testing.T) {
@@ -25,14 +28,14 @@ func TestUserRepository_Create(t *testing.T) {
 
 	userPGRepository := NewUserPGRepository(sqlxDB)
 
-	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at"}
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "created_at", "updated_at"}
 	userUUID := uuid.New()
 	mockUser := &models.User{
 		UserID:    userUUID,
 		Email:     "email@gmail.com",
 		FirstName: "FirstName",
 		LastName:  "LastName",
-		Role:      "admin",
+		Roles:     []string{models.RoleAdmin},
 		Avatar:    nil,
 		Password:  "123456",
 	}
@@ -44,23 +47,26 @@ func TestUserRepository_Create(t *testing.T) {
 		mockUser.Email,
 		mockUser.Password,
 		mockUser.Avatar,
-		mockUser.Role,
 		time.Now(),
 		time.Now(),
 	)
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(createUserQuery).WithArgs(
 		mockUser.FirstName,
 		mockUser.LastName,
 		mockUser.Email,
 		mockUser.Password,
-		mockUser.Role,
 		mockUser.Avatar,
 	).WillReturnRows(rows)
+	mock.ExpectExec(assignUserRoleQuery).WithArgs(userUUID, models.RoleAdmin).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	createdUser, err := userPGRepository.Create(context.Background(), mockUser)
 	require.NoError(t, err)
 	require.NotNil(t, createdUser)
+	require.Equal(t, []string{models.RoleAdmin}, createdUser.Roles)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestUserRepository_FindByEmail(t *testing.T) {
@@ -75,14 +81,14 @@ func TestUserRepository_FindByEmail(t *testing.T) {
 
 	userPGRepository := NewUserPGRepository(sqlxDB)
 
-	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at"}
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "created_at", "updated_at"}
 	userUUID := uuid.New()
 	mockUser := &models.User{
 		UserID:    userUUID,
 		Email:     "email@gmail.com",
 		FirstName: "FirstName",
 		LastName:  "LastName",
-		Role:      "admin",
+		Roles:     []string{models.RoleAdmin},
 		Avatar:    nil,
 		Password:  "123456",
 	}
@@ -94,17 +100,22 @@ func TestUserRepository_FindByEmail(t *testing.T) {
 		mockUser.Email,
 		mockUser.Password,
 		mockUser.Avatar,
-		mockUser.Role,
 		time.Now(),
 		time.Now(),
 	)
 
 	mock.ExpectQuery(findByEmailQuery).WithArgs(mockUser.Email).WillReturnRows(rows)
+	mock.ExpectQuery(findUserRolesQuery).WithArgs(userUUID).WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(models.RoleAdmin))
+	mock.ExpectQuery(findUserPermissionsQuery).WithArgs(userUUID).WillReturnRows(
+		sqlmock.NewRows([]string{"permission"}).AddRow(models.PermissionUsersRead).AddRow(models.PermissionRolesManage),
+	)
 
 	foundUser, err := userPGRepository.FindByEmail(context.Background(), mockUser.Email)
 	require.NoError(t, err)
 	require.NotNil(t, foundUser)
 	require.Equal(t, foundUser.Email, mockUser.Email)
+	require.Equal(t, []string{models.RoleAdmin}, foundUser.Roles)
+	require.Equal(t, []string{models.PermissionUsersRead, models.PermissionRolesManage}, foundUser.Permissions)
 }
 
 func TestUserRepository_FindById(t *testing.T) {
@@ -119,14 +130,14 @@ func TestUserRepository_FindById(t *testing.T) {
 
 	userPGRepository := NewUserPGRepository(sqlxDB)
 
-	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at"}
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "created_at", "updated_at"}
 	userUUID := uuid.New()
 	mockUser := &models.User{
 		UserID:    userUUID,
 		Email:     "email@gmail.com",
 		FirstName: "FirstName",
 		LastName:  "LastName",
-		Role:      "admin",
+		Roles:     []string{models.RoleAdmin},
 		Avatar:    nil,
 		Password:  "123456",
 	}
@@ -138,15 +149,264 @@ func TestUserRepository_FindById(t *testing.T) {
 		mockUser.Email,
 		mockUser.Password,
 		mockUser.Avatar,
-		mockUser.Role,
 		time.Now(),
 		time.Now(),
 	)
 
 	mock.ExpectQuery(findByIDQuery).WithArgs(mockUser.UserID).WillReturnRows(rows)
+	mock.ExpectQuery(findUserRolesQuery).WithArgs(userUUID).WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(models.RoleUser))
+	mock.ExpectQuery(findUserPermissionsQuery).WithArgs(userUUID).WillReturnRows(sqlmock.NewRows([]string{"permission"}))
 
 	foundUser, err := userPGRepository.FindById(context.Background(), mockUser.UserID)
 	require.NoError(t, err)
 	require.NotNil(t, foundUser)
 	require.Equal(t, foundUser.UserID, mockUser.UserID)
+	require.Equal(t, []string{models.RoleUser}, foundUser.Roles)
+	require.Empty(t, foundUser.Permissions)
+}
+
+func TestUserRepository_UpdatePassword(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	userUUID := uuid.New()
+	mock.ExpectExec(updatePasswordQuery).WithArgs(userUUID, "hash").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = userPGRepository.UpdatePassword(context.Background(), userUUID, "hash")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_Update(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	columns := []string{"user_id", "email", "email_verified", "first_name", "last_name", "avatar", "created_at", "updated_at"}
+	userUUID := uuid.New()
+	mockUser := &models.User{
+		UserID:    userUUID,
+		FirstName: "FirstName",
+		LastName:  "LastName",
+	}
+
+	rows := sqlmock.NewRows(columns).AddRow(
+		userUUID,
+		"email@gmail.com",
+		true,
+		mockUser.FirstName,
+		mockUser.LastName,
+		nil,
+		time.Now(),
+		time.Now(),
+	)
+
+	mock.ExpectQuery(updateUserQuery).WithArgs(userUUID, mockUser.FirstName, mockUser.LastName, mockUser.Avatar).WillReturnRows(rows)
+	mock.ExpectQuery(findUserRolesQuery).WithArgs(userUUID).WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(models.RoleUser))
+	mock.ExpectQuery(findUserPermissionsQuery).WithArgs(userUUID).WillReturnRows(sqlmock.NewRows([]string{"permission"}))
+
+	updatedUser, err := userPGRepository.Update(context.Background(), mockUser)
+	require.NoError(t, err)
+	require.Equal(t, mockUser.FirstName, updatedUser.FirstName)
+	require.Equal(t, []string{models.RoleUser}, updatedUser.Roles)
+}
+
+func TestUserRepository_UpdateEmail(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	userUUID := uuid.New()
+
+	mock.ExpectExec(updateEmailQuery).WithArgs(userUUID, "old@gmail.com", "new@gmail.com").WillReturnResult(sqlmock.NewResult(0, 1))
+	updated, err := userPGRepository.UpdateEmail(context.Background(), userUUID, "old@gmail.com", "new@gmail.com")
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	mock.ExpectExec(updateEmailQuery).WithArgs(userUUID, "old@gmail.com", "taken@gmail.com").WillReturnError(pgx.PgError{Code: uniqueViolationCode})
+	updated, err = userPGRepository.UpdateEmail(context.Background(), userUUID, "old@gmail.com", "taken@gmail.com")
+	require.False(t, updated)
+	require.True(t, errors.Is(err, grpc_errors.ErrEmailExists))
+}
+
+func TestUserRepository_SoftDelete(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	userUUID := uuid.New()
+	deletedAfter := time.Now()
+
+	mock.ExpectExec(softDeleteUserQuery).WithArgs(userUUID).WillReturnResult(sqlmock.NewResult(0, 1))
+	deleted, err := userPGRepository.SoftDelete(context.Background(), userUUID)
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	mock.ExpectExec(restoreUserQuery).WithArgs(userUUID, deletedAfter).WillReturnResult(sqlmock.NewResult(0, 0))
+	restored, err := userPGRepository.Restore(context.Background(), userUUID, deletedAfter)
+	require.NoError(t, err)
+	require.False(t, restored)
+
+	mock.ExpectExec(purgeDeletedUsersQuery).WithArgs(deletedAfter).WillReturnResult(sqlmock.NewResult(0, 2))
+	purged, err := userPGRepository.PurgeDeleted(context.Background(), deletedAfter)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), purged)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_List(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	columns := []string{"user_id", "email", "email_verified", "first_name", "last_name", "avatar", "created_at", "updated_at"}
+	firstID, secondID, thirdID := uuid.New(), uuid.New(), uuid.New()
+	createdAt := time.Now()
+
+	t.Run("NextPage", func(t *testing.T) {
+		cursor := &models.UserListCursor{CreatedAt: createdAt.Add(time.Hour), UserID: uuid.New()}
+		filter := &models.UserListFilter{Role: models.RoleUser, Prefix: "jo_", SortOrder: models.SortOrderDesc, Limit: 2, Cursor: cursor}
+
+		rows := sqlmock.NewRows(columns).
+			AddRow(firstID, "john@gmail.com", true, "John", "Doe", nil, createdAt, createdAt).
+			AddRow(secondID, "joe@gmail.com", true, "Joe", "Doe", nil, createdAt.Add(-time.Minute), createdAt).
+			AddRow(thirdID, "jo@gmail.com", false, "Jo", "Doe", nil, createdAt.Add(-time.Hour), createdAt)
+
+		mock.ExpectQuery(listUsersDescQuery).
+			WithArgs(models.RoleUser, "", nil, nil, `jo\_%`, cursor.CreatedAt, cursor.UserID, 3).
+			WillReturnRows(rows)
+		mock.ExpectQuery("SELECT user_id, role AS value FROM user_roles WHERE user_id IN (?, ?) ORDER BY role").
+			WithArgs(firstID, secondID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "value"}).AddRow(firstID, models.RoleUser).AddRow(secondID, models.RoleUser))
+		mock.ExpectQuery(`SELECT DISTINCT ur.user_id, rp.permission AS value FROM role_permissions rp 
+		JOIN user_roles ur ON ur.role = rp.role 
+		WHERE ur.user_id IN (?, ?) ORDER BY value`).
+			WithArgs(firstID, secondID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "value"}))
+
+		list, err := userPGRepository.List(context.Background(), filter)
+		require.NoError(t, err)
+		require.Len(t, list.Users, 2)
+		require.Equal(t, []string{models.RoleUser}, list.Users[1].Roles)
+		require.Empty(t, list.Users[0].Permissions)
+		require.NotNil(t, list.NextCursor)
+		require.Equal(t, secondID, list.NextCursor.UserID)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("LastPage", func(t *testing.T) {
+		filter := &models.UserListFilter{Status: models.UserStatusDeleted, SortOrder: models.SortOrderAsc, Limit: 2}
+
+		mock.ExpectQuery(listUsersAscQuery).
+			WithArgs("", models.UserStatusDeleted, nil, nil, "", nil, uuid.Nil, 3).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		list, err := userPGRepository.List(context.Background(), filter)
+		require.NoError(t, err)
+		require.Empty(t, list.Users)
+		require.Nil(t, list.NextCursor)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepository_Search(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	columns := []string{"user_id", "email", "email_verified", "first_name", "last_name", "avatar", "created_at", "updated_at", "rank"}
+	firstID, secondID := uuid.New(), uuid.New()
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(firstID, "john@gmail.com", true, "John", "Doe", nil, time.Now(), time.Now(), 1.0).
+		AddRow(secondID, "johanna@gmail.com", true, "Johanna", "Smith", nil, time.Now(), time.Now(), 0.6)
+
+	mock.ExpectQuery(searchUsersQuery).WithArgs("john", "%john%", 3, 10).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT user_id, role AS value FROM user_roles WHERE user_id IN (?, ?) ORDER BY role").
+		WithArgs(firstID, secondID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "value"}).AddRow(secondID, models.RoleAdmin))
+	mock.ExpectQuery(`SELECT DISTINCT ur.user_id, rp.permission AS value FROM role_permissions rp 
+		JOIN user_roles ur ON ur.role = rp.role 
+		WHERE ur.user_id IN (?, ?) ORDER BY value`).
+		WithArgs(firstID, secondID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "value"}))
+
+	page, err := userPGRepository.Search(context.Background(), &models.UserSearchFilter{Query: "john", Limit: 2, Offset: 10})
+	require.NoError(t, err)
+	require.False(t, page.HasMore)
+	require.Len(t, page.Results, 2)
+	require.Equal(t, firstID, page.Results[0].UserID)
+	require.Equal(t, 1.0, page.Results[0].Rank)
+	require.Equal(t, []string{models.RoleAdmin}, page.Results[1].Roles)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_FindByIdUnscoped(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	userUUID := uuid.New()
+	rows := sqlmock.NewRows([]string{"user_id", "email", "first_name", "last_name", "created_at", "updated_at"}).
+		AddRow(userUUID, "deleted@gmail.com", "FirstName", "LastName", time.Now(), time.Now())
+
+	mock.ExpectQuery(findByIDUnscopedQuery).WithArgs(userUUID).WillReturnRows(rows)
+	mock.ExpectQuery(findUserRolesQuery).WithArgs(userUUID).WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(models.RoleUser))
+	mock.ExpectQuery(findUserPermissionsQuery).WithArgs(userUUID).WillReturnRows(sqlmock.NewRows([]string{"permission"}))
+
+	foundUser, err := userPGRepository.FindByIdUnscoped(context.Background(), userUUID)
+	require.NoError(t, err)
+	require.Equal(t, userUUID, foundUser.UserID)
+	require.Equal(t, []string{models.RoleUser}, foundUser.Roles)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
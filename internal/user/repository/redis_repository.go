@@ -2,18 +2,29 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
 	"github.com/AleksK1NG/auth-microservice/pkg/logger"
 )
 
+const (
+	userTokenPrefix      = "user_tokens:"
+	userTokenIndexPrefix = "user_token_index:"
+	userTokenBytes       = 32
+)
+
 // Auth redis repository
 type userRedisRepo struct {
 	redisClient *redis.Client
@@ -67,6 +78,83 @@ func (r *userRedisRepo) DeleteUserCtx(ctx context.Context, key string) error {
 	return r.redisClient.Del(ctx, r.createKey(key)).Err()
 }
 
+// Create one-time token with duration in seconds, returns plain token and stores only its hash.
+// User has one active token per purpose, previous token of the same purpose is deleted.
+func (r *userRedisRepo) CreateTokenCtx(ctx context.Context, token *models.UserToken, seconds int) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "userRedisRepo.CreateTokenCtx")
+	defer span.Finish()
+
+	tokenBytes := make([]byte, userTokenBytes)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", errors.Wrap(err, "userRedisRepo.CreateTokenCtx.rand.Read")
+	}
+	plainToken := base64.RawURLEncoding.EncodeToString(tokenBytes)
+	tokenHash := hashUserToken(plainToken)
+
+	tokenData, err := json.Marshal(token)
+	if err != nil {
+		return "", errors.WithMessage(err, "userRedisRepo.CreateTokenCtx.json.Marshal")
+	}
+
+	indexKey := r.createTokenIndexKey(token.Purpose, token.UserID.String())
+	previousHash, err := r.redisClient.Get(ctx, indexKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", errors.Wrap(err, "userRedisRepo.CreateTokenCtx.Get")
+	}
+
+	expire := time.Second * time.Duration(seconds)
+	pipe := r.redisClient.TxPipeline()
+	if previousHash != "" {
+		pipe.Del(ctx, r.createTokenKey(token.Purpose, previousHash))
+	}
+	pipe.Set(ctx, r.createTokenKey(token.Purpose, tokenHash), tokenData, expire)
+	pipe.Set(ctx, indexKey, tokenHash, expire)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", errors.Wrap(err, "userRedisRepo.CreateTokenCtx.Exec")
+	}
+
+	return plainToken, nil
+}
+
+// Get and delete one-time token, returns redis.Nil if it does not exist, expired or was already used
+func (r *userRedisRepo) TakeTokenCtx(ctx context.Context, purpose string, token string) (*models.UserToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "userRedisRepo.TakeTokenCtx")
+	defer span.Finish()
+
+	tokenKey := r.createTokenKey(purpose, hashUserToken(token))
+	pipe := r.redisClient.TxPipeline()
+	get := pipe.Get(ctx, tokenKey)
+	pipe.Del(ctx, tokenKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errors.Wrap(err, "userRedisRepo.TakeTokenCtx.Exec")
+	}
+
+	tokenBytes, err := get.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "userRedisRepo.TakeTokenCtx.Get")
+	}
+
+	userToken := &models.UserToken{}
+	if err := json.Unmarshal(tokenBytes, userToken); err != nil {
+		return nil, errors.Wrap(err, "userRedisRepo.TakeTokenCtx.json.Unmarshal")
+	}
+
+	return userToken, nil
+}
+
+func (r *userRedisRepo) createTokenKey(purpose string, tokenHash string) string {
+	return fmt.Sprintf("%s%s: %s", userTokenPrefix, purpose, tokenHash)
+}
+
+func (r *userRedisRepo) createTokenIndexKey(purpose string, userID string) string {
+	return fmt.Sprintf("%s%s: %s", userTokenIndexPrefix, purpose, userID)
+}
+
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func (r *userRedisRepo) createKey(value string) string {
 	return fmt.Sprintf("%s: %s", r.basePrefix, value)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/alicebob/miniredis"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
 	"github.com/AleksK1NG/auth-microservice/internal/models"
@@ -74,3 +75,33 @@ func TestUserRedisRepo_DeleteUserCtx(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestUserRedisRepo_TakeTokenCtx(t *testing.T) {
+	t.Parallel()
+
+	redisRepo := SetupRedis()
+
+	t.Run("TakeTokenCtx", func(t *testing.T) {
+		userToken := &models.UserToken{
+			UserID:  uuid.New(),
+			Purpose: models.TokenPurposeEmailVerification,
+			Email:   "email@gmail.com",
+		}
+
+		first, err := redisRepo.CreateTokenCtx(context.Background(), userToken, 10)
+		require.NoError(t, err)
+		second, err := redisRepo.CreateTokenCtx(context.Background(), userToken, 10)
+		require.NoError(t, err)
+		require.NotEqual(t, first, second)
+
+		_, err = redisRepo.TakeTokenCtx(context.Background(), models.TokenPurposeEmailVerification, first)
+		require.True(t, errors.Is(err, redis.Nil))
+
+		found, err := redisRepo.TakeTokenCtx(context.Background(), models.TokenPurposeEmailVerification, second)
+		require.NoError(t, err)
+		require.Equal(t, userToken, found)
+
+		_, err = redisRepo.TakeTokenCtx(context.Background(), models.TokenPurposeEmailVerification, second)
+		require.True(t, errors.Is(err, redis.Nil))
+	})
+}
@@ -1,11 +1,72 @@
 package repository
 
 const (
-	createUserQuery = `INSERT INTO users (first_name, last_name, email, password, role, avatar) 
-		VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), null)) 
-		RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role`
+	createUserQuery = `INSERT INTO users (first_name, last_name, email, password, avatar) 
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), null)) 
+		RETURNING user_id, first_name, last_name, email, email_verified, password, avatar, created_at, updated_at`
 
-	findByEmailQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at FROM users WHERE email = $1`
+	assignUserRoleQuery = `INSERT INTO user_roles (user_id, role) VALUES ($1, $2)`
 
-	findByIDQuery = `SELECT user_id, email, first_name, last_name, role, avatar, created_at, updated_at FROM users WHERE user_id = $1`
+	findByEmailQuery = `SELECT user_id, email, email_verified, first_name, last_name, avatar, password, created_at, updated_at FROM users WHERE email = $1 AND deleted_at IS NULL`
+
+	findByIDQuery = `SELECT user_id, email, email_verified, first_name, last_name, avatar, created_at, updated_at FROM users WHERE user_id = $1 AND deleted_at IS NULL`
+
+	findByIDUnscopedQuery = `SELECT user_id, email, email_verified, first_name, last_name, avatar, created_at, updated_at FROM users WHERE user_id = $1`
+
+	updateUserQuery = `UPDATE users SET first_name = $2, last_name = $3, avatar = COALESCE(NULLIF($4, ''), null), updated_at = now() 
+		WHERE user_id = $1 AND deleted_at IS NULL 
+		RETURNING user_id, email, email_verified, first_name, last_name, avatar, created_at, updated_at`
+
+	updateEmailQuery = `UPDATE users SET email = $3, email_verified = TRUE, updated_at = now() WHERE user_id = $1 AND email = $2 AND deleted_at IS NULL`
+
+	setEmailVerifiedQuery = `UPDATE users SET email_verified = TRUE, updated_at = now() WHERE user_id = $1`
+
+	findPasswordByIDQuery = `SELECT password FROM users WHERE user_id = $1 AND deleted_at IS NULL`
+
+	updatePasswordQuery = `UPDATE users SET password = $2, updated_at = now() WHERE user_id = $1`
+
+	softDeleteUserQuery = `UPDATE users SET deleted_at = now(), updated_at = now() WHERE user_id = $1 AND deleted_at IS NULL`
+
+	restoreUserQuery = `UPDATE users SET deleted_at = NULL, updated_at = now() WHERE user_id = $1 AND deleted_at > $2`
+
+	purgeDeletedUsersQuery = `DELETE FROM users WHERE deleted_at < $1`
+
+	listUsersFilter = `FROM users u 
+		WHERE ($1 = '' OR EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.user_id AND ur.role = $1)) 
+		AND CASE $2 
+			WHEN 'deleted' THEN u.deleted_at IS NOT NULL 
+			WHEN 'active' THEN u.deleted_at IS NULL AND u.email_verified 
+			WHEN 'unverified' THEN u.deleted_at IS NULL AND NOT u.email_verified 
+			ELSE u.deleted_at IS NULL END 
+		AND ($3::timestamptz IS NULL OR u.created_at >= $3) 
+		AND ($4::timestamptz IS NULL OR u.created_at < $4) 
+		AND ($5 = '' OR u.email ILIKE $5 OR u.first_name ILIKE $5 OR u.last_name ILIKE $5) `
+
+	listUsersColumns = `SELECT u.user_id, u.email, u.email_verified, u.first_name, u.last_name, u.avatar, u.created_at, u.updated_at `
+
+	listUsersAscQuery = listUsersColumns + listUsersFilter + `AND ($6::timestamptz IS NULL OR (u.created_at, u.user_id) > ($6, $7)) 
+		ORDER BY u.created_at, u.user_id LIMIT $8`
+
+	listUsersDescQuery = listUsersColumns + listUsersFilter + `AND ($6::timestamptz IS NULL OR (u.created_at, u.user_id) < ($6, $7)) 
+		ORDER BY u.created_at DESC, u.user_id DESC LIMIT $8`
+
+	searchUsersQuery = `SELECT u.user_id, u.email, u.email_verified, u.first_name, u.last_name, u.avatar, u.created_at, u.updated_at, 
+		word_similarity($1, u.first_name || ' ' || u.last_name || ' ' || u.email) AS rank 
+		FROM users u 
+		WHERE u.deleted_at IS NULL 
+		AND ($1 <% (u.first_name || ' ' || u.last_name || ' ' || u.email) OR (u.first_name || ' ' || u.last_name || ' ' || u.email) ILIKE $2) 
+		ORDER BY rank DESC, u.user_id LIMIT $3 OFFSET $4`
+
+	findUsersRolesQuery = `SELECT user_id, role AS value FROM user_roles WHERE user_id IN (?) ORDER BY role`
+
+	findUsersPermissionsQuery = `SELECT DISTINCT ur.user_id, rp.permission AS value FROM role_permissions rp 
+		JOIN user_roles ur ON ur.role = rp.role 
+		WHERE ur.user_id IN (?) ORDER BY value`
+
+	findUserRolesQuery = `SELECT role FROM user_roles WHERE user_id = $1 ORDER BY role`
+
+	findUserPermissionsQuery = `SELECT DISTINCT rp.permission FROM role_permissions rp 
+		JOIN user_roles ur ON ur.role = rp.role 
+		JOIN users u ON u.user_id = ur.user_id AND u.deleted_at IS NULL 
+		WHERE ur.user_id = $1 ORDER BY rp.permission`
 )
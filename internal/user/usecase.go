@@ -15,4 +15,19 @@ type UserUseCase interface {
 	Login(ctx context.Context, email string, password string) (*models.User, error)
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	FindById(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	FindByIdUnscoped(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	Update(ctx context.Context, user *models.User) (*models.User, error)
+	List(ctx context.Context, filter *models.UserListFilter) (*models.UserList, error)
+	Search(ctx context.Context, filter *models.UserSearchFilter) (*models.UserSearchPage, error)
+	VerifyEmail(ctx context.Context, token string) (*models.User, error)
+	ResendVerification(ctx context.Context, email string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, newPassword string) (*models.User, error)
+	ChangePassword(ctx context.Context, userID uuid.UUID, oldPassword string, newPassword string) error
+	RequestEmailChange(ctx context.Context, userID uuid.UUID, newEmail string) error
+	ConfirmEmailChange(ctx context.Context, token string) (*models.User, error)
+	DeleteAccount(ctx context.Context, userID uuid.UUID, password string) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+	Restore(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	PurgeDeleted(ctx context.Context) (int64, error)
 }
@@ -2,32 +2,49 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
+	"github.com/AleksK1NG/auth-microservice/config"
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 	"github.com/AleksK1NG/auth-microservice/internal/user"
 	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
 	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+	"github.com/AleksK1NG/auth-microservice/pkg/mailer"
 )
 
 const (
 	userByIdCacheDuration = 3600
+
+	defaultListUsersLimit = 20
+	maxListUsersLimit     = 100
 )
 
 // User UseCase
 type userUseCase struct {
 	logger     logger.Logger
+	cfg        *config.Config
 	userPgRepo user.UserPGRepository
 	redisRepo  user.UserRedisRepository
+	mailer     mailer.Mailer
 }
 
 // New User UseCase
-func NewUserUseCase(logger logger.Logger, userRepo user.UserPGRepository, redisRepo user.UserRedisRepository) *userUseCase {
-	return &userUseCase{logger: logger, userPgRepo: userRepo, redisRepo: redisRepo}
+func NewUserUseCase(
+	logger logger.Logger,
+	cfg *config.Config,
+	userRepo user.UserPGRepository,
+	redisRepo user.UserRedisRepository,
+	mailer mailer.Mailer,
+) *userUseCase {
+	return &userUseCase{logger: logger, cfg: cfg, userPgRepo: userRepo, redisRepo: redisRepo, mailer: mailer}
 }
 
 // Register new user
@@ -40,7 +57,304 @@ func (u *userUseCase) Register(ctx context.Context, user *models.User) (*models.
 		return nil, grpc_errors.ErrEmailExists
 	}
 
-	return u.userPgRepo.Create(ctx, user)
+	// registered users get the default role, other roles are assigned by administrators
+	user.Roles = []string{models.RoleUser}
+
+	createdUser, err := u.userPgRepo.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// registration succeeds even if email is not delivered, user can request it again
+	if err := u.sendVerification(ctx, createdUser); err != nil {
+		u.logger.Errorf("sendVerification: %v", err)
+	}
+
+	return createdUser, nil
+}
+
+// Verify email of user with token sent by email
+func (u *userUseCase) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.VerifyEmail")
+	defer span.Finish()
+
+	foundUser, err := u.takeToken(ctx, models.TokenPurposeEmailVerification, token)
+	if err != nil {
+		return nil, err
+	}
+	if foundUser.EmailVerified {
+		return foundUser, nil
+	}
+
+	if err := u.userPgRepo.SetEmailVerified(ctx, foundUser.UserID); err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.SetEmailVerified")
+	}
+	if err := u.redisRepo.DeleteUserCtx(ctx, foundUser.UserID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx: %v", err)
+	}
+	foundUser.EmailVerified = true
+
+	return foundUser, nil
+}
+
+// Send new verification email, previous token stops working. Unknown and already verified emails
+// and delivery failures are not reported, so the result does not reveal registered accounts.
+func (u *userUseCase) ResendVerification(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.ResendVerification")
+	defer span.Finish()
+
+	foundUser, err := u.userPgRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrap(err, "userPgRepo.FindByEmail")
+	}
+	if foundUser.EmailVerified {
+		return nil
+	}
+
+	if err := u.sendVerification(ctx, foundUser); err != nil {
+		u.logger.Errorf("sendVerification: %v", err)
+	}
+
+	return nil
+}
+
+// Send password reset email. Unknown emails and delivery failures are not reported,
+// so the result does not reveal registered accounts.
+func (u *userUseCase) RequestPasswordReset(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.RequestPasswordReset")
+	defer span.Finish()
+
+	foundUser, err := u.userPgRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrap(err, "userPgRepo.FindByEmail")
+	}
+
+	link, err := u.createTokenLink(ctx, foundUser, models.TokenPurposePasswordReset, u.cfg.PasswordReset.TokenExpire, "reset-password")
+	if err != nil {
+		return err
+	}
+
+	if err := u.mailer.Send(ctx, &mailer.Message{
+		To:      foundUser.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Hi %s,\n\nyou can set a new password by following the link:\n%s\n\nIf you did not request it, ignore this email.\n", foundUser.FirstName, link),
+	}); err != nil {
+		u.logger.Errorf("mailer.Send: %v", err)
+	}
+
+	return nil
+}
+
+// Set new password of user with token sent by email, cached user is invalidated.
+// Sessions of the user must be revoked by caller.
+func (u *userUseCase) ResetPassword(ctx context.Context, token string, newPassword string) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.ResetPassword")
+	defer span.Finish()
+
+	foundUser, err := u.takeToken(ctx, models.TokenPurposePasswordReset, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := foundUser.SetPassword(newPassword); err != nil {
+		return nil, errors.Wrap(err, "user.SetPassword")
+	}
+	if err := u.userPgRepo.UpdatePassword(ctx, foundUser.UserID, foundUser.Password); err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.UpdatePassword")
+	}
+	if err := u.redisRepo.DeleteUserCtx(ctx, foundUser.UserID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx: %v", err)
+	}
+
+	foundUser.SanitizePassword()
+
+	return foundUser, nil
+}
+
+// Change password of user after checking the current one, cached user is invalidated
+func (u *userUseCase) ChangePassword(ctx context.Context, userID uuid.UUID, oldPassword string, newPassword string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.ChangePassword")
+	defer span.Finish()
+
+	passwordHash, err := u.userPgRepo.FindPasswordHash(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "userPgRepo.FindPasswordHash")
+	}
+
+	foundUser := &models.User{UserID: userID, Password: passwordHash}
+	if err := foundUser.ComparePasswords(models.NormalizePassword(oldPassword)); err != nil {
+		return errors.Wrapf(grpc_errors.ErrInvalidPassword, "user.ComparePasswords: %v", err)
+	}
+	if err := foundUser.ComparePasswords(models.NormalizePassword(newPassword)); err == nil {
+		return grpc_errors.ErrSamePassword
+	}
+
+	if err := foundUser.SetPassword(newPassword); err != nil {
+		return errors.Wrap(err, "user.SetPassword")
+	}
+	if err := u.userPgRepo.UpdatePassword(ctx, userID, foundUser.Password); err != nil {
+		return errors.Wrap(err, "userPgRepo.UpdatePassword")
+	}
+	if err := u.redisRepo.DeleteUserCtx(ctx, userID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx: %v", err)
+	}
+
+	return nil
+}
+
+// Send confirmation token to the new email address, email is changed only after it is confirmed
+func (u *userUseCase) RequestEmailChange(ctx context.Context, userID uuid.UUID, newEmail string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.RequestEmailChange")
+	defer span.Finish()
+
+	foundUser, err := u.userPgRepo.FindById(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "userPgRepo.FindById")
+	}
+
+	newEmail = models.NormalizeEmail(newEmail)
+	if newEmail == foundUser.Email {
+		return grpc_errors.ErrEmailExists
+	}
+	existsUser, err := u.userPgRepo.FindByEmail(ctx, newEmail)
+	if existsUser != nil || err == nil {
+		return grpc_errors.ErrEmailExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return errors.Wrap(err, "userPgRepo.FindByEmail")
+	}
+
+	token, err := u.redisRepo.CreateTokenCtx(ctx, &models.UserToken{
+		UserID:   foundUser.UserID,
+		Purpose:  models.TokenPurposeEmailChange,
+		Email:    foundUser.Email,
+		NewEmail: newEmail,
+	}, u.cfg.EmailVerification.TokenExpire)
+	if err != nil {
+		return errors.Wrap(err, "redisRepo.CreateTokenCtx")
+	}
+
+	link := fmt.Sprintf("%s/confirm-email-change?token=%s", u.cfg.Mailer.BaseURL, url.QueryEscape(token))
+	if err := u.mailer.Send(ctx, &mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email",
+		Body:    fmt.Sprintf("Hi %s,\n\nplease confirm your new email address by following the link:\n%s\n", foundUser.FirstName, link),
+	}); err != nil {
+		return errors.Wrap(err, "mailer.Send")
+	}
+
+	return nil
+}
+
+// Replace email of user with the address confirmed by token, previous address is notified about the change
+func (u *userUseCase) ConfirmEmailChange(ctx context.Context, token string) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.ConfirmEmailChange")
+	defer span.Finish()
+
+	userToken, err := u.redisRepo.TakeTokenCtx(ctx, models.TokenPurposeEmailChange, token)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.Wrap(grpc_errors.ErrInvalidToken, "redisRepo.TakeTokenCtx")
+		}
+		return nil, errors.Wrap(err, "redisRepo.TakeTokenCtx")
+	}
+
+	updated, err := u.userPgRepo.UpdateEmail(ctx, userToken.UserID, userToken.Email, userToken.NewEmail)
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.UpdateEmail")
+	}
+	if !updated {
+		return nil, errors.Wrap(grpc_errors.ErrInvalidToken, "token was sent before another email change")
+	}
+	if err := u.redisRepo.DeleteUserCtx(ctx, userToken.UserID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx: %v", err)
+	}
+
+	foundUser, err := u.userPgRepo.FindById(ctx, userToken.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.FindById")
+	}
+
+	if err := u.mailer.Send(ctx, &mailer.Message{
+		To:      userToken.Email,
+		Subject: "Your email was changed",
+		Body:    fmt.Sprintf("Hi %s,\n\nemail of your account was changed to %s.\nIf you did not do it, contact support.\n", foundUser.FirstName, foundUser.Email),
+	}); err != nil {
+		u.logger.Errorf("mailer.Send: %v", err)
+	}
+
+	return foundUser, nil
+}
+
+// Delete account of user after checking the password
+func (u *userUseCase) DeleteAccount(ctx context.Context, userID uuid.UUID, password string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.DeleteAccount")
+	defer span.Finish()
+
+	passwordHash, err := u.userPgRepo.FindPasswordHash(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "userPgRepo.FindPasswordHash")
+	}
+	if err := (&models.User{Password: passwordHash}).ComparePasswords(models.NormalizePassword(password)); err != nil {
+		return errors.Wrapf(grpc_errors.ErrInvalidPassword, "user.ComparePasswords: %v", err)
+	}
+
+	return u.Delete(ctx, userID)
+}
+
+// Mark user as deleted, the account can be restored until grace period ends and is purged afterwards.
+// Sessions of the user must be revoked by caller.
+func (u *userUseCase) Delete(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.Delete")
+	defer span.Finish()
+
+	deleted, err := u.userPgRepo.SoftDelete(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "userPgRepo.SoftDelete")
+	}
+	if !deleted {
+		return errors.Wrap(sql.ErrNoRows, "userPgRepo.SoftDelete")
+	}
+	if err := u.redisRepo.DeleteUserCtx(ctx, userID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx: %v", err)
+	}
+
+	return nil
+}
+
+// Restore user deleted during grace period
+func (u *userUseCase) Restore(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.Restore")
+	defer span.Finish()
+
+	restored, err := u.userPgRepo.Restore(ctx, userID, time.Now().Add(-u.deletionGracePeriod()))
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.Restore")
+	}
+	if !restored {
+		return nil, errors.Wrap(sql.ErrNoRows, "userPgRepo.Restore")
+	}
+
+	return u.FindById(ctx, userID)
+}
+
+// Permanently delete users whose grace period ended
+func (u *userUseCase) PurgeDeleted(ctx context.Context) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.PurgeDeleted")
+	defer span.Finish()
+
+	purged, err := u.userPgRepo.PurgeDeleted(ctx, time.Now().Add(-u.deletionGracePeriod()))
+	if err != nil {
+		return 0, errors.Wrap(err, "userPgRepo.PurgeDeleted")
+	}
+
+	return purged, nil
 }
 
 // Find use by email address
@@ -83,6 +397,79 @@ func (u *userUseCase) FindById(ctx context.Context, userID uuid.UUID) (*models.U
 	return foundUser, nil
 }
 
+// Find user by uuid including users deleted during grace period, e.g. for admin data export. Not cached.
+func (u *userUseCase) FindByIdUnscoped(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.FindByIdUnscoped")
+	defer span.Finish()
+
+	foundUser, err := u.userPgRepo.FindByIdUnscoped(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.FindByIdUnscoped")
+	}
+
+	return foundUser, nil
+}
+
+// Update user profile fields, cached user is invalidated
+func (u *userUseCase) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.Update")
+	defer span.Finish()
+
+	updatedUser, err := u.userPgRepo.Update(ctx, user)
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.Update")
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, updatedUser.UserID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx: %v", err)
+	}
+
+	return updatedUser, nil
+}
+
+// List page of users matching filter, page size is limited to maxListUsersLimit and newest users go first by default
+func (u *userUseCase) List(ctx context.Context, filter *models.UserListFilter) (*models.UserList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.List")
+	defer span.Finish()
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListUsersLimit
+	}
+	if filter.Limit > maxListUsersLimit {
+		filter.Limit = maxListUsersLimit
+	}
+	if filter.SortOrder == "" {
+		filter.SortOrder = models.SortOrderDesc
+	}
+
+	list, err := u.userPgRepo.List(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.List")
+	}
+
+	return list, nil
+}
+
+// Search users ranked by relevance to query, page size is limited like in List
+func (u *userUseCase) Search(ctx context.Context, filter *models.UserSearchFilter) (*models.UserSearchPage, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.Search")
+	defer span.Finish()
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListUsersLimit
+	}
+	if filter.Limit > maxListUsersLimit {
+		filter.Limit = maxListUsersLimit
+	}
+
+	page, err := u.userPgRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.Search")
+	}
+
+	return page, nil
+}
+
 // Login user with email and password
 func (u *userUseCase) Login(ctx context.Context, email string, password string) (*models.User, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "UserUseCase.Login")
@@ -93,9 +480,65 @@ func (u *userUseCase) Login(ctx context.Context, email string, password string)
 		return nil, errors.Wrap(err, "userPgRepo.FindByEmail")
 	}
 
-	if err := foundUser.ComparePasswords(password); err != nil {
+	if err := foundUser.ComparePasswords(models.NormalizePassword(password)); err != nil {
 		return nil, errors.Wrap(err, "user.ComparePasswords")
 	}
 
 	return foundUser, err
 }
+
+func (u *userUseCase) deletionGracePeriod() time.Duration {
+	return time.Second * time.Duration(u.cfg.AccountDeletion.GracePeriod)
+}
+
+func (u *userUseCase) sendVerification(ctx context.Context, user *models.User) error {
+	link, err := u.createTokenLink(ctx, user, models.TokenPurposeEmailVerification, u.cfg.EmailVerification.TokenExpire, "verify-email")
+	if err != nil {
+		return err
+	}
+
+	if err := u.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body:    fmt.Sprintf("Hi %s,\n\nplease verify your email address by following the link:\n%s\n", user.FirstName, link),
+	}); err != nil {
+		return errors.Wrap(err, "mailer.Send")
+	}
+
+	return nil
+}
+
+// Create one-time token bound to current email of user and link to the given page with it
+func (u *userUseCase) createTokenLink(ctx context.Context, user *models.User, purpose string, expire int, path string) (string, error) {
+	token, err := u.redisRepo.CreateTokenCtx(ctx, &models.UserToken{
+		UserID:  user.UserID,
+		Purpose: purpose,
+		Email:   user.Email,
+	}, expire)
+	if err != nil {
+		return "", errors.Wrap(err, "redisRepo.CreateTokenCtx")
+	}
+
+	return fmt.Sprintf("%s/%s?token=%s", u.cfg.Mailer.BaseURL, path, url.QueryEscape(token)), nil
+}
+
+// Use one-time token and find its user, token is rejected if user email changed after it was sent
+func (u *userUseCase) takeToken(ctx context.Context, purpose string, token string) (*models.User, error) {
+	userToken, err := u.redisRepo.TakeTokenCtx(ctx, purpose, token)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.Wrap(grpc_errors.ErrInvalidToken, "redisRepo.TakeTokenCtx")
+		}
+		return nil, errors.Wrap(err, "redisRepo.TakeTokenCtx")
+	}
+
+	foundUser, err := u.userPgRepo.FindById(ctx, userToken.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.FindById")
+	}
+	if foundUser.Email != userToken.Email {
+		return nil, errors.Wrap(grpc_errors.ErrInvalidToken, "token was sent to another email")
+	}
+
+	return foundUser, nil
+}
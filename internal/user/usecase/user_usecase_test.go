@@ -4,17 +4,35 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	"github.com/AleksK1NG/auth-microservice/config"
 	"github.com/AleksK1NG/auth-microservice/internal/models"
 	"github.com/AleksK1NG/auth-microservice/internal/user/mock"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
 	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+	"github.com/AleksK1NG/auth-microservice/pkg/mailer"
 )
 
+type testMailer struct {
+	sent []*mailer.Message
+	err  error
+}
+
+func (m *testMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
 func TestUserUseCase_Register(t *testing.T) {
 	t.Parallel()
 
@@ -24,14 +42,14 @@ func TestUserUseCase_Register(t *testing.T) {
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
 	apiLogger := logger.NewAPILogger(nil)
-	userUC := NewUserUseCase(apiLogger, userPGRepository, userRedisRepository)
+	testMailer := &testMailer{}
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, testMailer)
 
 	userID := uuid.New()
 	mockUser := &models.User{
 		Email:     "email@gmail.com",
 		FirstName: "FirstName",
 		LastName:  "LastName",
-		Role:      "admin",
 		Avatar:    nil,
 		Password:  "123456",
 	}
@@ -45,15 +63,24 @@ func TestUserUseCase_Register(t *testing.T) {
 		Email:     "email@gmail.com",
 		FirstName: "FirstName",
 		LastName:  "LastName",
-		Role:      "admin",
+		Roles:     []string{models.RoleUser},
 		Avatar:    nil,
 		Password:  "123456",
 	}, nil)
+	userRedisRepository.EXPECT().CreateTokenCtx(gomock.Any(), &models.UserToken{
+		UserID:  userID,
+		Purpose: models.TokenPurposeEmailVerification,
+		Email:   mockUser.Email,
+	}, 0).Return("token", nil)
 
 	createdUser, err := userUC.Register(ctx, mockUser)
 	require.NoError(t, err)
 	require.NotNil(t, createdUser)
 	require.Equal(t, createdUser.UserID, userID)
+	require.Equal(t, []string{models.RoleUser}, mockUser.Roles)
+	require.Len(t, testMailer.sent, 1)
+	require.Equal(t, mockUser.Email, testMailer.sent[0].To)
+	require.Contains(t, testMailer.sent[0].Body, "verify-email?token=token")
 }
 
 func TestUserUseCase_FindByEmail(t *testing.T) {
@@ -65,7 +92,7 @@ func TestUserUseCase_FindByEmail(t *testing.T) {
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
 	apiLogger := logger.NewAPILogger(nil)
-	userUC := NewUserUseCase(apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, &testMailer{})
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -73,7 +100,7 @@ func TestUserUseCase_FindByEmail(t *testing.T) {
 		Email:     "email@gmail.com",
 		FirstName: "FirstName",
 		LastName:  "LastName",
-		Role:      "admin",
+		Roles:     []string{models.RoleAdmin},
 		Avatar:    nil,
 		Password:  "123456",
 	}
@@ -97,7 +124,7 @@ func TestUserUseCase_FindById(t *testing.T) {
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
 	apiLogger := logger.NewAPILogger(nil)
-	userUC := NewUserUseCase(apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, &testMailer{})
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -105,7 +132,7 @@ func TestUserUseCase_FindById(t *testing.T) {
 		Email:     "email@gmail.com",
 		FirstName: "FirstName",
 		LastName:  "LastName",
-		Role:      "admin",
+		Roles:     []string{models.RoleAdmin},
 		Avatar:    nil,
 		Password:  "123456",
 	}
@@ -121,3 +148,471 @@ func TestUserUseCase_FindById(t *testing.T) {
 	require.NotNil(t, user)
 	require.Equal(t, user.UserID, mockUser.UserID)
 }
+
+func TestUserUseCase_Login(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	cfg := &config.Config{EmailVerification: config.EmailVerification{Required: true}}
+	userUC := NewUserUseCase(apiLogger, cfg, userPGRepository, userRedisRepository, &testMailer{})
+
+	mockUser := &models.User{
+		UserID:   uuid.New(),
+		Email:    "email@gmail.com",
+		Password: "123456",
+	}
+	require.NoError(t, mockUser.HashPassword())
+
+	ctx := context.Background()
+
+	userPGRepository.EXPECT().FindByEmail(gomock.Any(), mockUser.Email).Return(mockUser, nil)
+
+	user, err := userUC.Login(ctx, mockUser.Email, "654321")
+	require.Nil(t, user)
+	require.Error(t, err)
+
+	// email verification is checked by every login method of the delivery layer
+	userPGRepository.EXPECT().FindByEmail(gomock.Any(), mockUser.Email).Return(mockUser, nil)
+
+	user, err = userUC.Login(ctx, mockUser.Email, "123456")
+	require.NoError(t, err)
+	require.Equal(t, mockUser.UserID, user.UserID)
+}
+
+func TestUserUseCase_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, &testMailer{})
+
+	mockUser := &models.User{
+		UserID: uuid.New(),
+		Email:  "email@gmail.com",
+	}
+	userToken := &models.UserToken{
+		UserID:  mockUser.UserID,
+		Purpose: models.TokenPurposeEmailVerification,
+		Email:   mockUser.Email,
+	}
+
+	ctx := context.Background()
+
+	userRedisRepository.EXPECT().TakeTokenCtx(gomock.Any(), models.TokenPurposeEmailVerification, "token").Return(userToken, nil)
+	userPGRepository.EXPECT().FindById(gomock.Any(), mockUser.UserID).Return(mockUser, nil)
+	userPGRepository.EXPECT().SetEmailVerified(gomock.Any(), mockUser.UserID).Return(nil)
+	userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), mockUser.UserID.String()).Return(nil)
+
+	user, err := userUC.VerifyEmail(ctx, "token")
+	require.NoError(t, err)
+	require.True(t, user.EmailVerified)
+
+	userRedisRepository.EXPECT().TakeTokenCtx(gomock.Any(), models.TokenPurposeEmailVerification, "token").Return(nil, redis.Nil)
+
+	user, err = userUC.VerifyEmail(ctx, "token")
+	require.Nil(t, user)
+	require.True(t, errors.Is(err, grpc_errors.ErrInvalidToken))
+}
+
+func TestUserUseCase_ResendVerification(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	testMailer := &testMailer{}
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, testMailer)
+
+	ctx := context.Background()
+
+	userPGRepository.EXPECT().FindByEmail(gomock.Any(), "unknown@gmail.com").Return(nil, sql.ErrNoRows)
+	require.NoError(t, userUC.ResendVerification(ctx, "unknown@gmail.com"))
+
+	verifiedUser := &models.User{UserID: uuid.New(), Email: "verified@gmail.com", EmailVerified: true}
+	userPGRepository.EXPECT().FindByEmail(gomock.Any(), verifiedUser.Email).Return(verifiedUser, nil)
+	require.NoError(t, userUC.ResendVerification(ctx, verifiedUser.Email))
+	require.Empty(t, testMailer.sent)
+
+	mockUser := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+	userPGRepository.EXPECT().FindByEmail(gomock.Any(), mockUser.Email).Return(mockUser, nil)
+	userRedisRepository.EXPECT().CreateTokenCtx(gomock.Any(), gomock.Any(), 0).Return("token", nil)
+	require.NoError(t, userUC.ResendVerification(ctx, mockUser.Email))
+	require.Len(t, testMailer.sent, 1)
+	require.Equal(t, mockUser.Email, testMailer.sent[0].To)
+
+	// delivery failure is not reported, like unknown and verified emails
+	testMailer.err = errors.New("smtp unavailable")
+	userPGRepository.EXPECT().FindByEmail(gomock.Any(), mockUser.Email).Return(mockUser, nil)
+	userRedisRepository.EXPECT().CreateTokenCtx(gomock.Any(), gomock.Any(), 0).Return("token", nil)
+	require.NoError(t, userUC.ResendVerification(ctx, mockUser.Email))
+	require.Len(t, testMailer.sent, 1)
+}
+
+func TestUserUseCase_RequestPasswordReset(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	testMailer := &testMailer{}
+	cfg := &config.Config{PasswordReset: config.PasswordReset{TokenExpire: 3600}}
+	userUC := NewUserUseCase(apiLogger, cfg, userPGRepository, userRedisRepository, testMailer)
+
+	ctx := context.Background()
+
+	userPGRepository.EXPECT().FindByEmail(gomock.Any(), "unknown@gmail.com").Return(nil, sql.ErrNoRows)
+	require.NoError(t, userUC.RequestPasswordReset(ctx, "unknown@gmail.com"))
+	require.Empty(t, testMailer.sent)
+
+	mockUser := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+	userPGRepository.EXPECT().FindByEmail(gomock.Any(), mockUser.Email).Return(mockUser, nil)
+	userRedisRepository.EXPECT().CreateTokenCtx(gomock.Any(), &models.UserToken{
+		UserID:  mockUser.UserID,
+		Purpose: models.TokenPurposePasswordReset,
+		Email:   mockUser.Email,
+	}, 3600).Return("token", nil)
+
+	require.NoError(t, userUC.RequestPasswordReset(ctx, mockUser.Email))
+	require.Len(t, testMailer.sent, 1)
+	require.Contains(t, testMailer.sent[0].Body, "reset-password?token=token")
+}
+
+func TestUserUseCase_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, &testMailer{})
+
+	mockUser := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+	userToken := &models.UserToken{
+		UserID:  mockUser.UserID,
+		Purpose: models.TokenPurposePasswordReset,
+		Email:   mockUser.Email,
+	}
+
+	ctx := context.Background()
+
+	var passwordHash string
+	userRedisRepository.EXPECT().TakeTokenCtx(gomock.Any(), models.TokenPurposePasswordReset, "token").Return(userToken, nil)
+	userPGRepository.EXPECT().FindById(gomock.Any(), mockUser.UserID).Return(mockUser, nil)
+	userPGRepository.EXPECT().UpdatePassword(gomock.Any(), mockUser.UserID, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, userID uuid.UUID, hash string) error {
+			passwordHash = hash
+			return nil
+		},
+	)
+	userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), mockUser.UserID.String()).Return(nil)
+
+	user, err := userUC.ResetPassword(ctx, "token", "new password")
+	require.NoError(t, err)
+	require.Empty(t, user.Password)
+	require.NoError(t, (&models.User{Password: passwordHash}).ComparePasswords("new password"))
+
+	t.Run("EmailChanged", func(t *testing.T) {
+		userRedisRepository.EXPECT().TakeTokenCtx(gomock.Any(), models.TokenPurposePasswordReset, "old").Return(&models.UserToken{
+			UserID:  mockUser.UserID,
+			Purpose: models.TokenPurposePasswordReset,
+			Email:   "old@gmail.com",
+		}, nil)
+		userPGRepository.EXPECT().FindById(gomock.Any(), mockUser.UserID).Return(&models.User{UserID: mockUser.UserID, Email: mockUser.Email}, nil)
+
+		user, err := userUC.ResetPassword(ctx, "old", "new password")
+		require.Nil(t, user)
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidToken))
+	})
+}
+
+func TestUserUseCase_ChangePassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, &testMailer{})
+
+	mockUser := &models.User{UserID: uuid.New(), Password: "old password 1"}
+	require.NoError(t, mockUser.HashPassword())
+
+	ctx := context.Background()
+
+	t.Run("ChangePassword", func(t *testing.T) {
+		userPGRepository.EXPECT().FindPasswordHash(gomock.Any(), mockUser.UserID).Return(mockUser.Password, nil)
+		userPGRepository.EXPECT().UpdatePassword(gomock.Any(), mockUser.UserID, gomock.Any()).Return(nil)
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), mockUser.UserID.String()).Return(nil)
+
+		err := userUC.ChangePassword(ctx, mockUser.UserID, "old password 1", "new password 1")
+		require.NoError(t, err)
+	})
+
+	t.Run("InvalidPassword", func(t *testing.T) {
+		userPGRepository.EXPECT().FindPasswordHash(gomock.Any(), mockUser.UserID).Return(mockUser.Password, nil)
+
+		err := userUC.ChangePassword(ctx, mockUser.UserID, "wrong password", "new password 1")
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidPassword))
+	})
+
+	t.Run("SamePassword", func(t *testing.T) {
+		userPGRepository.EXPECT().FindPasswordHash(gomock.Any(), mockUser.UserID).Return(mockUser.Password, nil)
+
+		err := userUC.ChangePassword(ctx, mockUser.UserID, "old password 1", "old password 1")
+		require.True(t, errors.Is(err, grpc_errors.ErrSamePassword))
+	})
+
+	t.Run("NormalizedPasswords", func(t *testing.T) {
+		userPGRepository.EXPECT().FindPasswordHash(gomock.Any(), mockUser.UserID).Return(mockUser.Password, nil)
+
+		err := userUC.ChangePassword(ctx, mockUser.UserID, " old password 1 ", "old password 1 ")
+		require.True(t, errors.Is(err, grpc_errors.ErrSamePassword))
+	})
+}
+
+func TestUserUseCase_Update(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, &testMailer{})
+
+	mockUser := &models.User{UserID: uuid.New(), FirstName: "FirstName", LastName: "LastName"}
+
+	userPGRepository.EXPECT().Update(gomock.Any(), mockUser).Return(mockUser, nil)
+	userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), mockUser.UserID.String()).Return(nil)
+
+	user, err := userUC.Update(context.Background(), mockUser)
+	require.NoError(t, err)
+	require.Equal(t, mockUser.UserID, user.UserID)
+}
+
+func TestUserUseCase_RequestEmailChange(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	testMailer := &testMailer{}
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, testMailer)
+
+	mockUser := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+
+	ctx := context.Background()
+
+	t.Run("EmailExists", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), mockUser.UserID).Return(mockUser, nil)
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "taken@gmail.com").Return(&models.User{}, nil)
+
+		err := userUC.RequestEmailChange(ctx, mockUser.UserID, "taken@gmail.com")
+		require.True(t, errors.Is(err, grpc_errors.ErrEmailExists))
+	})
+
+	t.Run("RequestEmailChange", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), mockUser.UserID).Return(mockUser, nil)
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "new@gmail.com").Return(nil, sql.ErrNoRows)
+		userRedisRepository.EXPECT().CreateTokenCtx(gomock.Any(), &models.UserToken{
+			UserID:   mockUser.UserID,
+			Purpose:  models.TokenPurposeEmailChange,
+			Email:    mockUser.Email,
+			NewEmail: "new@gmail.com",
+		}, 0).Return("token", nil)
+
+		err := userUC.RequestEmailChange(ctx, mockUser.UserID, " New@gmail.com")
+		require.NoError(t, err)
+		require.Len(t, testMailer.sent, 1)
+		require.Equal(t, "new@gmail.com", testMailer.sent[0].To)
+	})
+}
+
+func TestUserUseCase_ConfirmEmailChange(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	testMailer := &testMailer{}
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, testMailer)
+
+	userToken := &models.UserToken{
+		UserID:   uuid.New(),
+		Purpose:  models.TokenPurposeEmailChange,
+		Email:    "old@gmail.com",
+		NewEmail: "new@gmail.com",
+	}
+
+	ctx := context.Background()
+
+	t.Run("ConfirmEmailChange", func(t *testing.T) {
+		userRedisRepository.EXPECT().TakeTokenCtx(gomock.Any(), models.TokenPurposeEmailChange, "token").Return(userToken, nil)
+		userPGRepository.EXPECT().UpdateEmail(gomock.Any(), userToken.UserID, userToken.Email, userToken.NewEmail).Return(true, nil)
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), userToken.UserID.String()).Return(nil)
+		userPGRepository.EXPECT().FindById(gomock.Any(), userToken.UserID).Return(&models.User{
+			UserID:        userToken.UserID,
+			Email:         userToken.NewEmail,
+			EmailVerified: true,
+		}, nil)
+
+		user, err := userUC.ConfirmEmailChange(ctx, "token")
+		require.NoError(t, err)
+		require.Equal(t, userToken.NewEmail, user.Email)
+		require.Len(t, testMailer.sent, 1)
+		require.Equal(t, userToken.Email, testMailer.sent[0].To)
+	})
+
+	t.Run("EmailExists", func(t *testing.T) {
+		userRedisRepository.EXPECT().TakeTokenCtx(gomock.Any(), models.TokenPurposeEmailChange, "token").Return(userToken, nil)
+		userPGRepository.EXPECT().UpdateEmail(gomock.Any(), userToken.UserID, userToken.Email, userToken.NewEmail).Return(false, grpc_errors.ErrEmailExists)
+
+		user, err := userUC.ConfirmEmailChange(ctx, "token")
+		require.Nil(t, user)
+		require.True(t, errors.Is(err, grpc_errors.ErrEmailExists))
+	})
+
+	t.Run("EmailChangedMeanwhile", func(t *testing.T) {
+		userRedisRepository.EXPECT().TakeTokenCtx(gomock.Any(), models.TokenPurposeEmailChange, "token").Return(userToken, nil)
+		userPGRepository.EXPECT().UpdateEmail(gomock.Any(), userToken.UserID, userToken.Email, userToken.NewEmail).Return(false, nil)
+
+		user, err := userUC.ConfirmEmailChange(ctx, "token")
+		require.Nil(t, user)
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidToken))
+	})
+}
+
+func TestUserUseCase_DeleteAccount(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, &testMailer{})
+
+	mockUser := &models.User{UserID: uuid.New(), Password: "password 1"}
+	require.NoError(t, mockUser.HashPassword())
+
+	ctx := context.Background()
+
+	t.Run("DeleteAccount", func(t *testing.T) {
+		userPGRepository.EXPECT().FindPasswordHash(gomock.Any(), mockUser.UserID).Return(mockUser.Password, nil)
+		userPGRepository.EXPECT().SoftDelete(gomock.Any(), mockUser.UserID).Return(true, nil)
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), mockUser.UserID.String()).Return(nil)
+
+		require.NoError(t, userUC.DeleteAccount(ctx, mockUser.UserID, "password 1"))
+	})
+
+	t.Run("InvalidPassword", func(t *testing.T) {
+		userPGRepository.EXPECT().FindPasswordHash(gomock.Any(), mockUser.UserID).Return(mockUser.Password, nil)
+
+		err := userUC.DeleteAccount(ctx, mockUser.UserID, "wrong")
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidPassword))
+	})
+
+	t.Run("AlreadyDeleted", func(t *testing.T) {
+		userPGRepository.EXPECT().SoftDelete(gomock.Any(), mockUser.UserID).Return(false, nil)
+
+		err := userUC.Delete(ctx, mockUser.UserID)
+		require.True(t, errors.Is(err, sql.ErrNoRows))
+	})
+}
+
+func TestUserUseCase_Restore(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	cfg := &config.Config{AccountDeletion: config.AccountDeletion{GracePeriod: 3600}}
+	userUC := NewUserUseCase(apiLogger, cfg, userPGRepository, userRedisRepository, &testMailer{})
+
+	mockUser := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+
+	ctx := context.Background()
+
+	userPGRepository.EXPECT().Restore(gomock.Any(), mockUser.UserID, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, userID uuid.UUID, deletedAfter time.Time) (bool, error) {
+			require.WithinDuration(t, time.Now().Add(-time.Hour), deletedAfter, time.Minute)
+			return true, nil
+		},
+	)
+	userRedisRepository.EXPECT().GetByIDCtx(gomock.Any(), mockUser.UserID.String()).Return(nil, redis.Nil)
+	userPGRepository.EXPECT().FindById(gomock.Any(), mockUser.UserID).Return(mockUser, nil)
+	userRedisRepository.EXPECT().SetUserCtx(gomock.Any(), mockUser.UserID.String(), 3600, mockUser).Return(nil)
+
+	user, err := userUC.Restore(ctx, mockUser.UserID)
+	require.NoError(t, err)
+	require.Equal(t, mockUser.UserID, user.UserID)
+
+	userPGRepository.EXPECT().PurgeDeleted(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+
+	purged, err := userUC.PurgeDeleted(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+}
+
+func TestUserUseCase_List(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	apiLogger := logger.NewAPILogger(nil)
+	userUC := NewUserUseCase(apiLogger, &config.Config{}, userPGRepository, userRedisRepository, &testMailer{})
+
+	t.Run("Defaults", func(t *testing.T) {
+		userPGRepository.EXPECT().List(gomock.Any(), &models.UserListFilter{
+			Limit:     defaultListUsersLimit,
+			SortOrder: models.SortOrderDesc,
+		}).Return(&models.UserList{}, nil)
+
+		_, err := userUC.List(context.Background(), &models.UserListFilter{})
+		require.NoError(t, err)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		userPGRepository.EXPECT().List(gomock.Any(), &models.UserListFilter{
+			Limit:     maxListUsersLimit,
+			SortOrder: models.SortOrderAsc,
+		}).Return(&models.UserList{}, nil)
+
+		_, err := userUC.List(context.Background(), &models.UserListFilter{Limit: 1000, SortOrder: models.SortOrderAsc})
+		require.NoError(t, err)
+	})
+}
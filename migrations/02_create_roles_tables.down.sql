@@ -0,0 +1,13 @@
+CREATE TYPE role AS ENUM ('admin', 'user');
+
+ALTER TABLE users
+    ADD COLUMN role role NOT NULL DEFAULT 'user';
+
+UPDATE users
+SET role = 'admin'
+WHERE user_id IN (SELECT user_id FROM user_roles WHERE role = 'admin');
+
+DROP TABLE IF EXISTS user_roles CASCADE;
+DROP TABLE IF EXISTS role_permissions CASCADE;
+DROP TABLE IF EXISTS permissions CASCADE;
+DROP TABLE IF EXISTS roles CASCADE;
@@ -0,0 +1,51 @@
+CREATE TABLE roles
+(
+    name        VARCHAR(64) PRIMARY KEY CHECK ( name <> '' ),
+    description VARCHAR(250)             NOT NULL DEFAULT '',
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE permissions
+(
+    name       VARCHAR(64) PRIMARY KEY CHECK ( name <> '' ),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE role_permissions
+(
+    role       VARCHAR(64) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    permission VARCHAR(64) NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE user_roles
+(
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    role       VARCHAR(64)              NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role)
+);
+
+CREATE INDEX user_roles_role_idx ON user_roles (role);
+
+INSERT INTO roles (name, description)
+VALUES ('admin', 'Administrator'),
+       ('user', 'Registered user');
+
+INSERT INTO permissions (name)
+VALUES ('users:read'),
+       ('roles:manage'),
+       ('keys:rotate');
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name
+FROM permissions;
+
+INSERT INTO user_roles (user_id, role)
+SELECT user_id, role::TEXT
+FROM users;
+
+ALTER TABLE users
+    DROP COLUMN role;
+
+DROP TYPE role;
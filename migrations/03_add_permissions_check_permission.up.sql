@@ -0,0 +1,7 @@
+INSERT INTO permissions (name)
+VALUES ('permissions:check')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'permissions:check')
+ON CONFLICT (role, permission) DO NOTHING;
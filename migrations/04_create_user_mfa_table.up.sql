@@ -0,0 +1,8 @@
+CREATE TABLE user_mfa
+(
+    user_id      UUID PRIMARY KEY REFERENCES users (user_id) ON DELETE CASCADE,
+    totp_secret  BYTEA                    NOT NULL,
+    totp_enabled BOOLEAN                  NOT NULL DEFAULT FALSE,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMP WITH TIME ZONE          DEFAULT CURRENT_TIMESTAMP
+);
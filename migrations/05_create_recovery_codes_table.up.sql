@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS user_recovery_codes CASCADE;
+CREATE TABLE user_recovery_codes
+(
+    code_id    UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    code_hash  VARCHAR(250)             NOT NULL CHECK ( code_hash <> '' ),
+    used_at    TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS user_recovery_codes_user_id_idx ON user_recovery_codes (user_id) WHERE used_at IS NULL;
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS user_passkeys CASCADE;
+CREATE TABLE user_passkeys
+(
+    credential_id    BYTEA PRIMARY KEY,
+    user_id          UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    name             VARCHAR(64)              NOT NULL CHECK ( name <> '' ),
+    public_key       BYTEA                    NOT NULL,
+    attestation_type VARCHAR(32)              NOT NULL DEFAULT '',
+    aaguid           BYTEA,
+    sign_count       BIGINT                   NOT NULL DEFAULT 0,
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at     TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS user_passkeys_user_id_idx ON user_passkeys (user_id);
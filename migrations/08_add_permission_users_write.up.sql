@@ -0,0 +1,7 @@
+INSERT INTO permissions (name)
+VALUES ('users:write')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'users:write')
+ON CONFLICT (role, permission) DO NOTHING;
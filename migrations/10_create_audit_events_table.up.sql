@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS audit_events CASCADE;
+CREATE TABLE audit_events
+(
+    event_id   UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    event_type VARCHAR(64)              NOT NULL CHECK ( event_type <> '' ),
+    metadata   JSONB                    NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_user_id_created_at_idx ON audit_events (user_id, created_at DESC);
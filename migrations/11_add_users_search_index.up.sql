@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS users_search_trgm_idx ON users
+    USING GIN ((first_name || ' ' || last_name || ' ' || email) gin_trgm_ops);
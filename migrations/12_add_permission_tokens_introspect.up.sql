@@ -0,0 +1,7 @@
+INSERT INTO permissions (name)
+VALUES ('tokens:introspect')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'tokens:introspect')
+ON CONFLICT (role, permission) DO NOTHING;
@@ -0,0 +1 @@
+ALTER TABLE user_mfa ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0;
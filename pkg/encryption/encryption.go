@@ -0,0 +1,71 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const keySize = 32
+
+// Symmetric encryptor of secrets stored at rest
+type Encryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// AES-256-GCM encryptor, random nonce is prepended to ciphertext
+type aesEncryptor struct {
+	aead cipher.AEAD
+}
+
+// AES-256-GCM encryptor constructor, key is base64 encoded 32 bytes
+func NewAESEncryptor(encodedKey string) (*aesEncryptor, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "base64.DecodeString")
+	}
+	if len(key) != keySize {
+		return nil, errors.Errorf("invalid encryption key size: %d, expected: %d", len(key), keySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "aes.NewCipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "cipher.NewGCM")
+	}
+
+	return &aesEncryptor{aead: aead}, nil
+}
+
+// Encrypt plaintext
+func (e *aesEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "rand.Read")
+	}
+
+	return e.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt ciphertext produced by Encrypt
+func (e *aesEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < e.aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, sealed := ciphertext[:e.aead.NonceSize()], ciphertext[e.aead.NonceSize():]
+	plaintext, err := e.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "aead.Open")
+	}
+
+	return plaintext, nil
+}
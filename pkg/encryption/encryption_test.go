@@ -0,0 +1,40 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESEncryptor(t *testing.T) {
+	t.Parallel()
+
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	encryptor, err := NewAESEncryptor(key)
+	require.NoError(t, err)
+
+	t.Run("Encrypt and decrypt", func(t *testing.T) {
+		ciphertext, err := encryptor.Encrypt([]byte("secret"))
+		require.NoError(t, err)
+		require.NotContains(t, string(ciphertext), "secret")
+
+		plaintext, err := encryptor.Decrypt(ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, "secret", string(plaintext))
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		ciphertext, err := encryptor.Encrypt([]byte("secret"))
+		require.NoError(t, err)
+		ciphertext[len(ciphertext)-1] ^= 0xff
+
+		_, err = encryptor.Decrypt(ciphertext)
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		_, err := NewAESEncryptor(base64.StdEncoding.EncodeToString([]byte("short")))
+		require.Error(t, err)
+	})
+}
@@ -12,10 +12,31 @@ import (
 )
 
 var (
-	ErrNotFound         = errors.New("Not found")
-	ErrNoCtxMetaData    = errors.New("No ctx metadata")
-	ErrInvalidSessionId = errors.New("Invalid session id")
-	ErrEmailExists      = errors.New("Email already exists")
+	ErrNotFound                = errors.New("Not found")
+	ErrNoCtxMetaData           = errors.New("No ctx metadata")
+	ErrInvalidSessionId        = errors.New("Invalid session id")
+	ErrEmailExists             = errors.New("Email already exists")
+	ErrInvalidToken            = errors.New("Invalid token")
+	ErrRefreshTokenUsed        = errors.New("Refresh token already used")
+	ErrPermissionDenied        = errors.New("Permission denied")
+	ErrUnauthenticated         = errors.New("Unauthenticated")
+	ErrAlreadyAuthenticated    = errors.New("Already authenticated")
+	ErrRoleExists              = errors.New("Role already exists")
+	ErrMFAAlreadyEnabled       = errors.New("MFA already enabled")
+	ErrMFANotEnabled           = errors.New("MFA not enabled")
+	ErrInvalidMFACode          = errors.New("Invalid MFA code")
+	ErrInvalidMFAChallenge     = errors.New("Invalid MFA challenge")
+	ErrTooManyMFAAttempts      = errors.New("Too many MFA code attempts, try again later")
+	ErrInvalidRecoveryCode     = errors.New("Invalid recovery code")
+	ErrEmailNotVerified        = errors.New("Email not verified")
+	ErrNoPasskeys              = errors.New("No passkeys registered")
+	ErrInvalidPasskey          = errors.New("Invalid passkey")
+	ErrInvalidPasskeyChallenge = errors.New("Invalid passkey challenge")
+	ErrInvalidPassword         = errors.New("Invalid password")
+	ErrSamePassword            = errors.New("New password must differ from current one")
+	ErrSessionNotFound         = errors.New("Session not found")
+	ErrSessionMaxAgeExceeded   = errors.New("Session max age exceeded, login required")
+	ErrSessionLimitReached     = errors.New("Concurrent session limit reached, logout from another device")
 )
 
 // Parse error and get code
@@ -35,6 +56,48 @@ func ParseGRPCErrStatusCode(err error) codes.Code {
 		return codes.Unauthenticated
 	case errors.Is(err, ErrInvalidSessionId):
 		return codes.PermissionDenied
+	case errors.Is(err, ErrInvalidToken):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrRefreshTokenUsed):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrPermissionDenied):
+		return codes.PermissionDenied
+	case errors.Is(err, ErrUnauthenticated):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrAlreadyAuthenticated):
+		return codes.FailedPrecondition
+	case errors.Is(err, ErrRoleExists):
+		return codes.AlreadyExists
+	case errors.Is(err, ErrMFAAlreadyEnabled):
+		return codes.FailedPrecondition
+	case errors.Is(err, ErrMFANotEnabled):
+		return codes.FailedPrecondition
+	case errors.Is(err, ErrInvalidMFACode):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrInvalidMFAChallenge):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrInvalidRecoveryCode):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrEmailNotVerified):
+		return codes.FailedPrecondition
+	case errors.Is(err, ErrNoPasskeys):
+		return codes.FailedPrecondition
+	case errors.Is(err, ErrInvalidPasskey):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrInvalidPasskeyChallenge):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrInvalidPassword):
+		return codes.InvalidArgument
+	case errors.Is(err, ErrSamePassword):
+		return codes.InvalidArgument
+	case errors.Is(err, ErrSessionNotFound):
+		return codes.NotFound
+	case errors.Is(err, ErrSessionMaxAgeExceeded):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrSessionLimitReached):
+		return codes.ResourceExhausted
+	case errors.Is(err, ErrTooManyMFAAttempts):
+		return codes.ResourceExhausted
 	case strings.Contains(err.Error(), "Validate"):
 		return codes.InvalidArgument
 	case strings.Contains(err.Error(), "redis"):
@@ -62,6 +125,8 @@ func MapGRPCErrCodeToHttpStatus(code codes.Code) int {
 		return http.StatusGatewayTimeout
 	case codes.InvalidArgument:
 		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusBadRequest
 	}
 	return http.StatusInternalServerError
 }
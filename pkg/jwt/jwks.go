@@ -0,0 +1,64 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JSON Web Key, RFC 7517
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSON Web Key Set
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// Public part of signing key as JWK
+func (k *signingKey) jwk() JSONWebKey {
+	key := JSONWebKey{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+
+	switch publicKey := k.privateKey.Public().(type) {
+	case *rsa.PublicKey:
+		key.Kty = "RSA"
+		key.N = encodeBase64URL(publicKey.N.Bytes())
+		key.E = encodeBase64URL(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		key.Kty = "EC"
+		key.Crv = publicKey.Curve.Params().Name
+		key.X = encodeBase64URL(padBytes(publicKey.X.Bytes(), size))
+		key.Y = encodeBase64URL(padBytes(publicKey.Y.Bytes(), size))
+	case ed25519.PublicKey:
+		key.Kty = "OKP"
+		key.Crv = "Ed25519"
+		key.X = encodeBase64URL(publicKey)
+	}
+
+	return key
+}
+
+func encodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func padBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
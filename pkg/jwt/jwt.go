@@ -0,0 +1,265 @@
+package jwt
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	jwtgo "github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+)
+
+// Access token claims
+type Claims struct {
+	UserID      string   `json:"user_id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	SessionID   string   `json:"session_id"`
+	jwtgo.RegisteredClaims
+}
+
+// Signing key info
+type KeyInfo struct {
+	Kid       string
+	Algorithm string
+}
+
+// Jwt access tokens manager
+type Manager interface {
+	GenerateAccessToken(claims *Claims) (string, error)
+	VerifyAccessToken(token string) (*Claims, error)
+	RotateSigningKey() (*KeyInfo, error)
+	JWKS() *JSONWebKeySet
+}
+
+// Keys are reloaded at least this often after failed reloads or unknown kid lookups
+const minKeysReloadInterval = time.Second
+
+// Asymmetric keys tokens manager, signs with the active key and verifies with any not retired key.
+// Keys are periodically reloaded from Jwt.KeysDir, so replicas sharing it pick up rotations of each other.
+type jwtManager struct {
+	logger    logger.Logger
+	cfg       *config.Config
+	reloadMu  sync.Mutex
+	mu        sync.RWMutex
+	activeKey *signingKey
+	keys      map[string]*signingKey
+	loadedAt  time.Time
+}
+
+// Jwt manager constructor, loads signing keys from Jwt.KeysDir and generates the first one if there are none
+func NewJwtManager(logger logger.Logger, cfg *config.Config) (*jwtManager, error) {
+	m := &jwtManager{logger: logger, cfg: cfg, keys: make(map[string]*signingKey)}
+	if err := m.loadKeys(); err != nil {
+		return nil, errors.Wrap(err, "jwtManager.loadKeys")
+	}
+	return m, nil
+}
+
+// Sign new access token with the active key, fills issuer, subject, id and expiration of given claims
+func (m *jwtManager) GenerateAccessToken(claims *Claims) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwtgo.RegisteredClaims{
+		ID:        uuid.New().String(),
+		Issuer:    m.cfg.Jwt.Issuer,
+		Subject:   claims.UserID,
+		IssuedAt:  jwtgo.NewNumericDate(now),
+		NotBefore: jwtgo.NewNumericDate(now),
+		ExpiresAt: jwtgo.NewNumericDate(now.Add(m.accessTokenExpire())),
+	}
+
+	m.reloadKeysIfStale(m.keysReloadInterval())
+	m.mu.RLock()
+	key := m.activeKey
+	m.mu.RUnlock()
+
+	token := jwtgo.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	signed, err := token.SignedString(key.privateKey)
+	if err != nil {
+		return "", errors.Wrap(err, "jwtManager.GenerateAccessToken.SignedString")
+	}
+	return signed, nil
+}
+
+// Verify access token signature and expiration and returns its claims
+func (m *jwtManager) VerifyAccessToken(token string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwtgo.ParseWithClaims(token, claims, func(t *jwtgo.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := m.getKey(kid)
+		if !ok {
+			// key may have been rotated by another replica
+			m.reloadKeysIfStale(minKeysReloadInterval)
+			key, ok = m.getKey(kid)
+		}
+		if !ok {
+			return nil, errors.Errorf("unknown signing key: %s", kid)
+		}
+		if t.Method.Alg() != key.method.Alg() {
+			return nil, errors.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return key.privateKey.Public(), nil
+	})
+	if err != nil || !parsed.Valid {
+		return nil, errors.Wrapf(grpc_errors.ErrInvalidToken, "jwtManager.VerifyAccessToken: %v", err)
+	}
+	if !claims.VerifyIssuer(m.cfg.Jwt.Issuer, true) {
+		return nil, errors.Wrap(grpc_errors.ErrInvalidToken, "jwtManager.VerifyAccessToken.VerifyIssuer")
+	}
+	return claims, nil
+}
+
+// Generate and persist new active signing key, previous key stays valid for verification until its tokens expire
+func (m *jwtManager) RotateSigningKey() (*KeyInfo, error) {
+	privateKey, err := generatePrivateKey(m.cfg.Jwt.SigningAlgorithm)
+	if err != nil {
+		return nil, errors.Wrap(err, "jwtManager.RotateSigningKey.generatePrivateKey")
+	}
+	method, err := signingMethodForKey(privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "jwtManager.RotateSigningKey.signingMethodForKey")
+	}
+
+	key := &signingKey{kid: uuid.New().String(), method: method, privateKey: privateKey, createdAt: time.Now()}
+	if err := writePrivateKey(m.cfg.Jwt.KeysDir, key.kid, privateKey); err != nil {
+		return nil, errors.Wrap(err, "jwtManager.RotateSigningKey.writePrivateKey")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.activeKey != nil {
+		retireAt := now.Add(m.accessTokenExpire())
+		if err := writeRetireMarker(m.cfg.Jwt.KeysDir, m.activeKey.kid, retireAt); err != nil {
+			return nil, errors.Wrap(err, "jwtManager.RotateSigningKey.writeRetireMarker")
+		}
+		m.activeKey.retireAt = retireAt
+	}
+	for kid, k := range m.keys {
+		if !k.validAt(now) {
+			delete(m.keys, kid)
+		}
+	}
+	m.activeKey = key
+	m.keys[key.kid] = key
+
+	return &KeyInfo{Kid: key.kid, Algorithm: method.Alg()}, nil
+}
+
+// Public keys which can verify currently valid tokens
+func (m *jwtManager) JWKS() *JSONWebKeySet {
+	m.reloadKeysIfStale(m.keysReloadInterval())
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	set := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(m.keys))}
+	for _, key := range m.keys {
+		if key.validAt(now) {
+			set.Keys = append(set.Keys, key.jwk())
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func (m *jwtManager) getKey(kid string) (*signingKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, ok := m.keys[kid]
+	if !ok || !key.validAt(time.Now()) {
+		return nil, false
+	}
+	return key, true
+}
+
+// Reload keys from Jwt.KeysDir if they were loaded longer than interval ago, keeps current keys on failure
+func (m *jwtManager) reloadKeysIfStale(interval time.Duration) {
+	if !m.keysStale(interval) {
+		return
+	}
+
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	if !m.keysStale(interval) {
+		return
+	}
+
+	if err := m.loadKeys(); err != nil {
+		m.logger.Errorf("jwtManager.loadKeys: %v", err)
+		m.mu.Lock()
+		m.loadedAt = time.Now()
+		m.mu.Unlock()
+	}
+}
+
+func (m *jwtManager) keysStale(interval time.Duration) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return time.Since(m.loadedAt) >= interval
+}
+
+// Load keys from Jwt.KeysDir, marks keys replaced by a newer one as retired and deletes keys retired in the past.
+// The newest not retired key becomes active, a new one is generated if there is none.
+func (m *jwtManager) loadKeys() error {
+	dir := m.cfg.Jwt.KeysDir
+	loaded, err := loadPrivateKeys(dir)
+	if err != nil {
+		return errors.Wrap(err, "loadPrivateKeys")
+	}
+
+	now := time.Now()
+	keys := make(map[string]*signingKey, len(loaded))
+	var activeKey *signingKey
+	for i, key := range loaded {
+		// key replaced before its retirement was persisted, e.g. concurrent rotation on another replica
+		if key.retireAt.IsZero() && i < len(loaded)-1 {
+			key.retireAt = loaded[i+1].createdAt.Add(m.accessTokenExpire())
+			if err := writeRetireMarker(dir, key.kid, key.retireAt); err != nil {
+				return errors.Wrap(err, "writeRetireMarker")
+			}
+		}
+		if !key.validAt(now) {
+			if err := removePrivateKey(dir, key.kid); err != nil {
+				return errors.Wrap(err, "removePrivateKey")
+			}
+			continue
+		}
+		if key.retireAt.IsZero() {
+			activeKey = key
+		}
+		keys[key.kid] = key
+	}
+
+	m.mu.Lock()
+	m.keys = keys
+	m.activeKey = activeKey
+	m.loadedAt = now
+	m.mu.Unlock()
+
+	if activeKey == nil {
+		if _, err := m.RotateSigningKey(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *jwtManager) keysReloadInterval() time.Duration {
+	if m.cfg.Jwt.KeysReloadInterval <= 0 {
+		return minKeysReloadInterval
+	}
+	return time.Second * time.Duration(m.cfg.Jwt.KeysReloadInterval)
+}
+
+func (m *jwtManager) accessTokenExpire() time.Duration {
+	return time.Second * time.Duration(m.cfg.Jwt.AccessTokenExpire)
+}
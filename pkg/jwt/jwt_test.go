@@ -0,0 +1,194 @@
+package jwt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/pkg/grpc_errors"
+	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+)
+
+func newTestLogger() logger.Logger {
+	apiLogger := logger.NewAPILogger(&config.Config{})
+	apiLogger.InitLogger()
+	return apiLogger
+}
+
+func newTestConfig(t *testing.T, algorithm string) *config.Config {
+	return &config.Config{Jwt: config.Jwt{
+		AccessTokenExpire: 60,
+		Issuer:            "auth",
+		SigningAlgorithm:  algorithm,
+		KeysDir:           t.TempDir(),
+	}}
+}
+
+func TestJwtManager_GenerateAccessToken(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA} {
+		algorithm := algorithm
+		t.Run(algorithm, func(t *testing.T) {
+			t.Parallel()
+
+			jwtManager, err := NewJwtManager(newTestLogger(), newTestConfig(t, algorithm))
+			require.NoError(t, err)
+
+			claims := &Claims{UserID: uuid.New().String(), Roles: []string{"user"}, Permissions: []string{"users:read"}, SessionID: uuid.New().String()}
+			token, err := jwtManager.GenerateAccessToken(claims)
+			require.NoError(t, err)
+			require.NotEqual(t, token, "")
+			require.NotNil(t, claims.ExpiresAt)
+
+			verified, err := jwtManager.VerifyAccessToken(token)
+			require.NoError(t, err)
+			require.Equal(t, claims.UserID, verified.UserID)
+			require.Equal(t, claims.Roles, verified.Roles)
+			require.Equal(t, claims.Permissions, verified.Permissions)
+			require.Equal(t, claims.SessionID, verified.SessionID)
+			require.Equal(t, claims.UserID, verified.Subject)
+		})
+	}
+}
+
+func TestJwtManager_VerifyAccessToken(t *testing.T) {
+	t.Parallel()
+
+	jwtManager, err := NewJwtManager(newTestLogger(), newTestConfig(t, AlgorithmES256))
+	require.NoError(t, err)
+
+	t.Run("Unknown key", func(t *testing.T) {
+		otherManager, err := NewJwtManager(newTestLogger(), newTestConfig(t, AlgorithmES256))
+		require.NoError(t, err)
+		token, err := otherManager.GenerateAccessToken(&Claims{UserID: uuid.New().String()})
+		require.NoError(t, err)
+
+		_, err = jwtManager.VerifyAccessToken(token)
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidToken))
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		cfg := newTestConfig(t, AlgorithmES256)
+		cfg.Jwt.AccessTokenExpire = -60
+		expiredManager, err := NewJwtManager(newTestLogger(), cfg)
+		require.NoError(t, err)
+		token, err := expiredManager.GenerateAccessToken(&Claims{UserID: uuid.New().String()})
+		require.NoError(t, err)
+
+		_, err = expiredManager.VerifyAccessToken(token)
+		require.True(t, errors.Is(err, grpc_errors.ErrInvalidToken))
+	})
+}
+
+func TestJwtManager_RotateSigningKey(t *testing.T) {
+	t.Parallel()
+
+	cfg := newTestConfig(t, AlgorithmEdDSA)
+	jwtManager, err := NewJwtManager(newTestLogger(), cfg)
+	require.NoError(t, err)
+
+	oldToken, err := jwtManager.GenerateAccessToken(&Claims{UserID: uuid.New().String()})
+	require.NoError(t, err)
+
+	keyInfo, err := jwtManager.RotateSigningKey()
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmEdDSA, keyInfo.Algorithm)
+	require.Len(t, jwtManager.JWKS().Keys, 2)
+
+	_, err = jwtManager.VerifyAccessToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, err := jwtManager.GenerateAccessToken(&Claims{UserID: uuid.New().String()})
+	require.NoError(t, err)
+	_, err = jwtManager.VerifyAccessToken(newToken)
+	require.NoError(t, err)
+
+	t.Run("Reload keys", func(t *testing.T) {
+		reloaded, err := NewJwtManager(newTestLogger(), cfg)
+		require.NoError(t, err)
+		require.Len(t, reloaded.JWKS().Keys, 2)
+		require.Equal(t, keyInfo.Kid, reloaded.activeKey.kid)
+
+		_, err = reloaded.VerifyAccessToken(oldToken)
+		require.NoError(t, err)
+		_, err = reloaded.VerifyAccessToken(newToken)
+		require.NoError(t, err)
+
+		// retirement is persisted, restart does not extend validity of the old key
+		for kid, key := range reloaded.keys {
+			if kid != keyInfo.Kid {
+				require.True(t, key.retireAt.Equal(jwtManager.keys[kid].retireAt))
+			}
+		}
+	})
+}
+
+func TestJwtManager_RotateSigningKeyOtherReplica(t *testing.T) {
+	t.Parallel()
+
+	cfg := newTestConfig(t, AlgorithmES256)
+	cfg.Jwt.KeysReloadInterval = 60
+	replica1, err := NewJwtManager(newTestLogger(), cfg)
+	require.NoError(t, err)
+	replica2, err := NewJwtManager(newTestLogger(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, replica1.activeKey.kid, replica2.activeKey.kid)
+
+	keyInfo, err := replica1.RotateSigningKey()
+	require.NoError(t, err)
+	token, err := replica1.GenerateAccessToken(&Claims{UserID: uuid.New().String()})
+	require.NoError(t, err)
+
+	// unknown kid reloads keys from the shared dir
+	replica2.loadedAt = time.Now().Add(-minKeysReloadInterval)
+	_, err = replica2.VerifyAccessToken(token)
+	require.NoError(t, err)
+	require.Equal(t, keyInfo.Kid, replica2.activeKey.kid)
+	require.Len(t, replica2.JWKS().Keys, 2)
+}
+
+func TestJwtManager_RemoveRetiredKeys(t *testing.T) {
+	t.Parallel()
+
+	cfg := newTestConfig(t, AlgorithmEdDSA)
+	jwtManager, err := NewJwtManager(newTestLogger(), cfg)
+	require.NoError(t, err)
+	oldKid := jwtManager.activeKey.kid
+
+	cfg.Jwt.AccessTokenExpire = 0
+	_, err = jwtManager.RotateSigningKey()
+	require.NoError(t, err)
+	require.Len(t, jwtManager.JWKS().Keys, 1)
+
+	reloaded, err := NewJwtManager(newTestLogger(), cfg)
+	require.NoError(t, err)
+	require.Len(t, reloaded.JWKS().Keys, 1)
+	require.NotEqual(t, oldKid, reloaded.activeKey.kid)
+
+	_, err = os.Stat(filepath.Join(cfg.Jwt.KeysDir, oldKid+keyFileSuffix))
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(cfg.Jwt.KeysDir, oldKid+retiredFileSuffix))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestJwtManager_JWKS(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA} {
+		jwtManager, err := NewJwtManager(newTestLogger(), newTestConfig(t, algorithm))
+		require.NoError(t, err)
+
+		jwks := jwtManager.JWKS()
+		require.Len(t, jwks.Keys, 1)
+		require.Equal(t, algorithm, jwks.Keys[0].Alg)
+		require.Equal(t, jwtManager.activeKey.kid, jwks.Keys[0].Kid)
+		require.Equal(t, "sig", jwks.Keys[0].Use)
+	}
+}
@@ -0,0 +1,209 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	jwtgo "github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyBits        = 2048
+	keyFileSuffix     = ".pem"
+	retiredFileSuffix = ".retired"
+	tmpFileSuffix     = ".tmp"
+)
+
+// Signing key identified by kid, retired keys are only used for verification until retireAt
+type signingKey struct {
+	kid        string
+	method     jwtgo.SigningMethod
+	privateKey crypto.Signer
+	createdAt  time.Time
+	retireAt   time.Time
+}
+
+// Check if key can still verify tokens at the given time
+func (k *signingKey) validAt(t time.Time) bool {
+	return k.retireAt.IsZero() || t.Before(k.retireAt)
+}
+
+// Generate new private key for algorithm
+func generatePrivateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	}
+	return nil, errors.Errorf("unsupported signing algorithm: %s", algorithm)
+}
+
+// Get jwt signing method for private key type
+func signingMethodForKey(privateKey crypto.Signer) (jwtgo.SigningMethod, error) {
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return jwtgo.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, errors.Errorf("unsupported ecdsa curve: %s", key.Curve.Params().Name)
+		}
+		return jwtgo.SigningMethodES256, nil
+	case ed25519.PrivateKey:
+		return jwtgo.SigningMethodEdDSA, nil
+	}
+	return nil, errors.Errorf("unsupported private key type: %T", privateKey)
+}
+
+// Parse PKCS8, PKCS1 or SEC1 PEM encoded private key
+func parsePrivateKey(pemBytes []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.Errorf("unsupported private key type: %T", key)
+		}
+		return signer, nil
+	}
+	return nil, errors.Errorf("unsupported PEM block type: %s", block.Type)
+}
+
+// Write private key as PKCS8 PEM file named by kid
+func writePrivateKey(dir string, kid string, privateKey crypto.Signer) error {
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return errors.Wrap(err, "x509.MarshalPKCS8PrivateKey")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "os.MkdirAll")
+	}
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})
+	return writeFileAtomic(filepath.Join(dir, kid+keyFileSuffix), pemBytes)
+}
+
+// Persist retirement time of key, so it is not revived after restart or by other replicas
+func writeRetireMarker(dir string, kid string, retireAt time.Time) error {
+	return writeFileAtomic(filepath.Join(dir, kid+retiredFileSuffix), []byte(retireAt.UTC().Format(time.RFC3339Nano)))
+}
+
+// Delete private key and its retire marker
+func removePrivateKey(dir string, kid string) error {
+	for _, name := range []string{kid + keyFileSuffix, kid + retiredFileSuffix} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "os.Remove")
+		}
+	}
+	return nil
+}
+
+// Write file through rename, so other replicas sharing the dir never read it partially written
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + tmpFileSuffix
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return errors.Wrap(err, "ioutil.WriteFile")
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return errors.Wrap(err, "os.Rename")
+	}
+	return nil
+}
+
+// Read retirement time of key, zero time if key is not retired
+func readRetireMarker(dir string, kid string) (time.Time, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, kid+retiredFileSuffix))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, errors.Wrap(err, "ioutil.ReadFile")
+	}
+	retireAt, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "time.Parse")
+	}
+	return retireAt, nil
+}
+
+// Load all PEM private keys from dir with their retire markers, kid is the file name without extension.
+// Keys are returned ordered by modification time, the newest last.
+func loadPrivateKeys(dir string) ([]*signingKey, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "ioutil.ReadDir")
+	}
+
+	var keys []*signingKey
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), keyFileSuffix) {
+			continue
+		}
+
+		pemBytes, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "ioutil.ReadFile")
+		}
+		privateKey, err := parsePrivateKey(pemBytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsePrivateKey %s", file.Name())
+		}
+		method, err := signingMethodForKey(privateKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "signingMethodForKey %s", file.Name())
+		}
+		kid := strings.TrimSuffix(file.Name(), keyFileSuffix)
+		retireAt, err := readRetireMarker(dir, kid)
+		if err != nil {
+			return nil, errors.Wrapf(err, "readRetireMarker %s", kid)
+		}
+
+		keys = append(keys, &signingKey{
+			kid:        kid,
+			method:     method,
+			privateKey: privateKey,
+			createdAt:  file.ModTime(),
+			retireAt:   retireAt,
+		})
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].createdAt.Before(keys[j].createdAt) })
+
+	return keys, nil
+}
@@ -0,0 +1,85 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+	"github.com/AleksK1NG/auth-microservice/pkg/logger"
+)
+
+const (
+	DriverLog  = "log"
+	DriverSMTP = "smtp"
+)
+
+// Email message
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails to users
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Mailer constructor, driver is chosen by Mailer.Driver config
+func NewMailer(cfg *config.Config, logger logger.Logger) (Mailer, error) {
+	switch cfg.Mailer.Driver {
+	case DriverLog, "":
+		return &logMailer{logger: logger}, nil
+	case DriverSMTP:
+		return &smtpMailer{cfg: cfg}, nil
+	}
+	return nil, errors.Errorf("unsupported mailer driver: %s", cfg.Mailer.Driver)
+}
+
+// Writes emails to log instead of sending them, for development
+type logMailer struct {
+	logger logger.Logger
+}
+
+// Log email
+func (m *logMailer) Send(ctx context.Context, msg *Message) error {
+	m.logger.Infof("Mailer: to: %s, subject: %s, body: %s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// Sends emails with SMTP server
+type smtpMailer struct {
+	cfg *config.Config
+}
+
+// Send email with SMTP server, uses PLAIN auth when username is set
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	var auth smtp.Auth
+	if m.cfg.Mailer.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Mailer.Username, m.cfg.Mailer.Password, m.cfg.Mailer.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.cfg.Mailer.Host, m.cfg.Mailer.Port)
+	if err := smtp.SendMail(addr, auth, m.cfg.Mailer.From, []string{msg.To}, buildMessage(m.cfg.Mailer.From, msg)); err != nil {
+		return errors.Wrap(err, "smtp.SendMail")
+	}
+
+	return nil
+}
+
+// Build RFC 5322 plain text message
+func buildMessage(from string, msg *Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/auth-microservice/config"
+)
+
+func TestNewMailer(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewMailer(&config.Config{Mailer: config.Mailer{Driver: DriverSMTP}}, nil)
+	require.NoError(t, err)
+	require.IsType(t, &smtpMailer{}, m)
+
+	m, err = NewMailer(&config.Config{}, nil)
+	require.NoError(t, err)
+	require.IsType(t, &logMailer{}, m)
+
+	_, err = NewMailer(&config.Config{Mailer: config.Mailer{Driver: "pigeon"}}, nil)
+	require.Error(t, err)
+}
+
+func TestBuildMessage(t *testing.T) {
+	t.Parallel()
+
+	message := buildMessage("no-reply@example.com", &Message{
+		To:      "email@gmail.com",
+		Subject: "Verify your email",
+		Body:    "first line\nsecond line",
+	})
+
+	require.Equal(t, "From: no-reply@example.com\r\n"+
+		"To: email@gmail.com\r\n"+
+		"Subject: Verify your email\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=\"utf-8\"\r\n"+
+		"\r\n"+
+		"first line\r\nsecond line", string(message))
+}
@@ -3,8 +3,17 @@ package utils
 import (
 	"context"
 	"regexp"
+	"strings"
+	"unicode"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores everything after 72 bytes
+	maxPasswordLength = 72
 )
 
 // Use a single instance of Validate, it caches struct info
@@ -25,3 +34,29 @@ func ValidateEmail(email string) bool {
 
 	return emailRegex.MatchString(email)
 }
+
+// Validate new password strength: length within bcrypt limits, at least one letter and one digit
+func ValidatePassword(password string) error {
+	password = strings.TrimSpace(password)
+	if len(password) < minPasswordLength {
+		return errors.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return errors.Errorf("password must be at most %d bytes", maxPasswordLength)
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return errors.New("password must contain a letter and a digit")
+	}
+
+	return nil
+}